@@ -3,15 +3,15 @@ module utui
 go 1.25.4
 
 require (
-	github.com/ObsidianIRC/unrealircd-rpc-golang v0.0.0-20251012000719-dd258951f773
+	github.com/dlclark/regexp2 v1.12.0
 	github.com/gdamore/tcell/v2 v2.8.1
+	github.com/gorilla/websocket v1.5.3
 	github.com/rivo/tview v0.42.0
 	golang.org/x/net v0.47.0
 )
 
 require (
 	github.com/gdamore/encoding v1.0.1 // indirect
-	github.com/gorilla/websocket v1.5.3 // indirect
 	github.com/lucasb-eyer/go-colorful v1.2.0 // indirect
 	github.com/mattn/go-runewidth v0.0.16 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
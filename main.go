@@ -21,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"utui/rpc"
 	"utui/ui"
 
 	"github.com/gdamore/tcell/v2"
@@ -1704,9 +1705,20 @@ func main() {
 			return nil
 		}
 		if event.Rune() == 'q' {
+			// Don't quit while typing into an inline field, like a table filter
+			if _, ok := app.GetFocus().(*tview.InputField); ok {
+				return event
+			}
 			// Don't quit if we're on pages with input fields
 			pageName, _ := pages.GetFrontPage()
-			if pageName == "remote_log_streaming" || pageName == "rpc_setup_modal" {
+			if pageName == "remote_log_streaming" || pageName == "remote_log_history" || pageName == "rpc_setup_modal" || pageName == "server_ban_add_modal" ||
+				pageName == "log_filter_modal" || pageName == "log_presets_modal" || pageName == "log_export_modal" ||
+				pageName == "spamfilter_add_modal" ||
+				pageName == "spamfilter_test_bench" || pageName == "spamfilter_samples_modal" ||
+				pageName == "name_ban_add_modal" || pageName == "ban_exception_add_modal" ||
+				pageName == "input_modal" || pageName == "channel_member_modal" ||
+				pageName == "table_columns_modal" || pageName == "ban_mask_builder_modal" ||
+				pageName == "passphrase_modal" || pageName == "legacy_password_modal" {
 				return event // Let the input field handle it
 			}
 			app.Stop()
@@ -1754,7 +1766,10 @@ func main() {
 		}
 		return event
 	})
-	if err := app.SetRoot(pages, true).Run(); err != nil {
+	err = app.SetRoot(pages, true).Run()
+	// Close RPC connections so they don't linger on the server
+	rpc.Sessions.CloseAll()
+	if err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,123 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// BanExceptionTypes are the exception type letters and what a matching user is exempt from
+var BanExceptionTypes = []struct {
+	Letter      string
+	Description string
+}{
+	{"k", "K-line"},
+	{"G", "G-line"},
+	{"z", "Z-line"},
+	{"Z", "Global Z-line"},
+	{"Q", "Q-line"},
+	{"s", "Shun"},
+	{"F", "Spamfilter"},
+	{"b", "Blacklist"},
+	{"c", "Connect flood"},
+	{"d", "Handshake data flood"},
+	{"m", "Max connections per IP"},
+	{"r", "Antirandom"},
+	{"8", "Antimixedutf8"},
+	{"v", "Ban version"},
+}
+
+// GetBanExceptions returns all server ban exceptions (E-lines)
+func (r *RPCClient) GetBanExceptions() ([]BanExceptionInfo, error) {
+	result, err := r.query("server_ban_exception.list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban exceptions: %w", err)
+	}
+
+	exceptionList, err := resultList(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var exceptions []BanExceptionInfo
+	for _, e := range exceptionList {
+		if exceptionMap, ok := e.(map[string]interface{}); ok {
+			exceptions = append(exceptions, parseBanException(exceptionMap))
+		}
+	}
+	return exceptions, nil
+}
+
+// AddBanException exempts a user@host mask from the bans in exceptionTypes (letters,
+// see BanExceptionTypes). duration uses the IRC notation ("1d", "0" for permanent).
+func (r *RPCClient) AddBanException(name, exceptionTypes, duration, reason string) error {
+	params := map[string]interface{}{
+		"name":            name,
+		"exception_types": exceptionTypes,
+		"reason":          reason,
+	}
+	if duration != "" {
+		params["duration_string"] = duration
+	}
+	if _, err := r.query("server_ban_exception.add", params); err != nil {
+		return fmt.Errorf("failed to add ban exception on %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteBanException removes a ban exception
+func (r *RPCClient) DeleteBanException(name string) error {
+	params := map[string]interface{}{
+		"name": name,
+	}
+	if _, err := r.query("server_ban_exception.del", params); err != nil {
+		return fmt.Errorf("failed to delete ban exception on %s: %w", name, err)
+	}
+	return nil
+}
+
+func parseBanException(exceptionMap map[string]interface{}) BanExceptionInfo {
+	exception := BanExceptionInfo{}
+
+	if name, ok := exceptionMap["name"].(string); ok {
+		exception.Name = name
+	}
+	if exceptionTypes, ok := exceptionMap["exception_types"].(string); ok {
+		exception.ExceptionTypes = exceptionTypes
+	}
+	if reason, ok := exceptionMap["reason"].(string); ok {
+		exception.Reason = reason
+	}
+	if setBy, ok := exceptionMap["set_by"].(string); ok {
+		exception.Setby = setBy
+	}
+	if setAt, ok := exceptionMap["set_at"].(string); ok {
+		exception.CreatedAt = parseRPCTime(setAt)
+	}
+	if expireAt, ok := exceptionMap["expire_at"].(string); ok {
+		exception.ExpireAt = parseRPCTime(expireAt)
+	}
+	if durationString, ok := exceptionMap["duration_string"].(string); ok {
+		exception.DurationString = durationString
+	}
+	if setInConfig, ok := exceptionMap["set_in_config"].(bool); ok {
+		exception.SetInConfig = setInConfig
+	}
+
+	return exception
+}
+
+// DescribeBanExceptionTypes spells out exception type letters, "kG" becomes "K-line, G-line"
+func DescribeBanExceptionTypes(exceptionTypes string) string {
+	var names []string
+	for _, letter := range exceptionTypes {
+		name := string(letter)
+		for _, exceptionType := range BanExceptionTypes {
+			if exceptionType.Letter == string(letter) {
+				name = exceptionType.Description
+				break
+			}
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
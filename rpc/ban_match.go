@@ -0,0 +1,243 @@
+package rpc
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Ban masks
+//
+// A ban mask is nick!user@host for channel bans or user@host for server bans,
+// with * and ? wildcards. The host is matched against the hostname, IP, cloaked
+// host and vhost, or is an IP network like 192.0.2.0/24. A mask without @ is a
+// host when it looks like one and a nick otherwise. Extended bans match on
+// something else than the host:
+//
+//	~account:name (~a)          logged in as name, ~account:0 for not logged in
+//	~country:NL (~C)            connecting from a country, by GeoIP
+//	~security-group:name (~G)   in a security group
+//	~realname:mask (~r)         the real name (gecos)
+//	~channel:#chan (~c)         in a channel
+//
+// ~time:minutes:mask (~t) and the channel ban actions ~quiet (~q), ~nickchange
+// (~n) and ~join (~j) match the users of the mask they wrap.
+//
+// Server bans only match the real host: their host is matched against the
+// hostname and IP, not the cloaked host or vhost. Z-lines and GZ-lines are
+// checked before the host is looked up, so their host is only matched against
+// the IP.
+
+// extbanNames maps the letters of the extended bans that can be matched to their names
+var extbanNames = map[string]string{
+	"a": "account",
+	"C": "country",
+	"G": "security-group",
+	"r": "realname",
+	"c": "channel",
+	"t": "time",
+	"q": "quiet",
+	"n": "nickchange",
+	"j": "join",
+}
+
+// BanMask is a parsed ban mask, to see which users a ban would hit before setting it
+type BanMask struct {
+	extban   string   // Name of the extended ban, "" for a nick!user@host mask
+	value    string   // Value of the extended ban
+	inner    *BanMask // Mask wrapped by ~time or an action
+	nick     string
+	user     string
+	host     string
+	ipNet    *net.IPNet // Set when host is an IP network
+	realHost bool       // The host is matched against the hostname and IP only
+	ipOnly   bool       // The host is matched against the IP only
+}
+
+// ParseBanMask parses a server or channel ban mask
+func ParseBanMask(mask string) (*BanMask, error) {
+	mask = strings.TrimSpace(mask)
+	if mask == "" {
+		return nil, fmt.Errorf("empty mask")
+	}
+
+	if strings.HasPrefix(mask, "~") {
+		name, value, ok := strings.Cut(mask[1:], ":")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("extended ban %s needs a value, like ~account:name", mask)
+		}
+		if long, ok := extbanNames[name]; ok {
+			name = long
+		}
+		b := &BanMask{extban: name, value: value}
+		switch name {
+		case "account", "country", "security-group", "realname", "channel":
+		case "time":
+			_, rest, ok := strings.Cut(value, ":")
+			if !ok {
+				return nil, fmt.Errorf("~time needs minutes and a mask, like ~time:60:*@192.0.2.1")
+			}
+			inner, err := ParseBanMask(rest)
+			if err != nil {
+				return nil, err
+			}
+			b.inner = inner
+		case "quiet", "nickchange", "join":
+			inner, err := ParseBanMask(value)
+			if err != nil {
+				return nil, err
+			}
+			b.inner = inner
+		default:
+			return nil, fmt.Errorf("extended ban ~%s cannot be matched here", name)
+		}
+		return b, nil
+	}
+
+	b := &BanMask{nick: "*", user: "*", host: "*"}
+	rest := mask
+	if nick, userHost, ok := strings.Cut(mask, "!"); ok {
+		b.nick = nick
+		rest = userHost
+		if !strings.Contains(rest, "@") {
+			b.user = rest
+			rest = ""
+		}
+	}
+	if user, host, ok := strings.Cut(rest, "@"); ok {
+		b.user = user
+		b.host = host
+	} else if rest != "" {
+		if strings.ContainsAny(rest, ".:/") {
+			b.host = rest
+		} else {
+			b.nick = rest
+		}
+	}
+	if b.nick == "" || b.user == "" || b.host == "" {
+		return nil, fmt.Errorf("incomplete mask %s", mask)
+	}
+	if strings.Contains(b.host, "/") {
+		_, ipNet, err := net.ParseCIDR(b.host)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %s", b.host)
+		}
+		b.ipNet = ipNet
+	}
+	return b, nil
+}
+
+// ParseServerBanMask parses the mask of a server ban of banType, one of ServerBanTypes
+func ParseServerBanMask(banType, mask string) (*BanMask, error) {
+	b, err := ParseBanMask(mask)
+	if err != nil {
+		return nil, err
+	}
+	for inner := b; inner != nil; inner = inner.inner {
+		inner.realHost = true
+		inner.ipOnly = banType == "zline" || banType == "gzline"
+	}
+	return b, nil
+}
+
+// Match reports whether the mask matches user
+func (b *BanMask) Match(user UserInfo) bool {
+	if b.inner != nil {
+		return b.inner.Match(user)
+	}
+	switch b.extban {
+	case "account":
+		loggedIn := user.Account != "" && user.Account != "none"
+		if b.value == "0" {
+			return !loggedIn
+		}
+		return loggedIn && wildcardMatch(b.value, user.Account)
+	case "country":
+		return user.Country != "" && wildcardMatch(b.value, user.Country)
+	case "security-group":
+		return indexWildcard(user.SecurityGroups, b.value) >= 0
+	case "realname":
+		return wildcardMatch(b.value, user.Realname)
+	case "channel":
+		return indexWildcard(user.Channels, strings.TrimLeft(b.value, "~&@%+")) >= 0
+	}
+
+	if !wildcardMatch(b.nick, user.Nick) || !wildcardMatch(b.user, user.Username) {
+		return false
+	}
+	if b.ipNet != nil {
+		ip := net.ParseIP(user.IP)
+		return ip != nil && b.ipNet.Contains(ip)
+	}
+	if b.ipOnly {
+		return user.IP != "" && wildcardMatch(b.host, user.IP)
+	}
+	hosts := []string{user.Hostname, user.IP, user.Cloakedhost, user.Vhost}
+	if b.realHost {
+		hosts = hosts[:2]
+	}
+	for _, host := range hosts {
+		if host != "" && wildcardMatch(b.host, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchBanMask returns the users mask matches
+func MatchBanMask(mask *BanMask, users []UserInfo) []UserInfo {
+	var matches []UserInfo
+	for _, user := range users {
+		if mask.Match(user) {
+			matches = append(matches, user)
+		}
+	}
+	return matches
+}
+
+// UserBanMasks returns masks to ban user by, from narrow to broad. Channel bans
+// get the nick!user@host form.
+func UserBanMasks(user UserInfo, channel bool) []string {
+	var masks []string
+	host := func(userHost string) {
+		if channel {
+			userHost = "*!" + userHost
+		}
+		masks = append(masks, userHost)
+	}
+	if user.Username != "" && user.Hostname != "" {
+		host(user.Username + "@" + user.Hostname)
+	}
+	if user.Hostname != "" && user.Hostname != user.IP {
+		host("*@" + user.Hostname)
+	}
+	if user.Cloakedhost != "" && channel {
+		host("*@" + user.Cloakedhost)
+	}
+	if user.IP != "" {
+		host("*@" + user.IP)
+		if network := ipNetwork(user.IP); network != "" {
+			host("*@" + network)
+		}
+	}
+	if user.Account != "" && user.Account != "none" {
+		masks = append(masks, "~account:"+user.Account)
+	}
+	if user.Country != "" {
+		masks = append(masks, "~country:"+user.Country)
+	}
+	for _, group := range user.SecurityGroups {
+		masks = append(masks, "~security-group:"+group)
+	}
+	return masks
+}
+
+// indexWildcard returns the index of the first item pattern matches, or -1
+func indexWildcard(list []string, pattern string) int {
+	for i, item := range list {
+		if wildcardMatch(pattern, item) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,175 @@
+package rpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+var banMatchUser = UserInfo{
+	Nick:           "Guest42",
+	Username:       "guest",
+	Realname:       "Just visiting",
+	Account:        "none",
+	IP:             "192.0.2.7",
+	Country:        "NL",
+	Hostname:       "dsl-7.example.net",
+	Cloakedhost:    "Clk-1A2B3C4D.example.net",
+	Vhost:          "guest.users.example.org",
+	Channels:       []string{"#help", "#lobby"},
+	SecurityGroups: []string{"unknown-users", "webirc-users"},
+}
+
+func TestBanMaskMatch(t *testing.T) {
+	tests := []struct {
+		mask string
+		want bool
+	}{
+		{"*!*@*", true},
+		{"*@dsl-7.example.net", true},
+		{"guest@*.example.net", true},
+		{"other@*.example.net", false},
+		{"*@192.0.2.7", true},
+		{"*@192.0.2.*", true},
+		{"*@192.0.2.0/24", true},
+		{"*@198.51.100.0/24", false},
+		{"*@clk-1a2b3c4d.example.net", true},
+		{"*@guest.users.example.org", true},
+		{"guest42!*@*", true},
+		{"guest4?!guest@*", true},
+		{"other!*@*", false},
+		{"guest42", true}, // A bare nick
+		{"*.example.net", true},
+		{"~account:0", true},
+		{"~a:guest", false},
+		{"~country:nl", true},
+		{"~C:DE", false},
+		{"~security-group:webirc-*", true},
+		{"~G:known-users", false},
+		{"~realname:just*", true},
+		{"~channel:#help", true},
+		{"~c:@#lobby", true},
+		{"~c:#staff", false},
+		{"~time:60:*@192.0.2.7", true},
+		{"~quiet:*!*@*.example.net", true},
+		{"~nickchange:other!*@*", false},
+	}
+	for _, tt := range tests {
+		mask, err := ParseBanMask(tt.mask)
+		if err != nil {
+			t.Errorf("ParseBanMask(%q): %v", tt.mask, err)
+			continue
+		}
+		if got := mask.Match(banMatchUser); got != tt.want {
+			t.Errorf("mask %q: Match = %v, want %v", tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestBanMaskAccount(t *testing.T) {
+	user := banMatchUser
+	user.Account = "Alice"
+	for mask, want := range map[string]bool{
+		"~account:alice": true,
+		"~account:al*":   true,
+		"~account:0":     false,
+		"~account:bob":   false,
+	} {
+		parsed, err := ParseBanMask(mask)
+		if err != nil {
+			t.Errorf("ParseBanMask(%q): %v", mask, err)
+			continue
+		}
+		if got := parsed.Match(user); got != want {
+			t.Errorf("mask %q: Match = %v, want %v", mask, got, want)
+		}
+	}
+}
+
+func TestServerBanMaskIPOnly(t *testing.T) {
+	tests := []struct {
+		banType, mask string
+		want          bool
+	}{
+		{"gline", "*@dsl-7.example.net", true},
+		{"zline", "*@dsl-7.example.net", false},
+		{"gzline", "*@*.example.net", false},
+		{"zline", "*@clk-1a2b3c4d.example.net", false},
+		{"zline", "*@192.0.2.7", true},
+		{"gzline", "*@192.0.2.*", true},
+		{"zline", "*@192.0.2.0/24", true},
+		{"gzline", "*@198.51.100.*", false},
+		{"kline", "*@192.0.2.7", true},
+		{"kline", "*@guest.users.example.org", false},
+		{"gline", "*@clk-1a2b3c4d.example.net", false},
+		{"gline", "guest@*.example.org", false},
+		{"shun", "*@dsl-7.example.net", true},
+	}
+	for _, tt := range tests {
+		mask, err := ParseServerBanMask(tt.banType, tt.mask)
+		if err != nil {
+			t.Errorf("ParseServerBanMask(%s, %q): %v", tt.banType, tt.mask, err)
+			continue
+		}
+		if got := mask.Match(banMatchUser); got != tt.want {
+			t.Errorf("%s on %q: Match = %v, want %v", tt.banType, tt.mask, got, tt.want)
+		}
+	}
+}
+
+func TestParseBanMaskErrors(t *testing.T) {
+	for _, mask := range []string{
+		"",
+		"   ",
+		"~account",
+		"~account:",
+		"~time:60",
+		"~certfp:abc",
+		"nick!",
+		"!user@host",
+		"*@192.0.2.0/33",
+		"~quiet:*@192.0.2.0/99",
+	} {
+		if _, err := ParseBanMask(mask); err == nil {
+			t.Errorf("ParseBanMask(%q) succeeded, want an error", mask)
+		}
+	}
+}
+
+func TestUserBanMasks(t *testing.T) {
+	user := banMatchUser
+	user.Account = "alice"
+
+	got := UserBanMasks(user, false)
+	want := []string{
+		"guest@dsl-7.example.net",
+		"*@dsl-7.example.net",
+		"*@192.0.2.7",
+		"*@192.0.2.0/24",
+		"~account:alice",
+		"~country:NL",
+		"~security-group:unknown-users",
+		"~security-group:webirc-users",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("UserBanMasks(server) = %q, want %q", got, want)
+	}
+
+	got = UserBanMasks(user, true)
+	if len(got) < 3 || got[0] != "*!guest@dsl-7.example.net" || got[2] != "*!*@Clk-1A2B3C4D.example.net" {
+		t.Errorf("UserBanMasks(channel) = %q, want nick!user@host masks with the cloaked host", got)
+	}
+
+	// Every mask offered for a user must match that user
+	for _, channel := range []bool{false, true} {
+		for _, mask := range UserBanMasks(user, channel) {
+			parsed, err := ParseBanMask(mask)
+			if err != nil {
+				t.Errorf("ParseBanMask(%q): %v", mask, err)
+				continue
+			}
+			if !parsed.Match(user) {
+				t.Errorf("mask %q offered for the user does not match it", mask)
+			}
+		}
+	}
+}
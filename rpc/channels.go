@@ -0,0 +1,179 @@
+package rpc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ChannelPrefixes are the member prefix modes, from the highest level to the lowest
+var ChannelPrefixes = []struct {
+	Letter      string
+	Symbol      string
+	Description string
+}{
+	{"q", "~", "Owner"},
+	{"a", "&", "Admin"},
+	{"o", "@", "Operator"},
+	{"h", "%", "Half-op"},
+	{"v", "+", "Voice"},
+}
+
+// ChannelListModes are the channel list modes and what their entries do
+var ChannelListModes = []struct {
+	Letter      string
+	Description string
+}{
+	{"b", "Bans"},
+	{"e", "Ban Exemptions"},
+	{"I", "Invite Exceptions"},
+}
+
+// Prefix returns the symbol of the highest prefix mode of the member, or "" for none
+func (m ChannelMember) Prefix() string {
+	if rank := m.Rank(); rank < len(ChannelPrefixes) {
+		return ChannelPrefixes[rank].Symbol
+	}
+	return ""
+}
+
+// Rank returns the index in ChannelPrefixes of the highest prefix mode of the
+// member, or len(ChannelPrefixes) for members without one
+func (m ChannelMember) Rank() int {
+	for i, prefix := range ChannelPrefixes {
+		if strings.Contains(m.Level, prefix.Letter) {
+			return i
+		}
+	}
+	return len(ChannelPrefixes)
+}
+
+// SortChannelMembers sorts members by their highest prefix mode (~ & @ % +), then by nick
+func SortChannelMembers(members []ChannelMember) {
+	sort.SliceStable(members, func(i, j int) bool {
+		ri, rj := members[i].Rank(), members[j].Rank()
+		if ri != rj {
+			return ri < rj
+		}
+		return strings.ToLower(members[i].Nick) < strings.ToLower(members[j].Nick)
+	})
+}
+
+// ListModeEntries returns the entries of a list mode ("b", "e" or "I") of the channel
+func (c ChannelInfo) ListModeEntries(mode string) []ListModeEntry {
+	switch mode {
+	case "b":
+		return c.Bans
+	case "e":
+		return c.BanExemptions
+	case "I":
+		return c.InviteExceptions
+	}
+	return nil
+}
+
+// SetChannelTopic changes the topic of a channel
+func (r *RPCClient) SetChannelTopic(channel, topic string) error {
+	params := map[string]interface{}{
+		"channel": channel,
+		"topic":   topic,
+	}
+	if _, err := r.query("channel.set_topic", params); err != nil {
+		return fmt.Errorf("failed to set topic of %s: %w", channel, err)
+	}
+	return nil
+}
+
+// SetChannelMode sets and unsets channel modes, like "+nt-s". parameters holds the
+// space separated mode parameters, in the same order as the modes that take one.
+func (r *RPCClient) SetChannelMode(channel, modes, parameters string) error {
+	params := map[string]interface{}{
+		"channel":    channel,
+		"modes":      modes,
+		"parameters": parameters,
+	}
+	if _, err := r.query("channel.set_mode", params); err != nil {
+		return fmt.Errorf("failed to set modes %s on %s: %w", modes, channel, err)
+	}
+	return nil
+}
+
+// KickUser kicks a member out of a channel
+func (r *RPCClient) KickUser(channel, nick, reason string) error {
+	params := map[string]interface{}{
+		"channel": channel,
+		"nick":    nick,
+		"reason":  reason,
+	}
+	if _, err := r.query("channel.kick", params); err != nil {
+		return fmt.Errorf("failed to kick %s from %s: %w", nick, channel, err)
+	}
+	return nil
+}
+
+// AddChannelListEntry adds mask to a list mode ("b", "e" or "I") of a channel
+func (r *RPCClient) AddChannelListEntry(channel, mode, mask string) error {
+	return r.SetChannelMode(channel, "+"+mode, mask)
+}
+
+// DeleteChannelListEntry removes mask from a list mode ("b", "e" or "I") of a channel
+func (r *RPCClient) DeleteChannelListEntry(channel, mode, mask string) error {
+	return r.SetChannelMode(channel, "-"+mode, mask)
+}
+
+func parseChannelMember(memberMap map[string]interface{}) ChannelMember {
+	member := ChannelMember{}
+
+	if name, ok := memberMap["name"].(string); ok {
+		member.Nick = name
+	} else if nick, ok := memberMap["nick"].(string); ok {
+		member.Nick = nick
+	}
+	if level, ok := memberMap["level"].(string); ok {
+		member.Level = level
+	}
+	if hostname, ok := memberMap["hostname"].(string); ok {
+		member.Hostname = hostname
+	}
+	if ip, ok := memberMap["ip"].(string); ok {
+		member.IP = ip
+	}
+	if username, ok := memberMap["username"].(string); ok {
+		member.Username = username
+	} else if userMap, ok := memberMap["user"].(map[string]interface{}); ok {
+		if username, ok := userMap["username"].(string); ok {
+			member.Username = username
+		}
+	}
+
+	return member
+}
+
+// parseListModeEntries parses the entries of a channel list mode, as found under
+// "bans", "ban_exemptions" and "invite_exceptions" in channel.get
+func parseListModeEntries(data interface{}) []ListModeEntry {
+	list, ok := data.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var entries []ListModeEntry
+	for _, e := range list {
+		entryMap, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		entry := ListModeEntry{}
+		if name, ok := entryMap["name"].(string); ok {
+			entry.Mask = name
+		}
+		if setBy, ok := entryMap["set_by"].(string); ok {
+			entry.SetBy = setBy
+		}
+		if setAt, ok := entryMap["set_at"].(string); ok {
+			entry.SetAt = parseRPCTime(setAt)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
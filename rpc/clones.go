@@ -0,0 +1,96 @@
+package rpc
+
+import (
+	"net"
+	"sort"
+	"strings"
+)
+
+// CloneGroupings are the ways users can be grouped into clones: by IP, by /24
+// (IPv4) or /64 (IPv6) network, and by account
+var CloneGroupings = []string{"ip", "network", "account"}
+
+// CloneGroup is a set of connected users sharing an IP, network or account
+type CloneGroup struct {
+	By    string // One of CloneGroupings
+	Key   string // The IP, network in CIDR notation or account
+	Users []UserInfo
+}
+
+// GroupClones groups users by one of CloneGroupings, leaving out users that are
+// alone in their group. The largest groups come first.
+func GroupClones(users []UserInfo, by string) []CloneGroup {
+	groups := make(map[string]*CloneGroup)
+	var keys []string
+	for _, user := range users {
+		key := cloneKey(user, by)
+		if key == "" {
+			continue
+		}
+		group, ok := groups[strings.ToLower(key)]
+		if !ok {
+			group = &CloneGroup{By: by, Key: key}
+			groups[strings.ToLower(key)] = group
+			keys = append(keys, strings.ToLower(key))
+		}
+		group.Users = append(group.Users, user)
+	}
+
+	var clones []CloneGroup
+	for _, key := range keys {
+		group := groups[key]
+		if len(group.Users) < 2 {
+			continue
+		}
+		sort.Slice(group.Users, func(i, j int) bool {
+			return strings.ToLower(group.Users[i].Nick) < strings.ToLower(group.Users[j].Nick)
+		})
+		clones = append(clones, *group)
+	}
+	sort.SliceStable(clones, func(i, j int) bool {
+		if len(clones[i].Users) != len(clones[j].Users) {
+			return len(clones[i].Users) > len(clones[j].Users)
+		}
+		return clones[i].Key < clones[j].Key
+	})
+	return clones
+}
+
+// cloneKey returns what user is grouped on, or "" when that is unknown
+func cloneKey(user UserInfo, by string) string {
+	switch by {
+	case "ip":
+		return user.IP
+	case "network":
+		return ipNetwork(user.IP)
+	case "account":
+		if user.Account == "none" {
+			return ""
+		}
+		return user.Account
+	}
+	return ""
+}
+
+// ipNetwork returns the /24 of an IPv4 or the /64 of an IPv6 address in CIDR
+// notation, or "" when ip is not an address
+func ipNetwork(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	if v4 := addr.To4(); v4 != nil {
+		network := net.IPNet{IP: v4.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)}
+		return network.String()
+	}
+	network := net.IPNet{IP: addr.Mask(net.CIDRMask(64, 128)), Mask: net.CIDRMask(64, 128)}
+	return network.String()
+}
+
+// BanMask returns the server ban mask covering the whole group
+func (g CloneGroup) BanMask() string {
+	if g.By == "account" {
+		return "~account:" + g.Key
+	}
+	return "*@" + g.Key
+}
@@ -5,53 +5,324 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
+// RPCConfig is a named connection profile, for one server or network
 type RPCConfig struct {
+	Name     string `json:"name"`
 	Username string `json:"username"`
-	Password string `json:"password"`
+	Password string `json:"-"` // Only set while it is being typed in, see ResolvePassword
 	WSURL    string `json:"ws_url"`
+	Socket   string `json:"socket,omitempty"` // Path of a UNIX socket, used instead of WSURL
+	Detected bool   `json:"-"`                // Found by DetectLocalRPC rather than saved
+
+	// LegacyPassword is a password found in plain text in the config file. It
+	// is kept there as it is until ConvertLegacyPasswords replaces it.
+	LegacyPassword string `json:"-"`
+
+	PasswordSource
+	TLSSettings
 }
 
 const rpcConfigFile = ".unrealircd_rpc_config"
 
-func LoadRPCConfig() (*RPCConfig, error) {
+// The config file holds the connection profiles and settings shared by all of
+// them, like saved log filters and table columns. They are kept as raw JSON so
+// saving one part leaves the others alone.
+func configFilePath() (string, error) {
 	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, rpcConfigFile), nil
+}
+
+// configFileMode is the only mode the config file may have, it holds RPC credentials
+const configFileMode = 0600
+
+// configMu is held while the config file is read, changed and written back, so
+// that saving one part does not undo another part saved at the same time
+var configMu sync.Mutex
+
+// readConfigFile returns the top level fields of the config file, nil if there is none
+func readConfigFile() (map[string]json.RawMessage, error) {
+	configPath, err := configFilePath()
 	if err != nil {
 		return nil, err
 	}
-	configPath := filepath.Join(home, rpcConfigFile)
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	data, err := os.ReadFile(configPath)
+	if os.IsNotExist(err) {
 		return nil, nil // No config file
 	}
-	file, err := os.Open(configPath)
 	if err != nil {
 		return nil, err
 	}
+	// Close up a file written by older versions, readable by anyone
+	if info, err := os.Stat(configPath); err == nil && info.Mode().Perm() != configFileMode {
+		if err := os.Chmod(configPath, configFileMode); err != nil {
+			return nil, fmt.Errorf("failed to restrict the permissions of %s: %w", configPath, err)
+		}
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", configPath, err)
+	}
+	return fields, nil
+}
+
+// writeConfigFile replaces the config file with fields. They are written to a
+// temporary file next to it first, which is renamed over it, so that a crash
+// while writing does not leave a partial file and lose the profiles.
+func writeConfigFile(fields map[string]json.RawMessage) error {
+	configPath, err := configFilePath()
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp(filepath.Dir(configPath), rpcConfigFile+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name()) // Fails once it was renamed
 	defer file.Close()
+
+	if err := file.Chmod(configFileMode); err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(fields); err != nil {
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), configPath)
+}
+
+// savedProfile is a profile as stored in the config file
+type savedProfile struct {
+	RPCConfig
+	LegacyPassword string `json:"password,omitempty"`
+}
+
+const (
+	rpcProfilesKey      = "profiles"
+	rpcActiveProfileKey = "active_profile"
+
+	// migratedProfileName is the name the connection of a config file from
+	// before profiles gets
+	migratedProfileName = "default"
+)
+
+// readProfiles returns the config file fields with the saved profiles and the
+// name of the active one, "" when none was chosen. A config file holding a
+// single connection at the top level, from before profiles, is turned into a
+// profile and saved, so configMu must be held.
+func readProfiles() (map[string]json.RawMessage, []RPCConfig, string, error) {
+	fields, err := readConfigFile()
+	if err != nil || fields == nil {
+		return fields, nil, "", err
+	}
+
+	var profiles []RPCConfig
+	var active string
+	if data, ok := fields[rpcProfilesKey]; ok {
+		var saved []savedProfile
+		if err := json.Unmarshal(data, &saved); err != nil {
+			return nil, nil, "", fmt.Errorf("invalid RPC profiles: %w", err)
+		}
+		for _, profile := range saved {
+			profile.RPCConfig.LegacyPassword = profile.LegacyPassword
+			profiles = append(profiles, profile.RPCConfig)
+		}
+		if data, ok := fields[rpcActiveProfileKey]; ok {
+			json.Unmarshal(data, &active)
+		}
+		return fields, profiles, active, nil
+	}
+
+	if _, ok := fields["ws_url"]; !ok {
+		return fields, nil, "", nil // Not configured yet
+	}
 	var config RPCConfig
-	err = json.NewDecoder(file).Decode(&config)
-	return &config, err
+	for key, value := range map[string]*string{"username": &config.Username, "password": &config.LegacyPassword, "ws_url": &config.WSURL} {
+		json.Unmarshal(fields[key], value)
+		delete(fields, key)
+	}
+	config.Name = migratedProfileName
+	profiles = []RPCConfig{config}
+	// None is made active, so the local installation is preferred when it is detected
+	if err := writeProfiles(fields, profiles, ""); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to migrate the RPC config to profiles: %w", err)
+	}
+	return fields, profiles, "", nil
 }
 
-func SaveRPCConfig(config *RPCConfig) error {
-	home, err := os.UserHomeDir()
+// writeProfiles stores the profiles and the name of the active one in fields
+// and writes the config file, with configMu held. Detected profiles are left
+// out. Plain text passwords are written back as they were, so that saving
+// other changes does not lose them before they are converted.
+func writeProfiles(fields map[string]json.RawMessage, profiles []RPCConfig, active string) error {
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	saved := make([]savedProfile, 0, len(profiles))
+	for _, profile := range profiles {
+		if !profile.Detected {
+			saved = append(saved, savedProfile{RPCConfig: profile, LegacyPassword: profile.LegacyPassword})
+		}
+	}
+	data, err := json.Marshal(saved)
 	if err != nil {
 		return err
 	}
-	configPath := filepath.Join(home, rpcConfigFile)
-	file, err := os.Create(configPath)
+	fields[rpcProfilesKey] = data
+	if data, err = json.Marshal(active); err != nil {
+		return err
+	}
+	fields[rpcActiveProfileKey] = data
+	return writeConfigFile(fields)
+}
+
+func findProfile(profiles []RPCConfig, name string) int {
+	for i, profile := range profiles {
+		if profile.Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// LoadRPCProfiles returns every connection profile, with local first when it
+// is not nil, and the name of the one in use: the active profile, or the first
+// when none was chosen or the chosen one is gone
+func LoadRPCProfiles(local *RPCConfig) ([]RPCConfig, string, error) {
+	configMu.Lock()
+	defer configMu.Unlock()
+	_, profiles, active, err := readProfiles()
+	if err != nil {
+		return nil, "", err
+	}
+	if local != nil && findProfile(profiles, local.Name) < 0 {
+		profiles = append([]RPCConfig{*local}, profiles...)
+	}
+	if findProfile(profiles, active) < 0 {
+		active = ""
+		if len(profiles) > 0 {
+			active = profiles[0].Name
+		}
+	}
+	return profiles, active, nil
+}
+
+// LoadRPCConfig returns the profile in use, see LoadRPCProfiles. It returns
+// nil when there are no profiles yet and local is nil.
+func LoadRPCConfig(local *RPCConfig) (*RPCConfig, error) {
+	profiles, active, err := LoadRPCProfiles(local)
+	if err != nil || len(profiles) == 0 {
+		return nil, err
+	}
+	return &profiles[findProfile(profiles, active)], nil
+}
+
+// SaveRPCProfile stores config as the profile called oldName, or as a new
+// profile when oldName is empty, and makes it the active profile
+func SaveRPCProfile(oldName string, config *RPCConfig) error {
+	if config.Name == "" {
+		return fmt.Errorf("the profile needs a name")
+	}
+	configMu.Lock()
+	defer configMu.Unlock()
+	fields, profiles, _, err := readProfiles()
 	if err != nil {
 		return err
 	}
-	defer file.Close()
-	return json.NewEncoder(file).Encode(config)
+
+	i := -1
+	if oldName != "" {
+		i = findProfile(profiles, oldName)
+	}
+	if existing := findProfile(profiles, config.Name); existing >= 0 && existing != i {
+		return fmt.Errorf("a profile called %s already exists", config.Name)
+	}
+	if i >= 0 {
+		profiles[i] = *config
+	} else {
+		profiles = append(profiles, *config)
+	}
+	return writeProfiles(fields, profiles, config.Name)
+}
+
+// ConvertLegacyPasswords replaces the plain text passwords of profiles by the
+// password sources for them in sources, by profile name, and rewrites the
+// config file without them
+func ConvertLegacyPasswords(sources map[string]PasswordSource) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	fields, profiles, active, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	for i := range profiles {
+		if profiles[i].LegacyPassword == "" {
+			continue
+		}
+		source, ok := sources[profiles[i].Name]
+		if !ok || source == (PasswordSource{}) {
+			return fmt.Errorf("no password source given for profile %s", profiles[i].Name)
+		}
+		profiles[i].PasswordSource = source
+		profiles[i].LegacyPassword = ""
+	}
+	return writeProfiles(fields, profiles, active)
+}
+
+// SetActiveRPCProfile makes the profile called name the one the remote control
+// menu uses. That is a saved profile, or LocalProfileName for the local installation.
+func SetActiveRPCProfile(name string) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	fields, profiles, _, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	if findProfile(profiles, name) < 0 && name != LocalProfileName {
+		return fmt.Errorf("no profile called %s", name)
+	}
+	return writeProfiles(fields, profiles, name)
+}
+
+// DeleteRPCProfile forgets the profile called name, keeping the rest of the config
+// file. If name was active, none is until another one is chosen.
+func DeleteRPCProfile(name string) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	fields, profiles, active, err := readProfiles()
+	if err != nil {
+		return err
+	}
+	i := findProfile(profiles, name)
+	if i < 0 {
+		return nil
+	}
+	profiles = append(profiles[:i], profiles[i+1:]...)
+	if active == name {
+		active = ""
+	}
+	return writeProfiles(fields, profiles, active)
 }
 
 func TestRPCConnection(config *RPCConfig) error {
-	// Test actual RPC connection
-	if config.Username == "" || config.Password == "" || config.WSURL == "" {
-		return fmt.Errorf("missing RPC configuration")
+	// Test actual RPC connection, the UNIX socket needs no credentials
+	if config.Socket == "" {
+		if config.Username == "" || config.WSURL == "" {
+			return fmt.Errorf("missing RPC configuration")
+		}
+		if _, err := config.ResolvePassword(); err != nil {
+			return err
+		}
 	}
 
 	client, err := NewRPCClient(config)
@@ -65,7 +336,7 @@ func TestRPCConnection(config *RPCConfig) error {
 	}
 
 	// Actually test by trying to get server info
-	_, err = client.conn.Rpc().Info()
+	_, err = client.query("rpc.info", nil)
 	if err != nil {
 		return fmt.Errorf("failed to get server info: %w", err)
 	}
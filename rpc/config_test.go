@@ -0,0 +1,263 @@
+package rpc
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// writeTestConfig writes a config file to a temporary home directory
+func writeTestConfig(t *testing.T, content string) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	configPath := filepath.Join(home, rpcConfigFile)
+	if content != "" {
+		if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return configPath
+}
+
+func readTestConfig(t *testing.T, configPath string) string {
+	t.Helper()
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestLegacyPasswordConversion(t *testing.T) {
+	configPath := writeTestConfig(t, `{"username":"admin","password":"secret","ws_url":"wss://irc.example.net:8600/","log_presets":[]}`)
+
+	profiles, active, err := LoadRPCProfiles(nil)
+	if err != nil {
+		t.Fatalf("LoadRPCProfiles: %v", err)
+	}
+	if len(profiles) != 1 || active != migratedProfileName {
+		t.Fatalf("LoadRPCProfiles = %+v, %q, want the migrated profile", profiles, active)
+	}
+	if profiles[0].LegacyPassword != "secret" || profiles[0].Username != "admin" {
+		t.Errorf("migrated profile = %+v", profiles[0])
+	}
+	if info, _ := os.Stat(configPath); info.Mode().Perm() != configFileMode {
+		t.Errorf("config file mode = %v, want %v", info.Mode().Perm(), os.FileMode(configFileMode))
+	}
+
+	// Other changes are saved, keeping the password until it is converted
+	if err := SetActiveRPCProfile(migratedProfileName); err != nil {
+		t.Errorf("SetActiveRPCProfile with a plain text password left: %v", err)
+	}
+	if err := SaveTableColumns("users", []string{"nick"}); err != nil {
+		t.Errorf("SaveTableColumns with a plain text password left: %v", err)
+	}
+	if !strings.Contains(readTestConfig(t, configPath), `"password":"secret"`) {
+		t.Error("plain text password dropped before it was converted")
+	}
+	if profiles, _, err := LoadRPCProfiles(nil); err != nil || len(profiles) != 1 || profiles[0].LegacyPassword != "secret" {
+		t.Errorf("after saving other changes: %+v, %v", profiles, err)
+	}
+
+	if err := ConvertLegacyPasswords(nil); err == nil {
+		t.Error("ConvertLegacyPasswords without a source succeeded")
+	}
+	if err := ConvertLegacyPasswords(map[string]PasswordSource{migratedProfileName: {Env: "RPC_PASSWORD"}}); err != nil {
+		t.Fatalf("ConvertLegacyPasswords: %v", err)
+	}
+
+	data := readTestConfig(t, configPath)
+	if strings.Contains(data, "secret") || strings.Contains(data, `"password"`) {
+		t.Errorf("config file still holds the password: %s", data)
+	}
+	if !strings.Contains(data, `"log_presets"`) {
+		t.Errorf("other settings lost: %s", data)
+	}
+	config, err := LoadRPCConfig(nil)
+	if err != nil {
+		t.Fatalf("LoadRPCConfig: %v", err)
+	}
+	if config.Env != "RPC_PASSWORD" || config.LegacyPassword != "" || config.Username != "admin" {
+		t.Errorf("converted profile = %+v", config)
+	}
+}
+
+func TestLegacyPasswordInProfiles(t *testing.T) {
+	configPath := writeTestConfig(t, `{"profiles":[`+
+		`{"name":"hub","username":"admin","password":"secret1","ws_url":"wss://hub.example.net:8600/"},`+
+		`{"name":"leaf","username":"admin","password_env":"LEAF_PASSWORD","ws_url":"wss://leaf.example.net:8600/"}],`+
+		`"active_profile":"leaf"}`)
+
+	profiles, _, err := LoadRPCProfiles(nil)
+	if err != nil {
+		t.Fatalf("LoadRPCProfiles: %v", err)
+	}
+	if profiles[0].LegacyPassword != "secret1" || profiles[1].LegacyPassword != "" {
+		t.Fatalf("legacy passwords = %q, %q", profiles[0].LegacyPassword, profiles[1].LegacyPassword)
+	}
+
+	if err := ConvertLegacyPasswords(map[string]PasswordSource{"hub": {Command: "pass show irc/hub"}}); err != nil {
+		t.Fatalf("ConvertLegacyPasswords: %v", err)
+	}
+	if data := readTestConfig(t, configPath); strings.Contains(data, "secret1") {
+		t.Errorf("config file still holds the password: %s", data)
+	}
+	profiles, active, err := LoadRPCProfiles(nil)
+	if err != nil {
+		t.Fatalf("LoadRPCProfiles: %v", err)
+	}
+	if active != "leaf" || profiles[0].Command != "pass show irc/hub" || profiles[1].Env != "LEAF_PASSWORD" {
+		t.Errorf("after converting: %+v, active %q", profiles, active)
+	}
+}
+
+func TestEncryptedPassword(t *testing.T) {
+	encrypted, err := EncryptPassword("secret", "correct horse")
+	if err != nil {
+		t.Fatalf("EncryptPassword: %v", err)
+	}
+	if strings.Contains(encrypted, "secret") || !strings.HasPrefix(encrypted, encryptedPasswordPrefix) {
+		t.Errorf("EncryptPassword = %q", encrypted)
+	}
+
+	if _, err := decryptPassword(encrypted, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("decrypting with the wrong passphrase: %v, want ErrWrongPassphrase", err)
+	}
+	password, err := decryptPassword(encrypted, "correct horse")
+	if err != nil || password != "secret" {
+		t.Errorf("decryptPassword = %q, %v, want secret", password, err)
+	}
+
+	// The passphrase given while encrypting unlocks it for the rest of the run
+	config := &RPCConfig{Name: "hub", PasswordSource: PasswordSource{Encrypted: encrypted}}
+	if PasswordLocked(config) {
+		t.Error("PasswordLocked after encrypting it this run")
+	}
+	if password, err := config.ResolvePassword(); err != nil || password != "secret" {
+		t.Errorf("ResolvePassword = %q, %v, want secret", password, err)
+	}
+}
+
+func TestLocalProfile(t *testing.T) {
+	configPath := writeTestConfig(t, `{"username":"admin","password_env":"RPC_PASSWORD","ws_url":"wss://irc.example.net:8600/"}`)
+	local := &RPCConfig{Name: LocalProfileName, Socket: "/home/ircd/unrealircd/data/rpc.socket", Detected: true}
+
+	// A migrated profile is not made active, so the local one is used
+	config, err := LoadRPCConfig(local)
+	if err != nil {
+		t.Fatalf("LoadRPCConfig: %v", err)
+	}
+	if config.Name != LocalProfileName || !config.Detected {
+		t.Errorf("LoadRPCConfig = %+v, want the local profile", config)
+	}
+	if config, _ := LoadRPCConfig(nil); config == nil || config.Name != migratedProfileName {
+		t.Errorf("LoadRPCConfig without a local profile = %+v, want %s", config, migratedProfileName)
+	}
+
+	// Chosen profiles are used over it, and it stays listed first
+	if err := SetActiveRPCProfile(migratedProfileName); err != nil {
+		t.Fatalf("SetActiveRPCProfile: %v", err)
+	}
+	profiles, active, err := LoadRPCProfiles(local)
+	if err != nil {
+		t.Fatalf("LoadRPCProfiles: %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != LocalProfileName || active != migratedProfileName {
+		t.Errorf("LoadRPCProfiles = %+v, %q", profiles, active)
+	}
+
+	if err := SetActiveRPCProfile(LocalProfileName); err != nil {
+		t.Fatalf("SetActiveRPCProfile(%s): %v", LocalProfileName, err)
+	}
+	if config, _ := LoadRPCConfig(local); config == nil || config.Name != LocalProfileName {
+		t.Errorf("LoadRPCConfig = %+v, want the local profile after choosing it", config)
+	}
+	if data := readTestConfig(t, configPath); strings.Contains(data, "rpc.socket") {
+		t.Errorf("local profile saved: %s", data)
+	}
+
+	// Without a socket the saved profile is used instead
+	if config, _ := LoadRPCConfig(nil); config == nil || config.Name != migratedProfileName {
+		t.Errorf("LoadRPCConfig without a local profile = %+v, want %s", config, migratedProfileName)
+	}
+	if err := DeleteRPCProfile(migratedProfileName); err != nil {
+		t.Fatalf("DeleteRPCProfile: %v", err)
+	}
+	if config, err := LoadRPCConfig(nil); err != nil || config != nil {
+		t.Errorf("LoadRPCConfig with no profiles = %+v, %v, want nil", config, err)
+	}
+}
+
+func TestDetectLocalRPC(t *testing.T) {
+	buildDir, err := os.MkdirTemp("", "rpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(buildDir)
+	if local := DetectLocalRPC(buildDir); local != nil {
+		t.Errorf("DetectLocalRPC without a socket = %+v", local)
+	}
+
+	if err := os.Mkdir(filepath.Join(buildDir, "data"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	socketPath := filepath.Join(buildDir, LocalSocketPath)
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	local := DetectLocalRPC(buildDir)
+	if local == nil || local.Socket != socketPath || !local.Detected {
+		t.Errorf("DetectLocalRPC = %+v, want a profile for %s", local, socketPath)
+	}
+}
+
+func TestConcurrentConfigWrites(t *testing.T) {
+	configPath := writeTestConfig(t, `{"profiles":[{"name":"hub","username":"admin","password_env":"HUB_PASSWORD","ws_url":"wss://hub.example.net:8600/"}]}`)
+
+	// Every save reads the whole file and writes it back, none may undo another
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		table := string(rune('a' + i))
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if err := SaveTableColumns(table, []string{"nick"}); err != nil {
+				t.Errorf("SaveTableColumns: %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := SetActiveRPCProfile("hub"); err != nil {
+				t.Errorf("SetActiveRPCProfile: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i := 0; i < 20; i++ {
+		table := string(rune('a' + i))
+		if columns, err := LoadTableColumns(table); err != nil || len(columns) != 1 {
+			t.Errorf("columns of table %s = %v, %v", table, columns, err)
+		}
+	}
+	if _, active, err := LoadRPCProfiles(nil); err != nil || active != "hub" {
+		t.Errorf("LoadRPCProfiles: active %q, %v", active, err)
+	}
+	if info, _ := os.Stat(configPath); info.Mode().Perm() != configFileMode {
+		t.Errorf("config file mode = %v, want %v", info.Mode().Perm(), os.FileMode(configFileMode))
+	}
+	entries, err := os.ReadDir(filepath.Dir(configPath))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("files next to the config file left behind: %v", entries)
+	}
+}
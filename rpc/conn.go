@@ -0,0 +1,298 @@
+package rpc
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	callTimeout      = 30 * time.Second
+	handshakeTimeout = 10 * time.Second
+	pingInterval     = 30 * time.Second
+	readTimeout      = 3 * pingInterval // connection is considered dead after this long without traffic
+)
+
+// ErrConnectionClosed is returned for calls on a connection that was closed or dropped
+var ErrConnectionClosed = errors.New("RPC connection closed")
+
+type rpcRequest struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+	ID      int64       `json:"id"`
+}
+
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Params json.RawMessage `json:"params"`
+	Error  *RPCError       `json:"error"`
+}
+
+// RPCError is an error returned by the server for a call
+type RPCError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// transport carries the JSON-RPC messages of a conn, over a WebSocket or a
+// UNIX socket. Writes are serialized by the conn.
+type transport interface {
+	writeJSON(v interface{}, deadline time.Time) error
+	readMessage() ([]byte, error)
+	ping() error
+	close() error // Closes cleanly, telling the server if the protocol has a way to
+	abort() error // Drops the connection
+}
+
+// conn is a JSON-RPC 2.0 connection to UnrealIRCd. Replies are matched to calls
+// by id, anything else the server sends (log events) is handed to onEvent.
+type conn struct {
+	t       transport
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *rpcMessage
+	err     error // set once the connection is gone
+	closed  chan struct{}
+
+	onEvent func(msg *rpcMessage)
+	onClose func(err error) // called when the connection drops, not on Close()
+}
+
+func dialConn(config *RPCConfig, onEvent func(msg *rpcMessage), onClose func(err error)) (*conn, error) {
+	var t transport
+	var err error
+	if config.Socket != "" {
+		t, err = dialUnix(config.Socket)
+	} else {
+		t, err = dialWebSocket(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c := &conn{
+		t:       t,
+		pending: make(map[int64]chan *rpcMessage),
+		closed:  make(chan struct{}),
+		onEvent: onEvent,
+		onClose: onClose,
+	}
+
+	go c.readLoop()
+	go c.pingLoop()
+
+	return c, nil
+}
+
+// wsTransport is JSON-RPC over a WebSocket, kept alive with pings
+type wsTransport struct {
+	ws *websocket.Conn
+}
+
+func dialWebSocket(config *RPCConfig) (*wsTransport, error) {
+	tlsConfig, err := tlsClientConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	dialer := websocket.Dialer{
+		HandshakeTimeout: handshakeTimeout,
+		TLSClientConfig:  tlsConfig,
+	}
+
+	password, err := config.ResolvePassword()
+	if err != nil {
+		return nil, err
+	}
+	header := http.Header{}
+	auth := base64.StdEncoding.EncodeToString([]byte(config.Username + ":" + password))
+	header.Set("Authorization", "Basic "+auth)
+
+	ws, resp, err := dialer.Dial(config.WSURL, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return nil, fmt.Errorf("authentication failed for RPC user %s", config.Username)
+		}
+		return nil, err
+	}
+
+	ws.SetReadDeadline(time.Now().Add(readTimeout))
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	return &wsTransport{ws: ws}, nil
+}
+
+func (w *wsTransport) writeJSON(v interface{}, deadline time.Time) error {
+	w.ws.SetWriteDeadline(deadline)
+	return w.ws.WriteJSON(v)
+}
+
+func (w *wsTransport) readMessage() ([]byte, error) {
+	_, data, err := w.ws.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	w.ws.SetReadDeadline(time.Now().Add(readTimeout))
+	return data, nil
+}
+
+func (w *wsTransport) ping() error {
+	return w.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(handshakeTimeout))
+}
+
+func (w *wsTransport) close() error {
+	w.ws.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return w.ws.Close()
+}
+
+func (w *wsTransport) abort() error {
+	return w.ws.Close()
+}
+
+// call sends a request and waits for its reply, returning the decoded result
+func (c *conn) call(method string, params interface{}) (interface{}, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	}
+	c.nextID++
+	id := c.nextID
+	replyChan := make(chan *rpcMessage, 1)
+	c.pending[id] = replyChan
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	req := rpcRequest{JSONRPC: "2.0", Method: method, Params: params, ID: id}
+	c.writeMu.Lock()
+	err := c.t.writeJSON(req, time.Now().Add(callTimeout))
+	c.writeMu.Unlock()
+	if err != nil {
+		c.fail(err)
+		return nil, err
+	}
+
+	select {
+	case reply := <-replyChan:
+		if reply.Error != nil {
+			return nil, reply.Error
+		}
+		var result interface{}
+		if len(reply.Result) > 0 {
+			if err := json.Unmarshal(reply.Result, &result); err != nil {
+				return nil, fmt.Errorf("invalid result for %s: %w", method, err)
+			}
+		}
+		return result, nil
+	case <-c.closed:
+		c.mu.Lock()
+		err := c.err
+		c.mu.Unlock()
+		return nil, err
+	case <-time.After(callTimeout):
+		return nil, fmt.Errorf("timeout waiting for reply to %s", method)
+	}
+}
+
+func (c *conn) readLoop() {
+	for {
+		data, err := c.t.readMessage()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+
+		var msg rpcMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			continue // Not JSON-RPC, ignore
+		}
+
+		if id, err := strconv.ParseInt(string(msg.ID), 10, 64); err == nil {
+			c.mu.Lock()
+			replyChan, ok := c.pending[id]
+			c.mu.Unlock()
+			if ok {
+				replyChan <- &msg
+				continue
+			}
+		}
+
+		if c.onEvent != nil {
+			c.onEvent(&msg)
+		}
+	}
+}
+
+func (c *conn) pingLoop() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.writeMu.Lock()
+			err := c.t.ping()
+			c.writeMu.Unlock()
+			if err != nil {
+				c.fail(err)
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// fail marks the connection as dropped and notifies onClose once
+func (c *conn) fail(err error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.err = fmt.Errorf("%w: %v", ErrConnectionClosed, err)
+	close(c.closed)
+	c.mu.Unlock()
+
+	c.t.abort()
+	if c.onClose != nil {
+		c.onClose(err)
+	}
+}
+
+// close shuts the connection down without triggering onClose
+func (c *conn) close() error {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil
+	}
+	c.err = ErrConnectionClosed
+	close(c.closed)
+	c.mu.Unlock()
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.t.close()
+}
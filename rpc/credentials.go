@@ -0,0 +1,236 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Passwords are never kept in the config file. A profile says where its
+// password comes from instead:
+//
+//	password_encrypted   encrypted with a key derived from a passphrase, which
+//	                     is asked for once per run or read from UTUI_RPC_PASSPHRASE
+//	password_env         the name of an environment variable holding it
+//	password_command     a command printing it, like "pass show irc/rpc"
+//
+// A "password" in plain text, written before these existed, is read into
+// RPCConfig.LegacyPassword. The profiles can not be saved again until it was
+// replaced by one of the above with ConvertLegacyPasswords.
+
+// PassphraseEnv is the environment variable the passphrase of encrypted passwords can be read from
+const PassphraseEnv = "UTUI_RPC_PASSPHRASE"
+
+const (
+	encryptedPasswordPrefix = "v1:" // PBKDF2-SHA256 key, AES-256-GCM
+	pbkdf2Iterations        = 600000
+	passwordSaltSize        = 16
+	passwordCommandTimeout  = 30 * time.Second
+)
+
+// ErrPassphraseNeeded is returned when an encrypted password is needed before its passphrase was given
+var ErrPassphraseNeeded = errors.New("the passphrase of the RPC password is needed")
+
+// ErrWrongPassphrase is returned when a passphrase does not decrypt a password
+var ErrWrongPassphrase = errors.New("wrong passphrase")
+
+// PasswordSource says where the password of a profile comes from, one of the fields is set
+type PasswordSource struct {
+	Encrypted string `json:"password_encrypted,omitempty"`
+	Env       string `json:"password_env,omitempty"`
+	Command   string `json:"password_command,omitempty"`
+}
+
+// DescribePassword returns where the password comes from, for display
+func (p PasswordSource) DescribePassword() string {
+	switch {
+	case p.Encrypted != "":
+		return "encrypted with a passphrase"
+	case p.Env != "":
+		return "environment variable " + p.Env
+	case p.Command != "":
+		return "command " + p.Command
+	}
+	return "none"
+}
+
+var (
+	unlockMu sync.Mutex
+	// unlocked holds the passwords decrypted this run by their encrypted form
+	unlocked = make(map[string]string)
+	// passphrases are the passphrases given this run, tried on other profiles too
+	passphrases []string
+)
+
+// ResolvePassword returns the password of config, from Password when it was
+// just typed in or else from its source
+func (c *RPCConfig) ResolvePassword() (string, error) {
+	if c.Password != "" {
+		return c.Password, nil
+	}
+	switch {
+	case c.Encrypted != "":
+		if PasswordLocked(c) {
+			return "", ErrPassphraseNeeded
+		}
+		unlockMu.Lock()
+		defer unlockMu.Unlock()
+		return unlocked[c.Encrypted], nil
+	case c.Env != "":
+		password := os.Getenv(c.Env)
+		if password == "" {
+			return "", fmt.Errorf("environment variable %s with the RPC password is not set", c.Env)
+		}
+		return password, nil
+	case c.Command != "":
+		return runPasswordCommand(c.Command)
+	}
+	return "", fmt.Errorf("no RPC password configured for profile %s", c.Name)
+}
+
+// runPasswordCommand returns the first line command prints, like pass does
+func runPasswordCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), passwordCommandTimeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("password command failed: %w: %s", err, message)
+		}
+		return "", fmt.Errorf("password command failed: %w", err)
+	}
+	password, _, _ := strings.Cut(string(output), "\n")
+	password = strings.TrimRight(password, "\r")
+	if password == "" {
+		return "", fmt.Errorf("password command printed nothing")
+	}
+	return password, nil
+}
+
+// PasswordLocked reports whether the password of config is encrypted and none
+// of the passphrases known so far decrypts it
+func PasswordLocked(config *RPCConfig) bool {
+	if config.Encrypted == "" || config.Password != "" {
+		return false
+	}
+	unlockMu.Lock()
+	_, ok := unlocked[config.Encrypted]
+	known := append([]string(nil), passphrases...)
+	unlockMu.Unlock()
+	if ok {
+		return false
+	}
+
+	if env := os.Getenv(PassphraseEnv); env != "" {
+		known = append(known, env)
+	}
+	for _, passphrase := range known {
+		if UnlockPassword(config, passphrase) == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// UnlockPassword decrypts the password of config with passphrase and keeps it
+// in memory for the rest of the run
+func UnlockPassword(config *RPCConfig, passphrase string) error {
+	password, err := decryptPassword(config.Encrypted, passphrase)
+	if err != nil {
+		return err
+	}
+	remember(config.Encrypted, password, passphrase)
+	return nil
+}
+
+func remember(encrypted, password, passphrase string) {
+	unlockMu.Lock()
+	defer unlockMu.Unlock()
+	unlocked[encrypted] = password
+	for _, known := range passphrases {
+		if known == passphrase {
+			return
+		}
+	}
+	passphrases = append(passphrases, passphrase)
+}
+
+// EncryptPassword encrypts password with a key derived from passphrase, for PasswordSource.Encrypted
+func EncryptPassword(password, passphrase string) (string, error) {
+	if passphrase == "" {
+		return "", fmt.Errorf("a passphrase is needed to encrypt the password")
+	}
+	salt := make([]byte, passwordSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	gcm, err := passwordCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	data := append(salt, nonce...)
+	data = gcm.Seal(data, nonce, []byte(password), nil)
+	encrypted := encryptedPasswordPrefix + base64.StdEncoding.EncodeToString(data)
+	remember(encrypted, password, passphrase)
+	return encrypted, nil
+}
+
+func decryptPassword(encrypted, passphrase string) (string, error) {
+	encoded, ok := strings.CutPrefix(encrypted, encryptedPasswordPrefix)
+	if !ok {
+		return "", fmt.Errorf("unknown encrypted password format")
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("invalid encrypted password: %w", err)
+	}
+	if len(data) < passwordSaltSize {
+		return "", fmt.Errorf("invalid encrypted password")
+	}
+	gcm, err := passwordCipher(passphrase, data[:passwordSaltSize])
+	if err != nil {
+		return "", err
+	}
+	data = data[passwordSaltSize:]
+	if len(data) < gcm.NonceSize() {
+		return "", fmt.Errorf("invalid encrypted password")
+	}
+	password, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(password), nil
+}
+
+func passwordCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
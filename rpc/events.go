@@ -0,0 +1,238 @@
+package rpc
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EventType tells what happened in an Event
+type EventType int
+
+const (
+	EventLog          EventType = iota // Any log message without a more specific type
+	EventUserConnect                   // A user connected to the network
+	EventUserQuit                      // A user disconnected
+	EventNickChange                    // A user changed nick
+	EventChannelJoin                   // A user joined a channel
+	EventChannelPart                   // A user left a channel (part or kick)
+	EventServerLink                    // A server linked to the network
+	EventServerUnlink                  // A server split off
+	EventBanAdded                      // A server ban (TKL) was added
+	EventBanRemoved                    // A server ban was removed or expired
+	EventTopicChange                   // The topic of a channel was changed
+	EventResync                        // Events were lost, what was built from them must be loaded again
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventLog:
+		return "log"
+	case EventUserConnect:
+		return "user connect"
+	case EventUserQuit:
+		return "user quit"
+	case EventNickChange:
+		return "nick change"
+	case EventChannelJoin:
+		return "channel join"
+	case EventChannelPart:
+		return "channel part"
+	case EventServerLink:
+		return "server link"
+	case EventServerUnlink:
+		return "server unlink"
+	case EventBanAdded:
+		return "ban added"
+	case EventBanRemoved:
+		return "ban removed"
+	case EventTopicChange:
+		return "topic change"
+	case EventResync:
+		return "resync"
+	}
+	return "unknown"
+}
+
+// Event is a log event pushed by the server after log.subscribe
+type Event struct {
+	Type    EventType
+	Time    time.Time
+	Nick    string        // Client the event is about, if any. For kicks the kicked user.
+	IP      string        // IP of that client, if known
+	NewNick string        // New nick, for nick changes
+	Channel string        // Channel joined, left or of which the topic changed
+	Topic   string        // New topic, for topic changes
+	Entry   *FileLogEntry // The full entry, laid out as in ircd.json.log
+}
+
+// parseEvent decodes an event notification, returning nil for anything that is not a log entry
+func parseEvent(msg *rpcMessage) *Event {
+	// Events carry the log entry in params, or in result for older servers
+	data := msg.Params
+	if len(data) == 0 {
+		data = msg.Result
+	}
+	if len(data) == 0 {
+		return nil
+	}
+
+	var entry FileLogEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil
+	}
+	if entry.Subsystem == "" && entry.Msg == "" {
+		return nil
+	}
+	entry.RawJSON = string(data)
+
+	event := &Event{
+		Type:  classifyEvent(entry.Subsystem, entry.EventID),
+		Time:  time.Now(),
+		Entry: &entry,
+	}
+	if t, err := time.Parse(time.RFC3339Nano, entry.Timestamp); err == nil {
+		event.Time = t
+	}
+	if name, ok := entry.Client["name"].(string); ok {
+		event.Nick = name
+	}
+	if ip, ok := entry.Client["ip"].(string); ok {
+		event.IP = ip
+	}
+	if name, ok := entry.Channel["name"].(string); ok {
+		event.Channel = name
+	}
+
+	// Fields only some events have, outside of FileLogEntry
+	var extra struct {
+		NewNick string                 `json:"new_nick"`
+		Topic   string                 `json:"topic"`
+		Victim  map[string]interface{} `json:"victim"`
+	}
+	if err := json.Unmarshal(data, &extra); err == nil {
+		event.NewNick = extra.NewNick
+		event.Topic = extra.Topic
+		if victim, ok := extra.Victim["name"].(string); ok && entry.Subsystem == "kick" {
+			event.Nick = victim
+		}
+	}
+	return event
+}
+
+func classifyEvent(subsystem, eventID string) EventType {
+	switch subsystem {
+	case "connect":
+		if strings.HasSuffix(eventID, "_DISCONNECT") {
+			return EventUserQuit
+		}
+		if strings.HasSuffix(eventID, "_CONNECT") {
+			return EventUserConnect
+		}
+	case "nick":
+		return EventNickChange
+	case "join":
+		return EventChannelJoin
+	case "part", "kick":
+		return EventChannelPart
+	case "topic":
+		return EventTopicChange
+	case "link":
+		if strings.HasPrefix(eventID, "SERVER_LINKED") {
+			return EventServerLink
+		}
+		if strings.Contains(eventID, "DISCONNECT") || strings.Contains(eventID, "SQUIT") {
+			return EventServerUnlink
+		}
+	case "tkl":
+		if eventID == "TKL_ADD" {
+			return EventBanAdded
+		}
+		if eventID == "TKL_DEL" || eventID == "TKL_EXPIRE" {
+			return EventBanRemoved
+		}
+	}
+	return EventLog
+}
+
+var logLevels = map[string]bool{"debug": true, "info": true, "warn": true, "error": true, "fatal": true}
+
+// matchSources reports whether an entry is selected by log.subscribe style sources:
+// "all", a level ("warn"), a subsystem ("connect") or "subsystem.EVENT_ID",
+// each optionally negated with "!"
+func matchSources(sources []string, entry *FileLogEntry) bool {
+	included := true
+	for _, source := range sources {
+		if !strings.HasPrefix(source, "!") {
+			included = false
+			break
+		}
+	}
+	for _, source := range sources {
+		if !strings.HasPrefix(source, "!") && matchSource(source, entry) {
+			included = true
+			break
+		}
+	}
+	if !included {
+		return false
+	}
+	for _, source := range sources {
+		if strings.HasPrefix(source, "!") && matchSource(source[1:], entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func matchSource(source string, entry *FileLogEntry) bool {
+	switch {
+	case source == "all" || source == "*":
+		return true
+	case logLevels[source]:
+		return entry.Level == source
+	case strings.Contains(source, "."):
+		subsystem, eventID, _ := strings.Cut(source, ".")
+		return entry.Subsystem == subsystem && entry.EventID == eventID
+	}
+	return entry.Subsystem == source
+}
+
+// mergeSources combines the sources of several subscribers into one log.subscribe
+// call that selects at least everything each of them wants. Exclusions are kept
+// only if every subscriber has them.
+func mergeSources(sets [][]string) []string {
+	include := make(map[string]bool)
+	exclude := make(map[string]int)
+	for _, sources := range sets {
+		positive := false
+		for _, source := range sources {
+			if strings.HasPrefix(source, "!") {
+				exclude[source]++
+			} else {
+				include[source] = true
+				positive = true
+			}
+		}
+		if !positive {
+			include["all"] = true
+		}
+	}
+
+	var merged []string
+	if include["all"] || include["*"] {
+		merged = append(merged, "all")
+	} else {
+		for source := range include {
+			merged = append(merged, source)
+		}
+	}
+	for source, count := range exclude {
+		if count == len(sets) {
+			merged = append(merged, source)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
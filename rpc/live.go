@@ -0,0 +1,241 @@
+package rpc
+
+import "strings"
+
+// LiveSources are the log sources that change the users and channels on the network
+var LiveSources = []string{"connect", "nick", "join", "part", "kick", "topic"}
+
+// ApplyUserEvent updates users, as returned by GetUsers, for a connect, quit, nick
+// change, join or part event, so the list can be kept current without listing all
+// users again. users may also hold just the user the event is about, or be
+// empty when it is not known, to apply the event to a list kept elsewhere.
+// users is not changed, nor are the users in it: when the event changes
+// anything, a new slice is returned. It reports whether anything changed.
+func ApplyUserEvent(users []UserInfo, event Event) ([]UserInfo, bool) {
+	index := findUser(users, event.Nick)
+	switch event.Type {
+	case EventUserConnect:
+		if index >= 0 || event.Entry == nil || event.Entry.Client == nil {
+			return users, false
+		}
+		user, _ := parseUser(event.Entry.Client)
+		if user.Nick == "" {
+			return users, false
+		}
+		return append(users[:len(users):len(users)], user), true
+	case EventUserQuit:
+		if index < 0 {
+			return users, false
+		}
+		return append(users[:index:index], users[index+1:]...), true
+	}
+	if index < 0 {
+		return users, false
+	}
+	user, changed := applyToUser(users[index], event)
+	if !changed {
+		return users, false
+	}
+	users = append([]UserInfo(nil), users...)
+	users[index] = user
+	return users, true
+}
+
+// applyToUser returns user after a nick change, join or part event of the
+// user, without changing the channels of user
+func applyToUser(user UserInfo, event Event) (UserInfo, bool) {
+	switch event.Type {
+	case EventNickChange:
+		if event.NewNick == "" {
+			return user, false
+		}
+		user.Nick = event.NewNick
+		user.Name = event.NewNick
+		return user, true
+	case EventChannelJoin:
+		if event.Channel == "" || indexFold(user.Channels, event.Channel) >= 0 {
+			return user, false
+		}
+		user.Channels = append(user.Channels[:len(user.Channels):len(user.Channels)], event.Channel)
+		return user, true
+	case EventChannelPart:
+		i := indexFold(user.Channels, event.Channel)
+		if i < 0 {
+			return user, false
+		}
+		user.Channels = append(user.Channels[:i:i], user.Channels[i+1:]...)
+		return user, true
+	}
+	return user, false
+}
+
+// ApplyChannelEvent updates channels, as returned by GetChannels or GetChannelDetails,
+// for a join, part, quit, nick change or topic event. Member lists are only kept
+// for channels that have them. Channels are dropped when the last member leaves,
+// unless they are permanent (+P). channels may also hold just the channels
+// the event changes, see EventChannels. Like ApplyUserEvent it does not change
+// channels, but returns a new slice. It reports whether anything changed.
+func ApplyChannelEvent(channels []ChannelInfo, event Event) ([]ChannelInfo, bool) {
+	index := findChannel(channels, event.Channel)
+	switch event.Type {
+	case EventChannelJoin:
+		if event.Channel == "" {
+			return channels, false
+		}
+		if index < 0 {
+			return append(channels[:len(channels):len(channels)], ChannelInfo{Name: event.Channel, UserCount: 1}), true
+		}
+		return replaceChannel(channels, index, joinChannel(channels[index], event.Nick)), true
+	case EventChannelPart:
+		if index < 0 {
+			return channels, false
+		}
+		return replaceChannel(channels, index, leaveChannel(channels[index], event.Nick)), true
+	case EventUserQuit:
+		// The channels of the user are known from the event, or from the member lists
+		userChannels := EventChannels(event)
+		changed := false
+		for i := len(channels) - 1; i >= 0; i-- {
+			if indexFold(userChannels, channels[i].Name) >= 0 || findMember(channels[i].Members, event.Nick) >= 0 {
+				channels = replaceChannel(channels, i, leaveChannel(channels[i], event.Nick))
+				changed = true
+			}
+		}
+		return channels, changed
+	case EventNickChange:
+		changed := false
+		for i := range channels {
+			if channel, ok := renameMember(channels[i], event.Nick, event.NewNick); ok {
+				channels = replaceChannel(channels, i, &channel)
+				changed = true
+			}
+		}
+		return channels, changed
+	case EventTopicChange:
+		if index < 0 {
+			return channels, false
+		}
+		channel := channels[index]
+		channel.Topic = event.Topic
+		return replaceChannel(channels, index, &channel), true
+	}
+	return channels, false
+}
+
+// EventChannels returns the names of the channels event changes, as far as
+// it tells: quits and nick changes also change the channels with a member
+// list that holds the user.
+func EventChannels(event Event) []string {
+	switch event.Type {
+	case EventChannelJoin, EventChannelPart, EventTopicChange:
+		if event.Channel != "" {
+			return []string{event.Channel}
+		}
+	case EventUserQuit:
+		if event.Entry != nil && event.Entry.Client != nil {
+			user, _ := parseUser(event.Entry.Client)
+			return user.Channels
+		}
+	}
+	return nil
+}
+
+// replaceChannel returns a copy of channels with the channel at index replaced
+// by channel, or removed when channel is nil
+func replaceChannel(channels []ChannelInfo, index int, channel *ChannelInfo) []ChannelInfo {
+	if channel == nil {
+		return append(channels[:index:index], channels[index+1:]...)
+	}
+	channels = append([]ChannelInfo(nil), channels...)
+	channels[index] = *channel
+	return channels
+}
+
+// joinChannel returns channel after nick joined it
+func joinChannel(channel ChannelInfo, nick string) *ChannelInfo {
+	channel.UserCount++
+	if channel.Members != nil {
+		channel.Members = append(channel.Members[:len(channel.Members):len(channel.Members)], ChannelMember{Nick: nick})
+		SortChannelMembers(channel.Members)
+	}
+	return &channel
+}
+
+// leaveChannel returns channel after nick left it, or nil when the channel is gone
+func leaveChannel(channel ChannelInfo, nick string) *ChannelInfo {
+	if m := findMember(channel.Members, nick); m >= 0 {
+		channel.Members = append(channel.Members[:m:m], channel.Members[m+1:]...)
+	}
+	channel.UserCount--
+	if channel.UserCount <= 0 && !isPermanentChannel(channel) {
+		return nil
+	}
+	if channel.UserCount < 0 {
+		channel.UserCount = 0
+	}
+	return &channel
+}
+
+// renameMember returns channel after the member nick changed its nick to newNick
+func renameMember(channel ChannelInfo, nick, newNick string) (ChannelInfo, bool) {
+	m := findMember(channel.Members, nick)
+	if m < 0 || newNick == "" {
+		return channel, false
+	}
+	channel.Members = append([]ChannelMember(nil), channel.Members...)
+	channel.Members[m].Nick = newNick
+	SortChannelMembers(channel.Members)
+	return channel, true
+}
+
+// isPermanentChannel reports whether the channel stays when empty (mode +P)
+func isPermanentChannel(channel ChannelInfo) bool {
+	modes, _, _ := strings.Cut(channel.Modes, " ")
+	return strings.Contains(modes, "P")
+}
+
+func findUser(users []UserInfo, nick string) int {
+	if nick == "" {
+		return -1
+	}
+	for i, user := range users {
+		if strings.EqualFold(user.Nick, nick) {
+			return i
+		}
+	}
+	return -1
+}
+
+func findChannel(channels []ChannelInfo, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, channel := range channels {
+		if strings.EqualFold(channel.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func findMember(members []ChannelMember, nick string) int {
+	if nick == "" {
+		return -1
+	}
+	for i, member := range members {
+		if strings.EqualFold(member.Nick, nick) {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexFold returns the index of s in list ignoring case, or -1
+func indexFold(list []string, s string) int {
+	for i, item := range list {
+		if strings.EqualFold(item, s) {
+			return i
+		}
+	}
+	return -1
+}
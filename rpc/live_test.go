@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApplyUserEvent(t *testing.T) {
+	client := map[string]interface{}{"name": "carol", "details": "carol!c@example.org", "user": map[string]interface{}{"username": "c", "channels": []interface{}{"#a"}}}
+	tests := []struct {
+		name    string
+		event   Event
+		changed bool
+		nicks   []string
+		check   func(users []UserInfo) bool
+	}{
+		{"connect", Event{Type: EventUserConnect, Nick: "carol", Entry: &FileLogEntry{Client: client}}, true, []string{"alice", "bob", "carol"}, nil},
+		{"connect known", Event{Type: EventUserConnect, Nick: "Alice", Entry: &FileLogEntry{Client: client}}, false, []string{"alice", "bob"}, nil},
+		{"quit", Event{Type: EventUserQuit, Nick: "ALICE"}, true, []string{"bob"}, nil},
+		{"quit unknown", Event{Type: EventUserQuit, Nick: "carol"}, false, []string{"alice", "bob"}, nil},
+		{"nick", Event{Type: EventNickChange, Nick: "bob", NewNick: "robert"}, true, []string{"alice", "robert"}, nil},
+		{"join", Event{Type: EventChannelJoin, Nick: "alice", Channel: "#c"}, true, []string{"alice", "bob"},
+			func(users []UserInfo) bool { return reflect.DeepEqual(users[0].Channels, []string{"#a", "#b", "#c"}) }},
+		{"join again", Event{Type: EventChannelJoin, Nick: "alice", Channel: "#A"}, false, []string{"alice", "bob"}, nil},
+		{"part", Event{Type: EventChannelPart, Nick: "alice", Channel: "#A"}, true, []string{"alice", "bob"},
+			func(users []UserInfo) bool { return reflect.DeepEqual(users[0].Channels, []string{"#b"}) }},
+	}
+	for _, tt := range tests {
+		// Spare capacity, so that changes in place would show in the original
+		channels := make([]string, 2, 10)
+		copy(channels, []string{"#a", "#b"})
+		users := make([]UserInfo, 2, 10)
+		users[0] = UserInfo{Name: "alice", Nick: "alice", Channels: channels}
+		users[1] = UserInfo{Name: "bob", Nick: "bob"}
+		original := []UserInfo{users[0], users[1]}
+		original[0].Channels = []string{"#a", "#b"}
+
+		got, changed := ApplyUserEvent(users, tt.event)
+		if changed != tt.changed {
+			t.Errorf("%s: changed = %v, want %v", tt.name, changed, tt.changed)
+		}
+		var nicks []string
+		for _, user := range got {
+			nicks = append(nicks, user.Nick)
+		}
+		if !reflect.DeepEqual(nicks, tt.nicks) {
+			t.Errorf("%s: nicks = %v, want %v", tt.name, nicks, tt.nicks)
+		}
+		if tt.check != nil && !tt.check(got) {
+			t.Errorf("%s: users = %+v", tt.name, got)
+		}
+		if !reflect.DeepEqual(users, original) || !reflect.DeepEqual(channels[:cap(channels)][2:3], []string{""}) {
+			t.Errorf("%s: the users passed in changed: %+v", tt.name, users)
+		}
+	}
+}
+
+func TestApplyChannelEvent(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		changed  bool
+		channels string
+		members  []string // Of #a
+	}{
+		{"join new", Event{Type: EventChannelJoin, Nick: "carol", Channel: "#c"}, true, "#a:2 #b:1 #c:1", []string{"alice", "bob"}},
+		{"join", Event{Type: EventChannelJoin, Nick: "carol", Channel: "#A"}, true, "#a:3 #b:1", []string{"alice", "bob", "carol"}},
+		{"part", Event{Type: EventChannelPart, Nick: "alice", Channel: "#a"}, true, "#a:1 #b:1", []string{"bob"}},
+		{"part last", Event{Type: EventChannelPart, Nick: "bob", Channel: "#b"}, true, "#a:2", []string{"alice", "bob"}},
+		{"quit", Event{Type: EventUserQuit, Nick: "bob"}, true, "#a:1 #b:1", []string{"alice"}},
+		{"nick", Event{Type: EventNickChange, Nick: "alice", NewNick: "zoe"}, true, "#a:2 #b:1", []string{"bob", "zoe"}},
+		{"topic", Event{Type: EventTopicChange, Channel: "#b", Topic: "hi"}, true, "#a:2 #b:1", []string{"alice", "bob"}},
+		{"topic unknown", Event{Type: EventTopicChange, Channel: "#x", Topic: "hi"}, false, "#a:2 #b:1", []string{"alice", "bob"}},
+	}
+	for _, tt := range tests {
+		members := make([]ChannelMember, 2, 10)
+		members[0] = ChannelMember{Nick: "alice"}
+		members[1] = ChannelMember{Nick: "bob"}
+		channels := make([]ChannelInfo, 2, 10)
+		channels[0] = ChannelInfo{Name: "#a", UserCount: 2, Members: members}
+		channels[1] = ChannelInfo{Name: "#b", UserCount: 1}
+		original := []ChannelInfo{channels[0], channels[1]}
+		original[0].Members = []ChannelMember{{Nick: "alice"}, {Nick: "bob"}}
+
+		got, changed := ApplyChannelEvent(channels, tt.event)
+		if changed != tt.changed {
+			t.Errorf("%s: changed = %v, want %v", tt.name, changed, tt.changed)
+		}
+		summary := ""
+		var names []string
+		for i, channel := range got {
+			if i > 0 {
+				summary += " "
+			}
+			summary += channel.Name + ":" + string(rune('0'+channel.UserCount))
+			if channel.Name == "#a" {
+				for _, member := range channel.Members {
+					names = append(names, member.Nick)
+				}
+			}
+		}
+		if summary != tt.channels {
+			t.Errorf("%s: channels = %q, want %q", tt.name, summary, tt.channels)
+		}
+		if !reflect.DeepEqual(names, tt.members) {
+			t.Errorf("%s: members of #a = %v, want %v", tt.name, names, tt.members)
+		}
+		if !reflect.DeepEqual(channels, original) || members[:3][2] != (ChannelMember{}) {
+			t.Errorf("%s: the channels passed in changed: %+v", tt.name, channels)
+		}
+	}
+}
+
+func TestEventChannels(t *testing.T) {
+	client := map[string]interface{}{"name": "carol", "user": map[string]interface{}{"channels": []interface{}{"#a", "#b"}}}
+	tests := []struct {
+		name  string
+		event Event
+		want  []string
+	}{
+		{"join", Event{Type: EventChannelJoin, Nick: "carol", Channel: "#a"}, []string{"#a"}},
+		{"topic", Event{Type: EventTopicChange, Channel: "#b", Topic: "hi"}, []string{"#b"}},
+		{"quit", Event{Type: EventUserQuit, Nick: "carol", Entry: &FileLogEntry{Client: client}}, []string{"#a", "#b"}},
+		{"quit without client", Event{Type: EventUserQuit, Nick: "carol"}, nil},
+		{"nick", Event{Type: EventNickChange, Nick: "carol", NewNick: "dave"}, nil},
+	}
+	for _, tt := range tests {
+		if got := EventChannels(tt.event); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// LogExportFormat is a file format log entries can be exported to
+type LogExportFormat string
+
+const (
+	ExportJSONL LogExportFormat = "jsonl" // The raw JSON lines, as in ircd.json.log
+	ExportCSV   LogExportFormat = "csv"   // The chosen columns, with a header row
+	ExportText  LogExportFormat = "txt"   // One readable line per entry
+)
+
+// LogExportColumns are the columns a CSV export can contain
+var LogExportColumns = []string{"timestamp", "level", "subsystem", "event_id", "log_source", "msg", "nick", "ip"}
+
+// DefaultLogExportColumns are used for a CSV export when no columns are chosen
+var DefaultLogExportColumns = []string{"timestamp", "level", "subsystem", "event_id", "msg"}
+
+// logExportColumn returns the value of a column of an entry
+func logExportColumn(entry *FileLogEntry, column string) string {
+	switch column {
+	case "timestamp":
+		return entry.Timestamp
+	case "level":
+		return entry.Level
+	case "subsystem":
+		return entry.Subsystem
+	case "event_id":
+		return entry.EventID
+	case "log_source":
+		return entry.LogSource
+	case "msg":
+		return entry.Msg
+	case "nick":
+		return entryClientField(entry, "name")
+	case "ip":
+		return entryClientField(entry, "ip")
+	}
+	return ""
+}
+
+// ExportLogEntries writes entries to path in the given format, replacing the
+// file if it exists. columns only applies to CSV.
+func ExportLogEntries(path string, entries []*FileLogEntry, format LogExportFormat, columns []string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteLogEntries(file, entries, format, columns); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// WriteLogEntries writes entries to w in the given format, columns only applies to CSV
+func WriteLogEntries(w io.Writer, entries []*FileLogEntry, format LogExportFormat, columns []string) error {
+	switch format {
+	case ExportJSONL:
+		writer := bufio.NewWriter(w)
+		for _, entry := range entries {
+			line := entry.RawJSON
+			if line == "" {
+				// Entries received over RPC have no raw line, so encode what we have
+				data, err := json.Marshal(entry)
+				if err != nil {
+					return err
+				}
+				line = string(data)
+			}
+			if _, err := writer.WriteString(line + "\n"); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+
+	case ExportCSV:
+		if len(columns) == 0 {
+			columns = DefaultLogExportColumns
+		}
+		for _, column := range columns {
+			if !isLogExportColumn(column) {
+				return fmt.Errorf("unknown column %q, choose from %s", column, strings.Join(LogExportColumns, ", "))
+			}
+		}
+		writer := csv.NewWriter(w)
+		if err := writer.Write(columns); err != nil {
+			return err
+		}
+		record := make([]string, len(columns))
+		for _, entry := range entries {
+			for i, column := range columns {
+				record[i] = logExportColumn(entry, column)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+
+	case ExportText:
+		writer := bufio.NewWriter(w)
+		for _, entry := range entries {
+			line := fmt.Sprintf("%s [%s] %s %s: %s", entry.Timestamp, entry.Level, entry.Subsystem, entry.EventID, entry.Msg)
+			if entry.LogSource != "" {
+				line += fmt.Sprintf(" (%s)", entry.LogSource)
+			}
+			if _, err := writer.WriteString(line + "\n"); err != nil {
+				return err
+			}
+		}
+		return writer.Flush()
+	}
+	return fmt.Errorf("unknown export format %q", format)
+}
+
+func isLogExportColumn(column string) bool {
+	for _, c := range LogExportColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
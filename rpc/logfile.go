@@ -0,0 +1,231 @@
+package rpc
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const followPollInterval = 250 * time.Millisecond
+
+// TailOptions controls how TailLogFile delivers entries
+type TailOptions struct {
+	BufferSize int // Size of the returned channel, 100 if 0
+	History    int // Number of existing entries to send before new ones
+
+	// DropWhenFull drops entries while the reader is behind instead of waiting
+	// for it. Off by default: the lines logged during a flood are the ones
+	// worth reading.
+	DropWhenFull bool
+}
+
+// TailLogFile follows the JSON log file and returns a channel of parsed log
+// entries, starting with the latest existing entries of the selected sources. It keeps
+// following across log rotation and truncation until ctx is cancelled, then
+// closes the channel.
+//
+// Failures after it started are sent on the returned error channel: the
+// history not being readable, after which it only follows, and following
+// stopping, after which the entries channel is closed. The error channel is
+// closed after the entries channel.
+func (r *RPCClient) TailLogFile(ctx context.Context, buildDir string, sources []string, opts TailOptions) (<-chan *FileLogEntry, <-chan error, error) {
+	logFilePath := filepath.Join(buildDir, "logs", "ircd.json.log")
+
+	// Check if log file exists
+	info, err := os.Stat(logFilePath)
+	if os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("log file does not exist: %s", logFilePath)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if opts.BufferSize <= 0 {
+		opts.BufferSize = 100
+	}
+	logChan := make(chan *FileLogEntry, opts.BufferSize)
+	errChan := make(chan error, 2) // At most one of each failure, never blocks
+
+	send := func(entry *FileLogEntry) bool {
+		if opts.DropWhenFull {
+			select {
+			case logChan <- entry:
+			default:
+			}
+			return ctx.Err() == nil
+		}
+		select {
+		case logChan <- entry:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	matchesSources := func(entry *FileLogEntry) bool {
+		for _, source := range sources {
+			if source == "*" || entry.Subsystem == source {
+				return true
+			}
+		}
+		return false
+	}
+
+	go func() {
+		defer close(errChan)
+		defer close(logChan)
+
+		// Follow from the end unless the existing logs are sent first
+		offset := info.Size()
+
+		// First, send the most recent existing logs of the selected sources
+		if opts.History > 0 {
+			page, err := recentLogEntries(logFilePath, sources, opts.History)
+			if err != nil {
+				errChan <- fmt.Errorf("failed to read log history: %w", err)
+			} else {
+				offset = page.IndexedSize
+				for _, entry := range page.Entries {
+					if !send(entry) {
+						return
+					}
+				}
+			}
+		}
+
+		// Now follow the file for new logs, from where the history ended
+		err := followFile(ctx, logFilePath, offset, func(line string) bool {
+			var entry FileLogEntry
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				return true // Skip invalid lines
+			}
+
+			// Store the raw JSON
+			entry.RawJSON = line
+
+			if !matchesSources(&entry) {
+				return true
+			}
+			return send(&entry)
+		})
+		if err != nil {
+			errChan <- fmt.Errorf("stopped following %s: %w", logFilePath, err)
+		}
+	}()
+
+	return logChan, errChan, nil
+}
+
+// recentLogEntries returns the last limit entries of the selected sources through the log index
+func recentLogEntries(logFilePath string, sources []string, limit int) (*LogPage, error) {
+	index, err := GetLogIndex(logFilePath)
+	if err != nil {
+		return nil, err
+	}
+	if err := index.Update(); err != nil {
+		return nil, err
+	}
+
+	query := LogQuery{Subsystems: sources, Limit: limit}
+	for _, source := range sources {
+		if source == "*" {
+			query.Subsystems = nil
+			break
+		}
+	}
+	return index.Query(query)
+}
+
+// followFile calls onLine for every line written to path from offset on, like
+// tail -F: when the file is replaced (log rotation) it continues with the new
+// file from its start, when the file is truncated it starts over at the top.
+// It returns when ctx is done, onLine returns false or reading fails.
+func followFile(ctx context.Context, path string, offset int64, onLine func(line string) bool) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		file.Close()
+	}()
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(file)
+	var partial string // Last line, while it is still being written
+
+	deliver := func(line string) bool {
+		line = strings.TrimSpace(line)
+		return line == "" || onLine(line)
+	}
+
+	for {
+		line, err := reader.ReadString('\n')
+		offset += int64(len(line))
+		if err == nil {
+			if !deliver(partial + line) {
+				return nil
+			}
+			partial = ""
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		partial += line
+
+		// At the end of the file: check whether it was rotated or truncated
+		current, statErr := file.Stat()
+		latest, latestErr := os.Stat(path)
+		switch {
+		case statErr != nil:
+			return statErr
+		case latestErr != nil:
+			// Rotated away and not created again yet, keep waiting
+		case !os.SameFile(current, latest):
+			// Rotated: read what was written to the old file in the meantime, then switch
+			rest, err := io.ReadAll(reader)
+			if err != nil {
+				return err
+			}
+			for _, line := range strings.Split(partial+string(rest), "\n") {
+				if !deliver(line) {
+					return nil
+				}
+			}
+
+			newFile, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			file.Close()
+			file = newFile
+			reader.Reset(file)
+			offset = 0
+			partial = ""
+			continue
+		case latest.Size() < offset:
+			// Truncated: start over at the top
+			if _, err := file.Seek(0, io.SeekStart); err != nil {
+				return err
+			}
+			reader.Reset(file)
+			offset = 0
+			partial = ""
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(followPollInterval):
+		}
+	}
+}
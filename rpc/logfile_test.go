@@ -0,0 +1,104 @@
+package rpc
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestBuildDir returns a build directory with a logs/ircd.json.log holding lines
+func newTestBuildDir(t *testing.T, lines ...string) (string, string) {
+	t.Helper()
+	buildDir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(buildDir, "logs"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	logPath := filepath.Join(buildDir, "logs", "ircd.json.log")
+	writeTestLog(t, logPath, os.O_CREATE|os.O_WRONLY, lines...)
+	return buildDir, logPath
+}
+
+func receiveLogEntry(t *testing.T, logChan <-chan *FileLogEntry) *FileLogEntry {
+	t.Helper()
+	select {
+	case entry, ok := <-logChan:
+		if !ok {
+			t.Fatal("log channel closed early")
+		}
+		return entry
+	case <-time.After(5 * time.Second):
+		t.Fatal("no log entry received")
+	}
+	return nil
+}
+
+func TestTailLogFile(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	buildDir, logPath := newTestBuildDir(t,
+		logIndexLine(0, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(1, "info", "link", "LINK_CONNECTED"),
+		logIndexLine(2, "info", "connect", "LOCAL_CLIENT_DISCONNECT"),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logChan, errChan, err := (&RPCClient{}).TailLogFile(ctx, buildDir, []string{"connect"}, TailOptions{History: 10})
+	if err != nil {
+		t.Fatalf("TailLogFile: %v", err)
+	}
+
+	for _, want := range []string{"entry 0", "entry 2"} {
+		if entry := receiveLogEntry(t, logChan); entry.Msg != want {
+			t.Errorf("history entry = %q, want %q", entry.Msg, want)
+		}
+	}
+	writeTestLog(t, logPath, os.O_APPEND|os.O_WRONLY,
+		logIndexLine(3, "info", "link", "LINK_CONNECTED"),
+		logIndexLine(4, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+	)
+	if entry := receiveLogEntry(t, logChan); entry.Msg != "entry 4" {
+		t.Errorf("followed entry = %q, want entry 4", entry.Msg)
+	}
+
+	cancel()
+	for range logChan {
+	}
+	for err := range errChan {
+		t.Errorf("error after cancelling: %v", err)
+	}
+}
+
+func TestTailLogFileHistoryError(t *testing.T) {
+	// The log index can not be created below a file
+	blocker := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(blocker, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("XDG_CACHE_HOME", blocker)
+	buildDir, logPath := newTestBuildDir(t, logIndexLine(0, "info", "connect", "LOCAL_CLIENT_CONNECT"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	logChan, errChan, err := (&RPCClient{}).TailLogFile(ctx, buildDir, []string{"*"}, TailOptions{History: 10})
+	if err != nil {
+		t.Fatalf("TailLogFile: %v", err)
+	}
+
+	select {
+	case err := <-errChan:
+		if err == nil || !strings.Contains(err.Error(), "log history") {
+			t.Errorf("error = %v, want a log history failure", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("history failure not reported")
+	}
+
+	// Following goes on without the history
+	writeTestLog(t, logPath, os.O_APPEND|os.O_WRONLY, logIndexLine(1, "warn", "link", "LINK_DENIED"))
+	if entry := receiveLogEntry(t, logChan); entry.Msg != "entry 1" {
+		t.Errorf("followed entry = %q, want entry 1", entry.Msg)
+	}
+}
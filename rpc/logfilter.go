@@ -0,0 +1,550 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// LogFilter selects log entries. Empty fields match everything. Subsystems,
+// event IDs, log sources and the client nick accept * and ? wildcards, the
+// client IP also a CIDR range like 192.0.2.0/24.
+type LogFilter struct {
+	Levels     []string `json:"levels,omitempty"`
+	Subsystems []string `json:"subsystems,omitempty"`
+	EventIDs   []string `json:"event_ids,omitempty"`
+	LogSources []string `json:"log_sources,omitempty"`
+	ClientIP   string   `json:"client_ip,omitempty"`
+	ClientNick string   `json:"client_nick,omitempty"`
+	From       string   `json:"from,omitempty"`   // As understood by ParseTimeRange, e.g. "15m" or "2024-05-14 13:00"
+	To         string   `json:"to,omitempty"`     // Same
+	Search     string   `json:"search,omitempty"` // Search expression, syntax below
+}
+
+// CompiledLogFilter is a LogFilter ready for matching
+type CompiledLogFilter struct {
+	filter   LogFilter
+	ipPrefix netip.Prefix
+	from, to time.Time
+	search   searchNode
+}
+
+// Compile checks the filter and prepares it for matching. Relative times are
+// resolved against now, so compile again to move a relative window along.
+func (f LogFilter) Compile(now time.Time) (*CompiledLogFilter, error) {
+	c := &CompiledLogFilter{filter: f}
+
+	if strings.Contains(f.ClientIP, "/") {
+		prefix, err := netip.ParsePrefix(f.ClientIP)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client IP range %q: %w", f.ClientIP, err)
+		}
+		c.ipPrefix = prefix.Masked()
+	}
+
+	var err error
+	if c.from, _, err = ParseTimeRange(f.From, now); err != nil {
+		return nil, fmt.Errorf("from: %w", err)
+	}
+	if _, c.to, err = ParseTimeRange(f.To, now); err != nil {
+		return nil, fmt.Errorf("to: %w", err)
+	}
+
+	if c.search, err = compileSearch(f.Search); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Match reports whether entry passes the filter
+func (c *CompiledLogFilter) Match(entry *FileLogEntry) bool {
+	f := &c.filter
+	if len(f.Levels) > 0 && !matchAny(f.Levels, entry.Level) {
+		return false
+	}
+	if len(f.Subsystems) > 0 && !matchAny(f.Subsystems, entry.Subsystem) {
+		return false
+	}
+	if len(f.EventIDs) > 0 && !matchAny(f.EventIDs, entry.EventID) {
+		return false
+	}
+	if len(f.LogSources) > 0 && !matchAny(f.LogSources, entry.LogSource) {
+		return false
+	}
+
+	if f.ClientNick != "" && !wildcardMatch(f.ClientNick, entryClientField(entry, "name")) {
+		return false
+	}
+	if f.ClientIP != "" {
+		ip := entryClientField(entry, "ip")
+		if c.ipPrefix.IsValid() {
+			addr, err := netip.ParseAddr(ip)
+			if err != nil || !c.ipPrefix.Contains(addr.Unmap()) {
+				return false
+			}
+		} else if !wildcardMatch(f.ClientIP, ip) {
+			return false
+		}
+	}
+
+	if !c.from.IsZero() || !c.to.IsZero() {
+		t, err := time.Parse(time.RFC3339Nano, entry.Timestamp)
+		if err != nil {
+			return false
+		}
+		if (!c.from.IsZero() && t.Before(c.from)) || (!c.to.IsZero() && t.After(c.to)) {
+			return false
+		}
+	}
+
+	return c.search == nil || c.search.match(entry)
+}
+
+func entryClientField(entry *FileLogEntry, key string) string {
+	if value, ok := entry.Client[key].(string); ok {
+		return value
+	}
+	return ""
+}
+
+func matchAny(patterns []string, s string) bool {
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches s against an IRC style mask with * and ?, ignoring case
+func wildcardMatch(pattern, s string) bool {
+	p := []rune(strings.ToLower(pattern))
+	str := []rune(strings.ToLower(s))
+
+	// Iterative matching with backtracking to the last *
+	pi, si := 0, 0
+	starPi, starSi := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && p[pi] == '*':
+			// Checked first, a * in s is matched by it like anything else
+			starPi, starSi = pi, si
+			pi++
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case starPi >= 0:
+			pi = starPi + 1
+			starSi++
+			si = starSi
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
+
+// Search expressions
+//
+// Words must all appear (case insensitive) in the level, subsystem, event ID,
+// message, client nick or IP of an entry. Words can be combined with AND, OR
+// (or |) and NOT (or a leading -), grouped with parentheses and quoted to
+// search for a phrase. /.../ is a regular expression, /.../i ignores case.
+// field:value limits a word or regex to one field: level, subsystem, event,
+// source, msg, nick or ip, and may then be a * and ? mask. Example:
+//
+//	subsystem:connect (ip:/^192\.0\.2\./ OR nick:guest*) -"exceeded"
+
+type searchNode interface {
+	match(entry *FileLogEntry) bool
+}
+
+type searchAnd []searchNode
+type searchOr []searchNode
+type searchNot struct{ node searchNode }
+
+type searchTerm struct {
+	field string         // Empty for any field
+	text  string         // Lower case substring, or wildcard mask for a field
+	re    *regexp.Regexp // Set for /regex/ terms
+}
+
+func (n searchAnd) match(entry *FileLogEntry) bool {
+	for _, node := range n {
+		if !node.match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n searchOr) match(entry *FileLogEntry) bool {
+	for _, node := range n {
+		if node.match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n searchNot) match(entry *FileLogEntry) bool {
+	return !n.node.match(entry)
+}
+
+func (t *searchTerm) match(entry *FileLogEntry) bool {
+	var values []string
+	switch t.field {
+	case "level":
+		values = []string{entry.Level}
+	case "subsystem":
+		values = []string{entry.Subsystem}
+	case "event":
+		values = []string{entry.EventID}
+	case "source":
+		values = []string{entry.LogSource}
+	case "msg":
+		values = []string{entry.Msg}
+	case "nick":
+		values = []string{entryClientField(entry, "name")}
+	case "ip":
+		values = []string{entryClientField(entry, "ip")}
+	default:
+		values = []string{entry.Level, entry.Subsystem, entry.EventID, entry.Msg,
+			entryClientField(entry, "name"), entryClientField(entry, "ip")}
+	}
+
+	for _, value := range values {
+		switch {
+		case t.re != nil:
+			if t.re.MatchString(value) {
+				return true
+			}
+		case t.field != "" && strings.ContainsAny(t.text, "*?"):
+			if wildcardMatch(t.text, value) {
+				return true
+			}
+		default:
+			if strings.Contains(strings.ToLower(value), t.text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var searchFields = map[string]bool{"level": true, "subsystem": true, "event": true, "source": true, "msg": true, "nick": true, "ip": true}
+
+type searchToken struct {
+	kind  byte // 'w' word, 'q' quoted phrase, 'r' regex, or one of ( ) |
+	field string
+	text  string
+	flags string
+}
+
+// compileSearch parses a search expression, nil means everything matches
+func compileSearch(expr string) (searchNode, error) {
+	tokens, err := tokenizeSearch(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &searchParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in search", p.tokens[p.pos].text)
+	}
+	return node, nil
+}
+
+func tokenizeSearch(expr string) ([]searchToken, error) {
+	var tokens []searchToken
+	r := []rune(expr)
+	for i := 0; i < len(r); {
+		c := r[i]
+		switch {
+		case unicode.IsSpace(c):
+			i++
+			continue
+		case c == '(' || c == ')' || c == '|':
+			tokens = append(tokens, searchToken{kind: byte(c), text: string(c)})
+			i++
+			continue
+		case c == '-' && i+1 < len(r) && !unicode.IsSpace(r[i+1]):
+			tokens = append(tokens, searchToken{kind: 'w', text: "NOT"})
+			i++
+			continue
+		}
+
+		// An optional field: prefix, then a word, phrase or regex
+		field := ""
+		j := i
+		for j < len(r) && unicode.IsLetter(r[j]) {
+			j++
+		}
+		if j < len(r) && j > i && r[j] == ':' && searchFields[strings.ToLower(string(r[i:j]))] {
+			field = strings.ToLower(string(r[i:j]))
+			i = j + 1
+		}
+
+		if i < len(r) && (r[i] == '"' || r[i] == '/') {
+			quote := r[i]
+			end := i + 1
+			var text []rune
+			for ; end < len(r) && r[end] != quote; end++ {
+				if r[end] == '\\' && quote == '/' && end+1 < len(r) && r[end+1] == '/' {
+					end++ // \/ is a slash inside a regex
+				}
+				text = append(text, r[end])
+			}
+			if end >= len(r) {
+				return nil, fmt.Errorf("missing closing %c in search", quote)
+			}
+			end++
+			token := searchToken{kind: 'q', field: field, text: string(text)}
+			if quote == '/' {
+				token.kind = 'r'
+				for end < len(r) && unicode.IsLetter(r[end]) {
+					token.flags += string(r[end])
+					end++
+				}
+			}
+			tokens = append(tokens, token)
+			i = end
+			continue
+		}
+
+		end := i
+		for end < len(r) && !unicode.IsSpace(r[end]) && r[end] != '(' && r[end] != ')' && r[end] != '|' {
+			end++
+		}
+		if end == i {
+			return nil, fmt.Errorf("missing value after %s:", field)
+		}
+		tokens = append(tokens, searchToken{kind: 'w', field: field, text: string(r[i:end])})
+		i = end
+	}
+	return tokens, nil
+}
+
+type searchParser struct {
+	tokens []searchToken
+	pos    int
+}
+
+func (p *searchParser) peek() *searchToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *searchParser) isKeyword(t *searchToken, keyword string) bool {
+	return t != nil && t.kind == 'w' && t.field == "" && t.text == keyword
+}
+
+func (p *searchParser) parseOr() (searchNode, error) {
+	var nodes searchOr
+	for {
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+
+		t := p.peek()
+		if t == nil || !(t.kind == '|' || p.isKeyword(t, "OR")) {
+			break
+		}
+		p.pos++
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *searchParser) parseAnd() (searchNode, error) {
+	var nodes searchAnd
+	for {
+		t := p.peek()
+		if t == nil || t.kind == ')' || t.kind == '|' || p.isKeyword(t, "OR") {
+			break
+		}
+		if p.isKeyword(t, "AND") {
+			p.pos++
+			if next := p.peek(); next == nil || next.kind == ')' || next.kind == '|' || p.isKeyword(next, "OR") {
+				return nil, fmt.Errorf("search ends after AND")
+			}
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("empty search term")
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *searchParser) parseUnary() (searchNode, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("search ends after NOT")
+	}
+	if p.isKeyword(t, "NOT") {
+		p.pos++
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return searchNot{node}, nil
+	}
+	if t.kind == '(' {
+		p.pos++
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if t := p.peek(); t == nil || t.kind != ')' {
+			return nil, fmt.Errorf("missing closing ) in search")
+		}
+		p.pos++
+		return node, nil
+	}
+	p.pos++
+
+	term := &searchTerm{field: t.field, text: strings.ToLower(t.text)}
+	if t.kind == 'r' {
+		pattern := t.text
+		if strings.Contains(t.flags, "i") {
+			pattern = "(?i)" + pattern
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex /%s/: %w", t.text, err)
+		}
+		term.re = re
+	}
+	return term, nil
+}
+
+// ParseTimeRange parses a point or period in time as typed by a user, returning
+// its start and end: "2024-05-14" (that day), "2024-05-14 13:00", "today",
+// "yesterday", a weekday for the last one before today, or "15m", "2h", "3d",
+// "1w" ago. Empty input gives zero times.
+func ParseTimeRange(text string, now time.Time) (time.Time, time.Time, error) {
+	original := strings.TrimSpace(text)
+	text = strings.TrimPrefix(strings.ToLower(original), "last ")
+	if text == "" {
+		return time.Time{}, time.Time{}, nil
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(start time.Time) (time.Time, time.Time, error) {
+		return start, start.AddDate(0, 0, 1).Add(-time.Millisecond), nil
+	}
+
+	switch text {
+	case "now":
+		return now, now, nil
+	case "today":
+		return day(today)
+	case "yesterday":
+		return day(today.AddDate(0, 0, -1))
+	}
+
+	// A weekday means the most recent one before today
+	for i := 1; i <= 7; i++ {
+		d := today.AddDate(0, 0, -i)
+		if strings.ToLower(d.Weekday().String()) == text || strings.ToLower(d.Weekday().String()[:3]) == text {
+			return day(d)
+		}
+	}
+
+	// Relative to now: 90m, 2h, 3d, 1w
+	if len(text) > 1 {
+		unit := text[len(text)-1]
+		if n, err := strconv.Atoi(text[:len(text)-1]); err == nil {
+			var ago time.Duration
+			switch unit {
+			case 'm':
+				ago = time.Duration(n) * time.Minute
+			case 'h':
+				ago = time.Duration(n) * time.Hour
+			case 'd':
+				ago = time.Duration(n) * 24 * time.Hour
+			case 'w':
+				ago = time.Duration(n) * 7 * 24 * time.Hour
+			}
+			if ago > 0 {
+				t := now.Add(-ago)
+				return t, t, nil
+			}
+		}
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05"} {
+		if t, err := time.ParseInLocation(layout, original, now.Location()); err == nil {
+			return t, t, nil
+		}
+	}
+	if t, err := time.ParseInLocation("2006-01-02", original, now.Location()); err == nil {
+		return day(t)
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("can not understand %q", original)
+}
+
+const logFilterPresetsKey = "log_filter_presets"
+
+// LoadLogFilterPresets returns the named log filters saved in the config file
+func LoadLogFilterPresets() (map[string]LogFilter, error) {
+	fields, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	presets := make(map[string]LogFilter)
+	if data, ok := fields[logFilterPresetsKey]; ok {
+		if err := json.Unmarshal(data, &presets); err != nil {
+			return nil, fmt.Errorf("invalid log filter presets: %w", err)
+		}
+	}
+	return presets, nil
+}
+
+// SaveLogFilterPresets stores the named log filters in the config file
+func SaveLogFilterPresets(presets map[string]LogFilter) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	fields, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	data, err := json.Marshal(presets)
+	if err != nil {
+		return err
+	}
+	fields[logFilterPresetsKey] = data
+	return writeConfigFile(fields)
+}
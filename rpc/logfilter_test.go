@@ -0,0 +1,145 @@
+package rpc
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWildcardMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "", true},
+		{"", "a", false},
+		{"*", "", true},
+		{"*", "anything", true},
+		{"abc", "abc", true},
+		{"abc", "abd", false},
+		{"abc", "ab", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"ab*", "abcdef", true},
+		{"ab*", "ab", true},
+		{"ab*", "a", false},
+		{"*def", "abcdef", true},
+		{"a*c*e", "abcde", true},
+		{"a*c*e", "abcdf", false},
+		{"*free*", "free money", true},
+		{"**", "x", true},
+
+		// A literal * or ? in the subject is matched like any other character
+		{"*free*", "**free money**", true},
+		{"*a", "*xa", true},
+		{"*a", "*xb", false},
+		{"a*", "a*", true},
+		{"a?", "a?", true},
+		{"a?", "a*", true},
+		{"*?", "*", true},
+		{"?*x", "**x", true},
+		{"*!*@*", "nick!*@host", true},
+
+		// Case is ignored, also outside ASCII
+		{"ABC", "abc", true},
+		{"abc", "ABC", true},
+		{"Guest*", "guest123", true},
+		{"ÄBC*", "äbcd", true},
+	}
+	for _, tt := range tests {
+		if got := wildcardMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestLogFilterMatch(t *testing.T) {
+	entry := &FileLogEntry{
+		Timestamp: "2024-05-14T13:30:00.000Z",
+		Level:     "info",
+		Subsystem: "connect",
+		EventID:   "LOCAL_CLIENT_CONNECT",
+		LogSource: "irc1.example.net",
+		Msg:       "Client connecting: Guest42 (guest@192.0.2.7) [exceeded nothing]",
+		Client:    map[string]interface{}{"name": "Guest42", "ip": "192.0.2.7"},
+	}
+	now := time.Date(2024, 5, 14, 14, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		filter LogFilter
+		want   bool
+	}{
+		{"empty", LogFilter{}, true},
+		{"level", LogFilter{Levels: []string{"warn", "info"}}, true},
+		{"other level", LogFilter{Levels: []string{"error"}}, false},
+		{"subsystem mask", LogFilter{Subsystems: []string{"conn*"}}, true},
+		{"event mask", LogFilter{EventIDs: []string{"*_CONNECT"}}, true},
+		{"source", LogFilter{LogSources: []string{"irc2.*"}}, false},
+		{"nick", LogFilter{ClientNick: "guest*"}, true},
+		{"ip mask", LogFilter{ClientIP: "192.0.2.*"}, true},
+		{"ip range", LogFilter{ClientIP: "192.0.2.0/24"}, true},
+		{"ip outside range", LogFilter{ClientIP: "198.51.100.0/24"}, false},
+		{"relative window", LogFilter{From: "1h"}, true},
+		{"before window", LogFilter{From: "15m"}, false},
+		{"words", LogFilter{Search: "client guest42"}, true},
+		{"missing word", LogFilter{Search: "client quit"}, false},
+		{"or", LogFilter{Search: "quit OR connecting"}, true},
+		{"not", LogFilter{Search: `-"exceeded nothing"`}, false},
+		{"field", LogFilter{Search: "subsystem:connect nick:guest*"}, true},
+		{"field elsewhere", LogFilter{Search: "level:connect"}, false},
+		{"regex", LogFilter{Search: `ip:/^192\.0\.2\./`}, true},
+		{"regex case", LogFilter{Search: `/GUEST\d+/`}, false},
+		{"regex ignore case", LogFilter{Search: `/GUEST\d+/i`}, true},
+		{"grouping", LogFilter{Search: `subsystem:connect (ip:/^10\./ OR nick:guest*) -"exceeded"`}, false},
+	}
+	for _, tt := range tests {
+		compiled, err := tt.filter.Compile(now)
+		if err != nil {
+			t.Errorf("%s: Compile: %v", tt.name, err)
+			continue
+		}
+		if got := compiled.Match(entry); got != tt.want {
+			t.Errorf("%s: Match = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogFilterCompileErrors(t *testing.T) {
+	now := time.Date(2024, 5, 14, 14, 0, 0, 0, time.UTC)
+	for _, filter := range []LogFilter{
+		{ClientIP: "192.0.2.0/33"},
+		{From: "next tuesday-ish"},
+		{Search: "(unclosed"},
+		{Search: "/[/"},
+	} {
+		if _, err := filter.Compile(now); err == nil {
+			t.Errorf("Compile(%+v) succeeded, want an error", filter)
+		}
+	}
+}
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Date(2024, 5, 14, 14, 0, 0, 0, time.UTC)
+	tests := []struct {
+		text       string
+		start, end time.Time
+	}{
+		{"", time.Time{}, time.Time{}},
+		{"15m", now.Add(-15 * time.Minute), now.Add(-15 * time.Minute)},
+		{"last 2h", now.Add(-2 * time.Hour), now.Add(-2 * time.Hour)},
+		{"2024-05-13 09:30", time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC), time.Date(2024, 5, 13, 9, 30, 0, 0, time.UTC)},
+		{"2024-05-13", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Add(-time.Millisecond)},
+		{"yesterday", time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 14, 0, 0, 0, 0, time.UTC).Add(-time.Millisecond)},
+		{"sunday", time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 13, 0, 0, 0, 0, time.UTC).Add(-time.Millisecond)},
+	}
+	for _, tt := range tests {
+		start, end, err := ParseTimeRange(tt.text, now)
+		if err != nil {
+			t.Errorf("ParseTimeRange(%q): %v", tt.text, err)
+			continue
+		}
+		if !start.Equal(tt.start) || !end.Equal(tt.end) {
+			t.Errorf("ParseTimeRange(%q) = %v, %v, want %v, %v", tt.text, start, end, tt.start, tt.end)
+		}
+	}
+}
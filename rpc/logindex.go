@@ -0,0 +1,514 @@
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Index file layout: a fixed header followed by one fixed size record per log
+// line. Strings (levels, subsystems, event IDs) are stored once in a separate
+// dictionary file, one per line, records refer to them by line number.
+const (
+	logIndexMagic      = "UTLIDX02"                  // 02: times clamped to stay ordered
+	logIndexHeaderSize = 8 + 8 + 8 + 8 + sha256.Size // magic, indexed size, records, head length, head hash
+	logIndexRecordSize = 8 + 8 + 4 + 2 + 2 + 2       // offset, time, length, level, subsystem, event id
+	logIndexHeadSize   = 4096                        // Bytes at the start of the log used to recognize it
+	logIndexChunk      = 4096                        // Records read at once when scanning
+	logIndexNoID       = 0xFFFF                      // Dictionary full, matches nothing
+)
+
+// LogQuery selects entries from a LogIndex. Empty fields match everything.
+type LogQuery struct {
+	From, To   time.Time
+	Levels     []string
+	Subsystems []string
+	EventIDs   []string // "LINK_*" matches all event IDs starting with LINK_
+	Before     int64    // Position to page back from, 0 for the newest entries
+	Limit      int
+}
+
+// LogPage is one page of query results
+type LogPage struct {
+	Entries []*FileLogEntry // Oldest first
+	Next    int64           // Before for the next, older page
+	HasMore bool            // Whether an older page may exist
+	Scanned int64           // Index records looked at
+
+	IndexedSize int64 // Bytes of the log the index covered for this query
+}
+
+type logRecord struct {
+	offset    int64
+	time      int64 // Unix milliseconds, never before the record preceding it
+	length    uint32
+	level     uint16
+	subsystem uint16
+	eventID   uint16
+}
+
+// LogIndex is an on-disk index over an ircd.json.log file, so history can be
+// searched and paged without parsing the whole log. It is brought up to date
+// incrementally by Update and rebuilt from scratch when the log was replaced.
+type LogIndex struct {
+	logPath  string
+	idxPath  string
+	dictPath string
+
+	mu          sync.Mutex
+	idx         *os.File
+	dict        []string
+	dictIDs     map[string]uint16
+	count       int64 // Number of records
+	indexedSize int64 // Bytes of the log covered by the index
+	headLen     int64
+	headHash    [sha256.Size]byte
+}
+
+var (
+	logIndexesMu sync.Mutex
+	logIndexes   = make(map[string]*LogIndex)
+)
+
+// GetLogIndex returns the index for logPath, shared by all users in the process.
+// Call Update before querying to include what was logged since.
+func GetLogIndex(logPath string) (*LogIndex, error) {
+	absPath, err := filepath.Abs(logPath)
+	if err != nil {
+		return nil, err
+	}
+
+	logIndexesMu.Lock()
+	defer logIndexesMu.Unlock()
+	if index, ok := logIndexes[absPath]; ok {
+		return index, nil
+	}
+
+	index, err := openLogIndex(absPath)
+	if err != nil {
+		return nil, err
+	}
+	logIndexes[absPath] = index
+	return index, nil
+}
+
+// logIndexDir is where index files are kept, outside of the UnrealIRCd directory
+func logIndexDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "utui", "logindex")
+}
+
+func openLogIndex(logPath string) (*LogIndex, error) {
+	dir := logIndexDir()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create log index directory: %w", err)
+	}
+	sum := sha1.Sum([]byte(logPath))
+	base := filepath.Join(dir, hex.EncodeToString(sum[:8]))
+
+	index := &LogIndex{
+		logPath:  logPath,
+		idxPath:  base + ".idx",
+		dictPath: base + ".dict",
+		dictIDs:  make(map[string]uint16),
+	}
+
+	idx, err := os.OpenFile(index.idxPath, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log index: %w", err)
+	}
+	index.idx = idx
+
+	if err := index.load(); err != nil {
+		// Damaged or from an older version, start over
+		if err := index.reset(); err != nil {
+			idx.Close()
+			return nil, err
+		}
+	}
+	return index, nil
+}
+
+// load reads the header and dictionary of an existing index
+func (x *LogIndex) load() error {
+	header := make([]byte, logIndexHeaderSize)
+	if _, err := x.idx.ReadAt(header, 0); err != nil {
+		return err
+	}
+	if string(header[:8]) != logIndexMagic {
+		return errors.New("not a log index")
+	}
+	x.indexedSize = int64(binary.LittleEndian.Uint64(header[8:]))
+	x.count = int64(binary.LittleEndian.Uint64(header[16:]))
+	x.headLen = int64(binary.LittleEndian.Uint64(header[24:]))
+	copy(x.headHash[:], header[32:])
+
+	// Drop records written after the header was last saved
+	if err := x.idx.Truncate(logIndexHeaderSize + x.count*logIndexRecordSize); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(x.dictPath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(data) > 0 {
+		for _, s := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+			x.dictIDs[s] = uint16(len(x.dict))
+			x.dict = append(x.dict, s)
+		}
+	}
+	return nil
+}
+
+// reset empties the index, mu must be held
+func (x *LogIndex) reset() error {
+	if err := x.idx.Truncate(0); err != nil {
+		return err
+	}
+	if err := os.WriteFile(x.dictPath, nil, 0600); err != nil {
+		return err
+	}
+	x.dict = nil
+	x.dictIDs = make(map[string]uint16)
+	x.count = 0
+	x.indexedSize = 0
+	x.headLen = 0
+	x.headHash = [sha256.Size]byte{}
+	return x.writeHeader()
+}
+
+func (x *LogIndex) writeHeader() error {
+	header := make([]byte, logIndexHeaderSize)
+	copy(header, logIndexMagic)
+	binary.LittleEndian.PutUint64(header[8:], uint64(x.indexedSize))
+	binary.LittleEndian.PutUint64(header[16:], uint64(x.count))
+	binary.LittleEndian.PutUint64(header[24:], uint64(x.headLen))
+	copy(header[32:], x.headHash[:])
+	_, err := x.idx.WriteAt(header, 0)
+	return err
+}
+
+// IndexedSize returns how many bytes of the log the index covers. Following
+// the log from there continues seamlessly after the indexed entries.
+func (x *LogIndex) IndexedSize() int64 {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	return x.indexedSize
+}
+
+// Update indexes the lines appended to the log since the last update. If the
+// log was rotated or truncated in the meantime the index is rebuilt.
+func (x *LogIndex) Update() error {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	logFile, err := os.Open(x.logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	info, err := logFile.Stat()
+	if err != nil {
+		return err
+	}
+
+	same, err := x.sameLog(logFile, info.Size())
+	if err != nil {
+		return err
+	}
+	if !same {
+		if err := x.reset(); err != nil {
+			return err
+		}
+	}
+	if x.headLen < logIndexHeadSize && info.Size() > x.headLen {
+		// Remember more of the start of the log to recognize it by
+		x.headLen = min(info.Size(), logIndexHeadSize)
+		if x.headHash, err = hashHead(logFile, x.headLen); err != nil {
+			return err
+		}
+	}
+
+	if _, err := logFile.Seek(x.indexedSize, io.SeekStart); err != nil {
+		return err
+	}
+	dictFile, err := os.OpenFile(x.dictPath, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	defer dictFile.Close()
+
+	reader := bufio.NewReaderSize(logFile, 1<<20)
+	dictWriter := bufio.NewWriter(dictFile)
+	var records []byte
+	offset := x.indexedSize
+	lastTime := int64(0)
+	if x.count > 0 {
+		if last, err := x.readRecords(x.count-1, 1); err == nil {
+			lastTime = last[0].time
+		}
+	}
+
+	flush := func() error {
+		if err := dictWriter.Flush(); err != nil {
+			return err
+		}
+		if len(records) > 0 {
+			if _, err := x.idx.WriteAt(records, logIndexHeaderSize+x.count*logIndexRecordSize); err != nil {
+				return err
+			}
+			x.count += int64(len(records) / logIndexRecordSize)
+			records = records[:0]
+		}
+		x.indexedSize = offset
+		return x.writeHeader()
+	}
+
+	var fields struct {
+		Timestamp string `json:"timestamp"`
+		Level     string `json:"level"`
+		Subsystem string `json:"subsystem"`
+		EventID   string `json:"event_id"`
+	}
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// EOF, or a line still being written: index it next time
+			break
+		}
+
+		rec := logRecord{offset: offset, length: uint32(len(line))}
+		offset += int64(len(line))
+
+		fields.Timestamp, fields.Level, fields.Subsystem, fields.EventID = "", "", "", ""
+		if err := json.Unmarshal(bytes.TrimSpace(line), &fields); err != nil {
+			continue // Not a log entry
+		}
+
+		// Keep records ordered by time, which the time window search relies on,
+		// when a timestamp can not be parsed or goes back after a clock step
+		rec.time = lastTime
+		if t, err := time.Parse(time.RFC3339Nano, fields.Timestamp); err == nil {
+			rec.time = max(lastTime, t.UnixMilli())
+		}
+		lastTime = rec.time
+		rec.level = x.dictID(fields.Level, dictWriter)
+		rec.subsystem = x.dictID(fields.Subsystem, dictWriter)
+		rec.eventID = x.dictID(fields.EventID, dictWriter)
+		records = appendRecord(records, rec)
+
+		if len(records) >= logIndexChunk*logIndexRecordSize*16 {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return flush()
+}
+
+// sameLog checks whether the log is still the file the index was built from
+func (x *LogIndex) sameLog(logFile *os.File, size int64) (bool, error) {
+	if size < x.indexedSize {
+		return false, nil
+	}
+	if x.headLen == 0 {
+		return true, nil
+	}
+	if size < x.headLen {
+		return false, nil
+	}
+	hash, err := hashHead(logFile, x.headLen)
+	if err != nil {
+		return false, err
+	}
+	return hash == x.headHash, nil
+}
+
+func hashHead(file *os.File, length int64) ([sha256.Size]byte, error) {
+	head := make([]byte, length)
+	if _, err := file.ReadAt(head, 0); err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(head), nil
+}
+
+// dictID returns the dictionary number of s, adding it if new
+func (x *LogIndex) dictID(s string, dictWriter *bufio.Writer) uint16 {
+	if id, ok := x.dictIDs[s]; ok {
+		return id
+	}
+	if len(x.dict) >= logIndexNoID || strings.Contains(s, "\n") {
+		return logIndexNoID
+	}
+	id := uint16(len(x.dict))
+	x.dict = append(x.dict, s)
+	x.dictIDs[s] = id
+	dictWriter.WriteString(s + "\n")
+	return id
+}
+
+func appendRecord(buf []byte, rec logRecord) []byte {
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(rec.offset))
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(rec.time))
+	buf = binary.LittleEndian.AppendUint32(buf, rec.length)
+	buf = binary.LittleEndian.AppendUint16(buf, rec.level)
+	buf = binary.LittleEndian.AppendUint16(buf, rec.subsystem)
+	buf = binary.LittleEndian.AppendUint16(buf, rec.eventID)
+	return buf
+}
+
+// readRecords reads n records starting at record number first
+func (x *LogIndex) readRecords(first, n int64) ([]logRecord, error) {
+	buf := make([]byte, n*logIndexRecordSize)
+	if _, err := x.idx.ReadAt(buf, logIndexHeaderSize+first*logIndexRecordSize); err != nil {
+		return nil, err
+	}
+	records := make([]logRecord, n)
+	for i := range records {
+		b := buf[i*logIndexRecordSize:]
+		records[i] = logRecord{
+			offset:    int64(binary.LittleEndian.Uint64(b)),
+			time:      int64(binary.LittleEndian.Uint64(b[8:])),
+			length:    binary.LittleEndian.Uint32(b[16:]),
+			level:     binary.LittleEndian.Uint16(b[20:]),
+			subsystem: binary.LittleEndian.Uint16(b[22:]),
+			eventID:   binary.LittleEndian.Uint16(b[24:]),
+		}
+	}
+	return records, nil
+}
+
+// searchTime returns the first record number with a time of at least t
+func (x *LogIndex) searchTime(t time.Time) (int64, error) {
+	var searchErr error
+	target := t.UnixMilli()
+	i := sort.Search(int(x.count), func(i int) bool {
+		records, err := x.readRecords(int64(i), 1)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return records[0].time >= target
+	})
+	return int64(i), searchErr
+}
+
+// matcher returns which dictionary entries a query field selects, nil for all
+func (x *LogIndex) matcher(values []string, prefixes bool) []bool {
+	if len(values) == 0 {
+		return nil
+	}
+	match := make([]bool, len(x.dict))
+	for id, s := range x.dict {
+		for _, v := range values {
+			if prefixes && strings.HasSuffix(v, "*") {
+				if strings.HasPrefix(strings.ToUpper(s), strings.ToUpper(strings.TrimSuffix(v, "*"))) {
+					match[id] = true
+				}
+			} else if strings.EqualFold(s, v) {
+				match[id] = true
+			}
+		}
+	}
+	return match
+}
+
+func selected(match []bool, id uint16) bool {
+	return match == nil || (int(id) < len(match) && match[id])
+}
+
+// Query returns the newest entries matching q before q.Before. Pass the
+// returned Next as Before to get the page before that.
+func (x *LogIndex) Query(q LogQuery) (*LogPage, error) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+
+	if q.Limit <= 0 {
+		q.Limit = 1000
+	}
+
+	// Narrow down to the time window, records are ordered by time
+	lo, hi := int64(0), x.count
+	var err error
+	if !q.From.IsZero() {
+		if lo, err = x.searchTime(q.From); err != nil {
+			return nil, err
+		}
+	}
+	if !q.To.IsZero() {
+		if hi, err = x.searchTime(q.To.Add(time.Millisecond)); err != nil {
+			return nil, err
+		}
+	}
+	if q.Before > 0 && q.Before < hi {
+		hi = q.Before
+	}
+
+	levels := x.matcher(q.Levels, false)
+	subsystems := x.matcher(q.Subsystems, false)
+	eventIDs := x.matcher(q.EventIDs, true)
+
+	page := &LogPage{IndexedSize: x.indexedSize}
+	var matches []logRecord
+	pos := hi
+	for pos > lo && len(matches) < q.Limit {
+		n := min(int64(logIndexChunk), pos-lo)
+		records, err := x.readRecords(pos-n, n)
+		if err != nil {
+			return nil, err
+		}
+		page.Scanned += n
+
+		i := len(records) - 1
+		for ; i >= 0 && len(matches) < q.Limit; i-- {
+			rec := records[i]
+			if selected(levels, rec.level) && selected(subsystems, rec.subsystem) && selected(eventIDs, rec.eventID) {
+				matches = append(matches, rec)
+			}
+		}
+		pos = pos - n + int64(i) + 1
+	}
+	page.Next = pos
+	page.HasMore = pos > lo
+
+	// Read the matching lines from the log, oldest first
+	logFile, err := os.Open(x.logPath)
+	if err != nil {
+		return nil, err
+	}
+	defer logFile.Close()
+
+	for i := len(matches) - 1; i >= 0; i-- {
+		rec := matches[i]
+		line := make([]byte, rec.length)
+		if _, err := logFile.ReadAt(line, rec.offset); err != nil {
+			return nil, fmt.Errorf("log changed since it was indexed: %w", err)
+		}
+
+		var entry FileLogEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			continue
+		}
+		entry.RawJSON = string(bytes.TrimSpace(line))
+		page.Entries = append(page.Entries, &entry)
+	}
+	return page, nil
+}
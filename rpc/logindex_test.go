@@ -0,0 +1,247 @@
+package rpc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// logIndexLine returns a log line of the given minute after 2024-05-14 12:00 UTC
+func logIndexLine(minute int, level, subsystem, eventID string) string {
+	timestamp := time.Date(2024, 5, 14, 12, minute, 0, 0, time.UTC).Format("2006-01-02T15:04:05.000Z")
+	return fmt.Sprintf(`{"timestamp":"%s","level":"%s","subsystem":"%s","event_id":"%s","msg":"entry %d"}`+"\n",
+		timestamp, level, subsystem, eventID, minute)
+}
+
+// newTestLogIndex writes lines to a log in a temporary directory and opens an index on it
+func newTestLogIndex(t *testing.T, lines ...string) (*LogIndex, string) {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	logPath := filepath.Join(t.TempDir(), "ircd.json.log")
+	writeTestLog(t, logPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, lines...)
+
+	index, err := openLogIndex(logPath)
+	if err != nil {
+		t.Fatalf("openLogIndex: %v", err)
+	}
+	t.Cleanup(func() { index.idx.Close() })
+	if err := index.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	return index, logPath
+}
+
+func writeTestLog(t *testing.T, logPath string, flag int, lines ...string) {
+	t.Helper()
+	file, err := os.OpenFile(logPath, flag, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	for _, line := range lines {
+		if _, err := file.WriteString(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// pageMessages returns the messages of the entries of page, oldest first
+func pageMessages(page *LogPage) []string {
+	var messages []string
+	for _, entry := range page.Entries {
+		messages = append(messages, entry.Msg)
+	}
+	return messages
+}
+
+func TestLogIndexQuery(t *testing.T) {
+	index, _ := newTestLogIndex(t,
+		logIndexLine(0, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		"not json\n",
+		logIndexLine(1, "warn", "link", "LINK_DENIED"),
+		logIndexLine(2, "info", "link", "LINK_CONNECTED"),
+		logIndexLine(3, "error", "link", "LINK_ERROR"),
+		logIndexLine(4, "info", "connect", "LOCAL_CLIENT_DISCONNECT"),
+	)
+
+	from := time.Date(2024, 5, 14, 12, 1, 0, 0, time.UTC)
+	to := time.Date(2024, 5, 14, 12, 3, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		query LogQuery
+		want  []string
+	}{
+		{"everything", LogQuery{}, []string{"entry 0", "entry 1", "entry 2", "entry 3", "entry 4"}},
+		{"level", LogQuery{Levels: []string{"INFO"}}, []string{"entry 0", "entry 2", "entry 4"}},
+		{"levels", LogQuery{Levels: []string{"warn", "error"}}, []string{"entry 1", "entry 3"}},
+		{"subsystem", LogQuery{Subsystems: []string{"connect"}}, []string{"entry 0", "entry 4"}},
+		{"event prefix", LogQuery{EventIDs: []string{"link_*"}}, []string{"entry 1", "entry 2", "entry 3"}},
+		{"event id", LogQuery{EventIDs: []string{"LINK_ERROR"}}, []string{"entry 3"}},
+		{"event not a prefix", LogQuery{EventIDs: []string{"LINK"}}, nil},
+		{"unknown level", LogQuery{Levels: []string{"debug"}}, nil},
+		{"from", LogQuery{From: from}, []string{"entry 1", "entry 2", "entry 3", "entry 4"}},
+		{"window", LogQuery{From: from, To: to}, []string{"entry 1", "entry 2", "entry 3"}},
+		{"window and level", LogQuery{From: from, To: to, Levels: []string{"info"}}, []string{"entry 2"}},
+		{"newest", LogQuery{Limit: 2}, []string{"entry 3", "entry 4"}},
+	}
+	for _, tt := range tests {
+		page, err := index.Query(tt.query)
+		if err != nil {
+			t.Errorf("%s: Query: %v", tt.name, err)
+			continue
+		}
+		if got := pageMessages(page); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: Query = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogIndexBackwardsTime(t *testing.T) {
+	// The clock was set back by two minutes after entry 5
+	index, _ := newTestLogIndex(t,
+		logIndexLine(3, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(4, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(5, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(1, "warn", "link", "LINK_DENIED"),
+		logIndexLine(2, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(6, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(7, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+	)
+
+	at := func(minute int) time.Time { return time.Date(2024, 5, 14, 12, minute, 0, 0, time.UTC) }
+	tests := []struct {
+		name  string
+		query LogQuery
+		want  []string
+	}{
+		// Entries logged after the step are kept at the time of the entry before them
+		{"from", LogQuery{From: at(5)}, []string{"entry 5", "entry 1", "entry 2", "entry 6", "entry 7"}},
+		{"from after the step", LogQuery{From: at(6)}, []string{"entry 6", "entry 7"}},
+		{"window", LogQuery{From: at(4), To: at(6)}, []string{"entry 4", "entry 5", "entry 1", "entry 2", "entry 6"}},
+		{"to", LogQuery{To: at(4)}, []string{"entry 3", "entry 4"}},
+		{"level", LogQuery{From: at(5), Levels: []string{"warn"}}, []string{"entry 1"}},
+	}
+	for _, tt := range tests {
+		page, err := index.Query(tt.query)
+		if err != nil {
+			t.Errorf("%s: Query: %v", tt.name, err)
+			continue
+		}
+		if got := pageMessages(page); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: Query = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLogIndexPaging(t *testing.T) {
+	var lines []string
+	for minute := 0; minute < 10; minute++ {
+		lines = append(lines, logIndexLine(minute, "info", "connect", "LOCAL_CLIENT_CONNECT"))
+	}
+	index, _ := newTestLogIndex(t, lines...)
+
+	var got []string
+	query := LogQuery{Limit: 4}
+	for pages := 0; ; pages++ {
+		if pages > 3 {
+			t.Fatalf("paging did not end, got %q", got)
+		}
+		page, err := index.Query(query)
+		if err != nil {
+			t.Fatalf("Query: %v", err)
+		}
+		got = append(pageMessages(page), got...)
+		if !page.HasMore {
+			break
+		}
+		query.Before = page.Next
+	}
+	if len(got) != 10 || got[0] != "entry 0" || got[9] != "entry 9" {
+		t.Errorf("pages = %q, want entry 0 to entry 9 in order", got)
+	}
+}
+
+func TestLogIndexUpdate(t *testing.T) {
+	index, logPath := newTestLogIndex(t, logIndexLine(0, "info", "connect", "LOCAL_CLIENT_CONNECT"))
+
+	// A line still being written is left for the next update
+	partial := logIndexLine(1, "warn", "link", "LINK_DENIED")
+	writeTestLog(t, logPath, os.O_APPEND|os.O_WRONLY, partial[:20])
+	if err := index.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if page, _ := index.Query(LogQuery{}); len(page.Entries) != 1 {
+		t.Errorf("partial line indexed, got %q", pageMessages(page))
+	}
+
+	writeTestLog(t, logPath, os.O_APPEND|os.O_WRONLY, partial[20:], logIndexLine(2, "info", "link", "LINK_CONNECTED"))
+	if err := index.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	page, err := index.Query(LogQuery{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := pageMessages(page); fmt.Sprint(got) != fmt.Sprint([]string{"entry 0", "entry 1", "entry 2"}) {
+		t.Errorf("after appending, Query = %q", got)
+	}
+	info, _ := os.Stat(logPath)
+	if index.IndexedSize() != info.Size() {
+		t.Errorf("IndexedSize = %d, want the log size %d", index.IndexedSize(), info.Size())
+	}
+
+	// A new index on the same files continues where this one stopped
+	reopened, err := openLogIndex(logPath)
+	if err != nil {
+		t.Fatalf("openLogIndex: %v", err)
+	}
+	defer reopened.idx.Close()
+	if reopened.IndexedSize() != info.Size() {
+		t.Errorf("reopened IndexedSize = %d, want %d", reopened.IndexedSize(), info.Size())
+	}
+	page, err = reopened.Query(LogQuery{Subsystems: []string{"link"}})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := pageMessages(page); fmt.Sprint(got) != fmt.Sprint([]string{"entry 1", "entry 2"}) {
+		t.Errorf("reopened Query = %q", got)
+	}
+}
+
+func TestLogIndexRotation(t *testing.T) {
+	index, logPath := newTestLogIndex(t,
+		logIndexLine(0, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+		logIndexLine(1, "info", "connect", "LOCAL_CLIENT_CONNECT"),
+	)
+
+	// Replaced by a log of the same length with other content
+	writeTestLog(t, logPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY,
+		logIndexLine(5, "warn", "kill", "KILL_COMMAND"),
+		logIndexLine(6, "warn", "kill", "KILL_COMMAND"),
+	)
+	if err := index.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	page, err := index.Query(LogQuery{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := pageMessages(page); fmt.Sprint(got) != fmt.Sprint([]string{"entry 5", "entry 6"}) {
+		t.Errorf("after replacing the log, Query = %q", got)
+	}
+
+	// Truncated, as after a rotation
+	writeTestLog(t, logPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, logIndexLine(7, "info", "connect", "LOCAL_CLIENT_CONNECT"))
+	if err := index.Update(); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	page, err = index.Query(LogQuery{})
+	if err != nil {
+		t.Fatalf("Query: %v", err)
+	}
+	if got := pageMessages(page); fmt.Sprint(got) != fmt.Sprint([]string{"entry 7"}) {
+		t.Errorf("after truncating the log, Query = %q", got)
+	}
+}
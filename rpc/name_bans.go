@@ -0,0 +1,51 @@
+package rpc
+
+import "fmt"
+
+// GetNameBans returns all name bans (Q-lines), the nick and channel masks
+// reserved from use. They have the same fields as server bans.
+func (r *RPCClient) GetNameBans() ([]ServerBanInfo, error) {
+	result, err := r.query("name_ban.list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get name bans: %w", err)
+	}
+
+	banList, err := resultList(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var bans []ServerBanInfo
+	for _, b := range banList {
+		if banMap, ok := b.(map[string]interface{}); ok {
+			bans = append(bans, parseServerBan(banMap))
+		}
+	}
+	return bans, nil
+}
+
+// AddNameBan reserves a nick or channel mask. duration uses the IRC notation ("1d", "0" for permanent)
+func (r *RPCClient) AddNameBan(name, duration, reason string) error {
+	params := map[string]interface{}{
+		"name":   name,
+		"reason": reason,
+	}
+	if duration != "" {
+		params["duration_string"] = duration
+	}
+	if _, err := r.query("name_ban.add", params); err != nil {
+		return fmt.Errorf("failed to add name ban on %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteNameBan removes a name ban
+func (r *RPCClient) DeleteNameBan(name string) error {
+	params := map[string]interface{}{
+		"name": name,
+	}
+	if _, err := r.query("name_ban.del", params); err != nil {
+		return fmt.Errorf("failed to delete name ban on %s: %w", name, err)
+	}
+	return nil
+}
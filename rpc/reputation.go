@@ -0,0 +1,203 @@
+package rpc
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LowReputation is the score below which users count as unknown, the default
+// minimum-reputation-score of connthrottle
+const LowReputation = 24
+
+// maxReputationSnapshots is how many snapshots are kept per server, a week at one every five minutes
+const maxReputationSnapshots = 2016
+
+// reputationBounds are the lowest scores of the histogram buckets
+var reputationBounds = []int{0, 1, 5, 10, LowReputation, 50, 100, 250, 500, 1000}
+
+// ReputationBucket counts the users with a reputation from Min up to Max, Max is -1 for the last bucket
+type ReputationBucket struct {
+	Label string
+	Min   int
+	Max   int
+	Count int
+}
+
+// ReputationHistogram counts users per reputation range
+func ReputationHistogram(users []UserInfo) []ReputationBucket {
+	buckets := make([]ReputationBucket, len(reputationBounds))
+	for i, min := range reputationBounds {
+		buckets[i] = ReputationBucket{Min: min, Max: -1}
+		switch {
+		case i == len(reputationBounds)-1:
+			buckets[i].Label = fmt.Sprintf("%d+", min)
+		case reputationBounds[i+1]-1 == min:
+			buckets[i].Max = min
+			buckets[i].Label = fmt.Sprintf("%d", min)
+		default:
+			buckets[i].Max = reputationBounds[i+1] - 1
+			buckets[i].Label = fmt.Sprintf("%d-%d", min, buckets[i].Max)
+		}
+	}
+	for _, user := range users {
+		i := sort.Search(len(reputationBounds), func(i int) bool { return reputationBounds[i] > user.Reputation }) - 1
+		if i < 0 {
+			i = 0
+		}
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+// ServerUsers are the users of a server
+type ServerUsers struct {
+	Server string
+	Users  []UserInfo
+}
+
+// LowReputationUsers returns the users with a reputation below LowReputation per
+// server, the servers with most of them first, their users lowest score first
+func LowReputationUsers(users []UserInfo) []ServerUsers {
+	var servers []ServerUsers
+	index := make(map[string]int)
+	for _, user := range users {
+		if user.Reputation >= LowReputation {
+			continue
+		}
+		i, ok := index[user.Servername]
+		if !ok {
+			i = len(servers)
+			index[user.Servername] = i
+			servers = append(servers, ServerUsers{Server: user.Servername})
+		}
+		servers[i].Users = append(servers[i].Users, user)
+	}
+
+	for _, server := range servers {
+		sort.SliceStable(server.Users, func(i, j int) bool {
+			a, b := server.Users[i], server.Users[j]
+			if a.Reputation != b.Reputation {
+				return a.Reputation < b.Reputation
+			}
+			return strings.ToLower(a.Nick) < strings.ToLower(b.Nick)
+		})
+	}
+	sort.SliceStable(servers, func(i, j int) bool {
+		if len(servers[i].Users) != len(servers[j].Users) {
+			return len(servers[i].Users) > len(servers[j].Users)
+		}
+		return servers[i].Server < servers[j].Server
+	})
+	return servers
+}
+
+// ReputationSnapshot records the reputation of the users at one moment, for the trend
+type ReputationSnapshot struct {
+	Time    time.Time `json:"time"`
+	Users   int       `json:"users"`
+	Low     int       `json:"low"` // Users below LowReputation
+	Median  int       `json:"median"`
+	Buckets []int     `json:"buckets"` // Counts of the ReputationHistogram buckets
+}
+
+// TakeReputationSnapshot summarizes the reputation of users at now
+func TakeReputationSnapshot(users []UserInfo, now time.Time) ReputationSnapshot {
+	snapshot := ReputationSnapshot{Time: now, Users: len(users)}
+	for _, bucket := range ReputationHistogram(users) {
+		snapshot.Buckets = append(snapshot.Buckets, bucket.Count)
+	}
+
+	scores := make([]int, 0, len(users))
+	for _, user := range users {
+		scores = append(scores, user.Reputation)
+		if user.Reputation < LowReputation {
+			snapshot.Low++
+		}
+	}
+	if len(scores) > 0 {
+		sort.Ints(scores)
+		snapshot.Median = scores[len(scores)/2]
+	}
+	return snapshot
+}
+
+// reputationSnapshotPath is the file the snapshots of the server at target are
+// kept in, one JSON object per line, next to the log indexes
+func reputationSnapshotPath(target string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	sum := sha1.Sum([]byte(target))
+	return filepath.Join(dir, "utui", "reputation", hex.EncodeToString(sum[:8])+".jsonl")
+}
+
+// LoadReputationSnapshots returns the snapshots saved for the server at target, oldest first
+func LoadReputationSnapshots(target string) ([]ReputationSnapshot, error) {
+	file, err := os.Open(reputationSnapshotPath(target))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open reputation snapshots: %w", err)
+	}
+	defer file.Close()
+
+	var snapshots []ReputationSnapshot
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var snapshot ReputationSnapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snapshot); err != nil {
+			continue // Skip a line cut short by a crash
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read reputation snapshots: %w", err)
+	}
+	return snapshots, nil
+}
+
+// SaveReputationSnapshot adds a snapshot for the server at target, dropping the
+// oldest ones beyond maxReputationSnapshots
+func SaveReputationSnapshot(target string, snapshot ReputationSnapshot) error {
+	path := reputationSnapshotPath(target)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create reputation snapshot directory: %w", err)
+	}
+
+	snapshots, err := LoadReputationSnapshots(target)
+	if err != nil {
+		return err
+	}
+	snapshots = append(snapshots, snapshot)
+	if len(snapshots) > maxReputationSnapshots {
+		snapshots = snapshots[len(snapshots)-maxReputationSnapshots:]
+	}
+
+	// Written next to the old file and renamed, so a crash does not lose the history
+	tmpPath := path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to save reputation snapshot: %w", err)
+	}
+	encoder := json.NewEncoder(file)
+	for _, s := range snapshots {
+		if err := encoder.Encode(s); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to save reputation snapshot: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to save reputation snapshot: %w", err)
+	}
+	return os.Rename(tmpPath, path)
+}
@@ -0,0 +1,107 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+)
+
+// ServerBanTypes lists the server ban types accepted by server_ban.add
+var ServerBanTypes = []string{"gline", "kline", "gzline", "zline", "shun"}
+
+// GetServerBans returns all server bans (G-lines, K-lines, Z-lines, shuns, ...)
+func (r *RPCClient) GetServerBans() ([]ServerBanInfo, error) {
+	result, err := r.query("server_ban.list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server bans: %w", err)
+	}
+
+	banList, err := resultList(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var bans []ServerBanInfo
+	for _, b := range banList {
+		if banMap, ok := b.(map[string]interface{}); ok {
+			bans = append(bans, parseServerBan(banMap))
+		}
+	}
+	return bans, nil
+}
+
+// AddServerBan adds a server ban. duration uses the IRC notation ("1d", "2h30m", "0" for permanent)
+func (r *RPCClient) AddServerBan(banType, name, duration, reason string) error {
+	params := map[string]interface{}{
+		"name":   name,
+		"type":   banType,
+		"reason": reason,
+	}
+	if duration != "" {
+		params["duration_string"] = duration
+	}
+	if _, err := r.query("server_ban.add", params); err != nil {
+		return fmt.Errorf("failed to add %s on %s: %w", banType, name, err)
+	}
+	return nil
+}
+
+// DeleteServerBan removes a server ban
+func (r *RPCClient) DeleteServerBan(banType, name string) error {
+	params := map[string]interface{}{
+		"name": name,
+		"type": banType,
+	}
+	if _, err := r.query("server_ban.del", params); err != nil {
+		return fmt.Errorf("failed to delete %s on %s: %w", banType, name, err)
+	}
+	return nil
+}
+
+func parseServerBan(banMap map[string]interface{}) ServerBanInfo {
+	ban := ServerBanInfo{}
+
+	if name, ok := banMap["name"].(string); ok {
+		ban.Name = name
+	}
+	if banType, ok := banMap["type"].(string); ok {
+		ban.Type = banType
+	}
+	if typeString, ok := banMap["type_string"].(string); ok {
+		ban.TypeString = typeString
+	}
+	if reason, ok := banMap["reason"].(string); ok {
+		ban.Reason = reason
+	}
+	if setBy, ok := banMap["set_by"].(string); ok {
+		ban.Setby = setBy
+	}
+	if setAt, ok := banMap["set_at"].(string); ok {
+		ban.CreatedAt = parseRPCTime(setAt)
+	}
+	if expireAt, ok := banMap["expire_at"].(string); ok {
+		ban.ExpireAt = parseRPCTime(expireAt)
+	}
+	if durationString, ok := banMap["duration_string"].(string); ok {
+		ban.DurationString = durationString
+	}
+	if setInConfig, ok := banMap["set_in_config"].(bool); ok {
+		ban.SetInConfig = setInConfig
+	}
+	if ban.ExpireAt > 0 && ban.CreatedAt > 0 {
+		ban.Duration = ban.ExpireAt - ban.CreatedAt
+	}
+
+	return ban
+}
+
+// parseRPCTime converts the ISO 8601 timestamps used by UnrealIRCd into a unix time, 0 if unset
+func parseRPCTime(value string) int64 {
+	if value == "" || value == "never" {
+		return 0
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"fmt"
+	"time"
+)
+
+// GetServers returns all servers linked to the network
+func (r *RPCClient) GetServers() ([]ServerInfo, error) {
+	result, err := r.query("server.list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get servers: %w", err)
+	}
+
+	serverList, err := resultList(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var servers []ServerInfo
+	for _, s := range serverList {
+		if serverMap, ok := s.(map[string]interface{}); ok {
+			servers = append(servers, parseServer(serverMap))
+		}
+	}
+	return servers, nil
+}
+
+// GetServer returns the details of a single server, or of the server we are connected to if name is empty
+func (r *RPCClient) GetServer(name string) (*ServerInfo, error) {
+	var params map[string]interface{}
+	if name != "" {
+		params = map[string]interface{}{"server": name}
+	}
+
+	result, err := r.query("server.get", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get server %s: %w", name, err)
+	}
+
+	resultMap, ok := result.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected response format: %T", result)
+	}
+	// The server object is wrapped in a "server" key
+	if serverMap, ok := resultMap["server"].(map[string]interface{}); ok {
+		if _, hasName := serverMap["name"]; hasName {
+			resultMap = serverMap
+		}
+	}
+
+	server := parseServer(resultMap)
+	return &server, nil
+}
+
+func parseServer(serverMap map[string]interface{}) ServerInfo {
+	server := ServerInfo{Synced: true} // Only trust an explicit "synced": false
+
+	if name, ok := serverMap["name"].(string); ok {
+		server.Name = name
+	}
+	if id, ok := serverMap["id"].(string); ok {
+		server.ID = id
+	}
+	if hostname, ok := serverMap["hostname"].(string); ok {
+		server.Hostname = hostname
+	}
+	if ip, ok := serverMap["ip"].(string); ok {
+		server.IP = ip
+	}
+	if connectedSince, ok := serverMap["connected_since"].(string); ok {
+		server.ConnectedSince = parseRPCTime(connectedSince)
+	}
+
+	// Server specific fields live under the "server" key
+	if serverData, ok := serverMap["server"].(map[string]interface{}); ok {
+		if info, ok := serverData["info"].(string); ok {
+			server.Info = info
+		}
+		if uplink, ok := serverData["uplink"].(string); ok {
+			server.Uplink = uplink
+		}
+		if numUsers, ok := serverData["num_users"].(float64); ok {
+			server.Users = int(numUsers)
+		}
+		if bootTime, ok := serverData["boot_time"].(string); ok {
+			server.BootTime = parseRPCTime(bootTime)
+		}
+		if synced, ok := serverData["synced"].(bool); ok {
+			server.Synced = synced
+		}
+		if ulined, ok := serverData["ulined"].(bool); ok {
+			server.Ulined = ulined
+		}
+		if features, ok := serverData["features"].(map[string]interface{}); ok {
+			if software, ok := features["software"].(string); ok {
+				server.Software = software
+			}
+			if protocol, ok := features["protocol"].(float64); ok {
+				server.Protocol = int(protocol)
+			}
+		}
+	}
+
+	if tls, ok := serverMap["tls"].(map[string]interface{}); ok {
+		if cipher, ok := tls["cipher"].(string); ok {
+			server.TLSCipher = cipher
+		}
+		if certfp, ok := tls["certfp"].(string); ok {
+			server.TLSCertFP = certfp
+		}
+	}
+
+	if server.BootTime > 0 {
+		server.Uptime = time.Now().Unix() - server.BootTime
+	}
+
+	return server
+}
@@ -0,0 +1,495 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	minReconnectDelay = 1 * time.Second
+	maxReconnectDelay = 30 * time.Second
+	eventBufferSize   = 256 // Events buffered per subscriber, one more slot is kept for an EventResync
+)
+
+// ErrSessionClosed is returned by Session.Client after Close
+var ErrSessionClosed = errors.New("RPC session closed")
+
+// ConnectionState is the state of a Session's connection
+type ConnectionState int
+
+const (
+	StateDisconnected ConnectionState = iota // Not connected yet, or the last connect failed
+	StateConnecting
+	StateConnected
+	StateReconnecting // The connection dropped, retrying with backoff
+	StateClosed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case StateDisconnected:
+		return "disconnected"
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	}
+	return "unknown"
+}
+
+// StateEvent describes a change of connection state
+type StateEvent struct {
+	State   ConnectionState
+	Err     error         // Why we got disconnected, if we did
+	Attempt int           // Reconnect attempt number while reconnecting
+	RetryIn time.Duration // Time until the next reconnect attempt
+	Time    time.Time
+}
+
+// Session owns one long-lived connection to a server, shared by all views.
+// It connects on first use and reconnects with backoff if the connection drops.
+type Session struct {
+	config *RPCConfig
+
+	mu       sync.Mutex
+	client   *RPCClient
+	dialing  chan struct{} // non-nil while a connect is in progress
+	current  StateEvent
+	watchers map[chan StateEvent]struct{}
+	done     chan struct{} // closed by Close, stops the reconnect loop
+
+	subscribers      map[*subscriber]struct{}
+	subscribedTo     []string   // Sources of the last log.subscribe on subscribedClient
+	subscribedClient *RPCClient // Subscriptions are per connection
+	subMu            sync.Mutex // Serializes log.subscribe calls
+	dispatchMu       sync.Mutex // Held while an event is handed to subscribers
+}
+
+type subscriber struct {
+	ctx     context.Context
+	sources []string
+	ch      chan Event
+	behind  bool // Events were dropped since the EventResync queued last, guarded by dispatchMu
+}
+
+func newSession(config *RPCConfig) *Session {
+	return &Session{
+		config:      config,
+		current:     StateEvent{State: StateDisconnected, Time: time.Now()},
+		watchers:    make(map[chan StateEvent]struct{}),
+		done:        make(chan struct{}),
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Client returns the connected client, connecting first if needed.
+// While reconnecting it fails right away instead of blocking the caller.
+func (s *Session) Client() (*RPCClient, error) {
+	s.mu.Lock()
+	if s.current.State == StateClosed {
+		s.mu.Unlock()
+		return nil, ErrSessionClosed
+	}
+	if s.client != nil {
+		client := s.client
+		s.mu.Unlock()
+		return client, nil
+	}
+	if s.current.State == StateReconnecting {
+		err := s.current.Err
+		s.mu.Unlock()
+		return nil, fmt.Errorf("not connected, reconnecting: %w", err)
+	}
+	if s.dialing != nil {
+		// Someone else is connecting already, wait for their result
+		dialing := s.dialing
+		s.mu.Unlock()
+		<-dialing
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.client != nil {
+			return s.client, nil
+		}
+		return nil, s.current.Err
+	}
+
+	dialing := make(chan struct{})
+	s.dialing = dialing
+	s.setState(StateEvent{State: StateConnecting})
+	s.mu.Unlock()
+
+	client, err := newRPCClient(s.config, s.handleDisconnect)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dialing = nil
+	close(dialing)
+
+	if err != nil {
+		s.setState(StateEvent{State: StateDisconnected, Err: err})
+		return nil, err
+	}
+	if s.current.State == StateClosed {
+		client.Close()
+		return nil, ErrSessionClosed
+	}
+	s.attach(client)
+	return client, nil
+}
+
+// State returns the current connection state
+func (s *Session) State() StateEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// Watch returns a channel receiving connection state changes, starting with
+// the current state. Only the latest state is kept if the reader falls behind.
+// Call the returned function to stop watching.
+func (s *Session) Watch() (<-chan StateEvent, func()) {
+	ch := make(chan StateEvent, 1)
+
+	s.mu.Lock()
+	s.watchers[ch] = struct{}{}
+	ch <- s.current
+	s.mu.Unlock()
+
+	stop := func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if _, ok := s.watchers[ch]; ok {
+			delete(s.watchers, ch)
+			close(ch)
+		}
+	}
+	return ch, stop
+}
+
+// Close disconnects and stops reconnecting. The session can not be used afterwards.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.current.State == StateClosed {
+		s.mu.Unlock()
+		return nil
+	}
+	close(s.done)
+	client := s.client
+	s.client = nil
+	s.setState(StateEvent{State: StateClosed})
+	for ch := range s.watchers {
+		delete(s.watchers, ch)
+		close(ch)
+	}
+	s.mu.Unlock()
+
+	if client != nil {
+		return client.Close()
+	}
+	return nil
+}
+
+// setState records and broadcasts a state change, s.mu must be held
+func (s *Session) setState(event StateEvent) {
+	event.Time = time.Now()
+	s.current = event
+	for ch := range s.watchers {
+		// Replace a state the watcher has not read yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- event
+	}
+}
+
+// attach makes client the session's connection, s.mu must be held
+func (s *Session) attach(client *RPCClient) {
+	s.client = client
+	s.setState(StateEvent{State: StateConnected})
+	go s.dispatchEvents(client)
+	if len(s.subscribers) > 0 {
+		// Subscribe the new connection to what the old one was subscribed to.
+		// Events sent while reconnecting are lost.
+		go func() {
+			s.updateSubscription(client)
+			s.dispatch(&Event{Type: EventResync, Time: time.Now()})
+		}()
+	}
+}
+
+func (s *Session) handleDisconnect(client *RPCClient, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client != client || s.current.State == StateClosed {
+		return
+	}
+	s.client = nil
+	s.setState(StateEvent{State: StateReconnecting, Err: err, RetryIn: minReconnectDelay})
+	go s.reconnectLoop(err)
+}
+
+func (s *Session) reconnectLoop(lastErr error) {
+	delay := minReconnectDelay
+	for attempt := 1; ; attempt++ {
+		s.mu.Lock()
+		if s.current.State == StateClosed {
+			s.mu.Unlock()
+			return
+		}
+		s.setState(StateEvent{State: StateReconnecting, Err: lastErr, Attempt: attempt, RetryIn: delay})
+		s.mu.Unlock()
+
+		select {
+		case <-time.After(delay):
+		case <-s.done:
+			return
+		}
+
+		client, err := newRPCClient(s.config, s.handleDisconnect)
+		if err == nil {
+			s.mu.Lock()
+			if s.current.State == StateClosed {
+				s.mu.Unlock()
+				client.Close()
+				return
+			}
+			s.attach(client)
+			s.mu.Unlock()
+			return
+		}
+
+		lastErr = err
+		delay *= 2
+		if delay > maxReconnectDelay {
+			delay = maxReconnectDelay
+		}
+	}
+}
+
+// Subscribe streams the log events selected by sources (as in log.subscribe:
+// "all", a level, a subsystem or "subsystem.EVENT_ID", optionally negated with
+// "!") until ctx is cancelled, which closes the channel. The subscription is
+// renewed after a reconnect. Delivery does not wait for slow readers: a reader
+// that falls eventBufferSize events behind loses events. Lost events, also those
+// sent while disconnected, are followed by an EventResync.
+func (s *Session) Subscribe(ctx context.Context, sources []string) (<-chan Event, error) {
+	client, err := s.Client()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &subscriber{
+		ctx:     ctx,
+		sources: sources,
+		ch:      make(chan Event, eventBufferSize+1),
+	}
+	s.mu.Lock()
+	s.subscribers[sub] = struct{}{}
+	s.mu.Unlock()
+
+	if err := s.updateSubscription(client); err != nil {
+		s.removeSubscriber(sub)
+		return nil, fmt.Errorf("failed to subscribe to %s: %w", strings.Join(sources, ","), err)
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-s.done:
+		}
+		s.removeSubscriber(sub)
+
+		s.mu.Lock()
+		client := s.client
+		s.mu.Unlock()
+		if client != nil {
+			s.updateSubscription(client)
+		}
+	}()
+
+	return sub.ch, nil
+}
+
+func (s *Session) removeSubscriber(sub *subscriber) {
+	s.mu.Lock()
+	delete(s.subscribers, sub)
+	s.mu.Unlock()
+
+	// Wait for an event being dispatched right now before closing the channel
+	s.dispatchMu.Lock()
+	close(sub.ch)
+	s.dispatchMu.Unlock()
+}
+
+// updateSubscription makes client's log.subscribe cover every subscriber,
+// unsubscribing once nobody is left
+func (s *Session) updateSubscription(client *RPCClient) error {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+
+	s.mu.Lock()
+	var sets [][]string
+	for sub := range s.subscribers {
+		sets = append(sets, sub.sources)
+	}
+	var current []string
+	if s.subscribedClient == client {
+		current = s.subscribedTo
+	}
+	s.mu.Unlock()
+
+	var merged []string
+	if len(sets) > 0 {
+		merged = mergeSources(sets)
+	}
+	if strings.Join(merged, ",") == strings.Join(current, ",") {
+		return nil
+	}
+
+	var err error
+	if len(merged) == 0 {
+		err = client.UnsubscribeFromLogs()
+	} else {
+		err = client.SubscribeToLogs(merged)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.subscribedTo = merged
+	s.subscribedClient = client
+	s.mu.Unlock()
+	return nil
+}
+
+// dispatchEvents hands the events of client to the subscribers until the connection goes away
+func (s *Session) dispatchEvents(client *RPCClient) {
+	for {
+		select {
+		case msg := <-client.events:
+			if event := parseEvent(msg); event != nil {
+				s.dispatch(event)
+			}
+			if client.lost.Swap(false) {
+				s.dispatch(&Event{Type: EventResync, Time: time.Now()})
+			}
+		case <-client.conn.closed:
+			return
+		}
+	}
+}
+
+func (s *Session) dispatch(event *Event) {
+	s.dispatchMu.Lock()
+	defer s.dispatchMu.Unlock()
+
+	s.mu.Lock()
+	subscribers := make([]*subscriber, 0, len(s.subscribers))
+	for sub := range s.subscribers {
+		subscribers = append(subscribers, sub)
+	}
+	s.mu.Unlock()
+
+	for _, sub := range subscribers {
+		if event.Type != EventResync && !matchSources(sub.sources, event.Entry) {
+			continue
+		}
+		sub.deliver(*event)
+	}
+}
+
+// deliver queues event without waiting for the subscriber, so that one slow
+// reader does not hold up the others. Once eventBufferSize events are queued,
+// events are dropped and an EventResync takes the slot kept free for it.
+// s.dispatchMu must be held, it makes deliver the only sender.
+func (sub *subscriber) deliver(event Event) {
+	if sub.ctx.Err() != nil {
+		return
+	}
+	if len(sub.ch) < eventBufferSize {
+		sub.behind = false
+		sub.ch <- event
+		return
+	}
+	if !sub.behind {
+		sub.behind = true
+		sub.ch <- Event{Type: EventResync, Time: time.Now()}
+	}
+}
+
+// SessionManager keeps one Session per server
+type SessionManager struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+func NewSessionManager() *SessionManager {
+	return &SessionManager{sessions: make(map[string]*Session)}
+}
+
+// Sessions is the session manager shared by the UI
+var Sessions = NewSessionManager()
+
+// Sessions are kept per profile. A profile whose settings changed must be
+// removed before its new settings are used.
+func sessionKey(config *RPCConfig) string {
+	return config.Name
+}
+
+// Get returns the session for config, creating it if needed. No connection
+// is made until the first call to Client.
+func (m *SessionManager) Get(config *RPCConfig) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := sessionKey(config)
+	session, ok := m.sessions[key]
+	if !ok || session.State().State == StateClosed {
+		session = newSession(config)
+		m.sessions[key] = session
+	}
+	return session
+}
+
+// Peek returns the session for config without creating it, nil if there is none
+func (m *SessionManager) Peek(config *RPCConfig) *Session {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.sessions[sessionKey(config)]
+}
+
+// Remove closes and forgets the session for config, e.g. when its credentials change
+func (m *SessionManager) Remove(config *RPCConfig) {
+	m.mu.Lock()
+	key := sessionKey(config)
+	session, ok := m.sessions[key]
+	delete(m.sessions, key)
+	m.mu.Unlock()
+
+	if ok {
+		session.Close()
+	}
+}
+
+// CloseAll closes every session, to be called on exit
+func (m *SessionManager) CloseAll() {
+	m.mu.Lock()
+	sessions := m.sessions
+	m.sessions = make(map[string]*Session)
+	m.mu.Unlock()
+
+	for _, session := range sessions {
+		session.Close()
+	}
+}
@@ -0,0 +1,121 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// addTestSubscriber subscribes to sources on s without a connection
+func addTestSubscriber(t *testing.T, s *Session, sources ...string) *subscriber {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	sub := &subscriber{ctx: ctx, sources: sources, ch: make(chan Event, eventBufferSize+1)}
+	s.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func testJoinEvent(n int) *Event {
+	return &Event{Type: EventChannelJoin, Nick: "nick", Channel: "#" + string(rune('a'+n%26)), Entry: &FileLogEntry{Subsystem: "join", Level: "info"}}
+}
+
+// drainEvents returns the events queued for sub
+func drainEvents(sub *subscriber) []Event {
+	var events []Event
+	for {
+		select {
+		case event := <-sub.ch:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestDispatchSlowSubscriber(t *testing.T) {
+	s := newSession(&RPCConfig{Name: "test"})
+	slow := addTestSubscriber(t, s, "join")
+	fast := addTestSubscriber(t, s, "all")
+	other := addTestSubscriber(t, s, "nick")
+
+	total := eventBufferSize + 50
+	done := make(chan struct{})
+	received := 0
+	go func() {
+		defer close(done)
+		for i := 0; i < total; i++ {
+			s.dispatch(testJoinEvent(i))
+			received += len(drainEvents(fast))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("dispatch held up by a subscriber that does not read")
+	}
+
+	if received != total {
+		t.Errorf("reading subscriber got %d events, want %d", received, total)
+	}
+	events := drainEvents(slow)
+	if len(events) != eventBufferSize+1 {
+		t.Fatalf("slow subscriber got %d events, want %d", len(events), eventBufferSize+1)
+	}
+	if last := events[len(events)-1]; last.Type != EventResync {
+		t.Errorf("last event of the slow subscriber = %s, want resync", last.Type)
+	}
+	for _, event := range events[:eventBufferSize] {
+		if event.Type != EventChannelJoin {
+			t.Errorf("slow subscriber got a %s before the resync", event.Type)
+			break
+		}
+	}
+	if events := drainEvents(other); len(events) != 0 {
+		t.Errorf("subscriber to other sources got %d events", len(events))
+	}
+
+	// Once it caught up, events are delivered again
+	s.dispatch(testJoinEvent(0))
+	if events := drainEvents(slow); len(events) != 1 || events[0].Type != EventChannelJoin {
+		t.Errorf("after catching up the slow subscriber got %v", events)
+	}
+}
+
+func TestDispatchLostEvents(t *testing.T) {
+	s := newSession(&RPCConfig{Name: "test"})
+	sub := addTestSubscriber(t, s, "join")
+	client := newFakeClient(userGetHandler(0))
+	defer client.Close()
+
+	// More events than the client buffers before they are dispatched
+	data, err := json.Marshal(map[string]string{"subsystem": "join", "event_id": "JOIN", "msg": "joined"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < cap(client.events)+5; i++ {
+		client.handleEvent(&rpcMessage{Params: data})
+	}
+	go s.dispatchEvents(client)
+
+	var events []Event
+	deadline := time.After(5 * time.Second)
+	for len(events) < cap(client.events)+1 {
+		select {
+		case event := <-sub.ch:
+			events = append(events, event)
+		case <-deadline:
+			t.Fatalf("got %d events, want %d", len(events), cap(client.events)+1)
+		}
+	}
+	resyncs := 0
+	for _, event := range events {
+		if event.Type == EventResync {
+			resyncs++
+		}
+	}
+	if resyncs != 1 {
+		t.Errorf("got %d resyncs after events were dropped, want 1", resyncs)
+	}
+}
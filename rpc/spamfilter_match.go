@@ -0,0 +1,527 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/dlclark/regexp2"
+)
+
+// Offline spamfilter matching, for trying out spamfilters before adding them.
+//
+// It follows UnrealIRCd: simple spamfilters are * and ? masks matched against
+// the whole text, regex spamfilters are matched anywhere in the text, both
+// ignoring case, and color and formatting codes are stripped from the text
+// first. Regexes run on regexp2, a backtracking engine that has the PCRE
+// features spamfilters use: lookarounds, backreferences, atomic groups and,
+// translated first, POSIX classes, \Q...\E and (?P<name>...). It is close to
+// PCRE2 but not the same, see SpamfilterRegexDifferences. A match that takes
+// longer than spamfilterMatchTimeout is stopped, so a pattern can not hang the
+// test bench, and reported: PCRE2 backtracks on that text too.
+
+// spamfilterMatchTimeout is how long a regex may take on one sample
+const spamfilterMatchTimeout = 100 * time.Millisecond
+
+// ErrSpamfilterTimeout is returned by Match when a regex took too long on a text
+var ErrSpamfilterTimeout = errors.New("matching took too long, the regex backtracks heavily on this text")
+
+// SpamfilterRegexDifferences lists where testing a regex offline differs from UnrealIRCd
+var SpamfilterRegexDifferences = []string{
+	"possessive quantifiers (a*+), \\K, recursion ((?R), (?1)), branch resets ((?|...)), \\g references, \\h, \\R and (*VERB)s are not supported",
+	"\\w, \\d, \\s and case folding also cover non-ASCII characters",
+}
+
+// CompiledSpamfilter is a spamfilter ready to be matched against sample text
+type CompiledSpamfilter struct {
+	Filter   SpamfilterInfo
+	Warnings []string // Patterns that may backtrack badly in UnrealIRCd
+
+	re *regexp2.Regexp
+}
+
+// CompileSpamfilter prepares a spamfilter for MatchSpamfilter. It fails for
+// regexes using PCRE features regexp2 does not have, see SpamfilterRegexDifferences.
+func CompileSpamfilter(filter SpamfilterInfo) (*CompiledSpamfilter, error) {
+	compiled := &CompiledSpamfilter{Filter: filter}
+	switch filter.MatchType {
+	case "simple":
+	case "regex":
+		pattern, err := translatePCRE(filter.Name)
+		if err != nil {
+			return nil, fmt.Errorf("can not test this regex offline: %w", err)
+		}
+		re, err := regexp2.Compile(pattern, regexp2.IgnoreCase)
+		if err != nil {
+			return nil, fmt.Errorf("can not test this regex offline: %w", err)
+		}
+		re.MatchTimeout = spamfilterMatchTimeout
+		compiled.re = re
+		compiled.Warnings = CheckRegexBacktracking(filter.Name)
+	default:
+		return nil, fmt.Errorf("unknown match type %q", filter.MatchType)
+	}
+	return compiled, nil
+}
+
+// Match tells whether the spamfilter catches text sent as target (a target
+// letter). It returns ErrSpamfilterTimeout when the regex took too long.
+func (c *CompiledSpamfilter) Match(target, text string) (bool, error) {
+	if !strings.Contains(c.Filter.Targets, target) {
+		return false, nil
+	}
+	text = StripControlCodes(text)
+	if c.re != nil {
+		matched, err := c.re.MatchString(text)
+		if err != nil {
+			return false, ErrSpamfilterTimeout
+		}
+		return matched, nil
+	}
+	return wildcardMatch(c.Filter.Name, text), nil
+}
+
+// posixClasses are the ranges of the POSIX classes PCRE allows inside brackets
+var posixClasses = map[string]string{
+	"alpha":  `a-zA-Z`,
+	"digit":  `0-9`,
+	"alnum":  `a-zA-Z0-9`,
+	"upper":  `A-Z`,
+	"lower":  `a-z`,
+	"space":  `\t\n\v\f\r `,
+	"blank":  `\t `,
+	"punct":  `!-/:-@\[-` + "`" + `{-~`,
+	"xdigit": `0-9A-Fa-f`,
+	"word":   `\w`,
+	"cntrl":  `\x00-\x1f\x7f`,
+	"print":  `\x20-\x7e`,
+	"graph":  `\x21-\x7e`,
+	"ascii":  `\x00-\x7f`,
+}
+
+// translatePCRE rewrites the PCRE syntax regexp2 does not know into its own:
+// POSIX classes, \Q...\E quoting and Python style named groups. Possessive
+// quantifiers are reported here, regexp2 rejects other unknown syntax itself.
+func translatePCRE(pattern string) (string, error) {
+	var b strings.Builder
+	quantified := false // The last token was a quantifier
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case c == '\\' && i+1 < len(pattern) && pattern[i+1] == 'Q':
+			literal, rest, _ := strings.Cut(pattern[i+2:], `\E`)
+			b.WriteString(regexp2.Escape(literal))
+			i = len(pattern) - len(rest) - 1
+			quantified = false
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteString(pattern[i : i+2])
+			i++
+			quantified = false
+		case c == '[':
+			end, class, err := translatePCREClass(pattern, i)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(class)
+			i = end
+			quantified = false
+		case c == '+' && quantified:
+			return "", errors.New("possessive quantifiers are not supported")
+		case strings.HasPrefix(pattern[i:], "(?P<"):
+			b.WriteString("(?<")
+			i += 3
+			quantified = false
+		case strings.HasPrefix(pattern[i:], "(?P="):
+			name, _, _ := strings.Cut(pattern[i+4:], ")")
+			b.WriteString(`\k<` + name + `>`)
+			i += 4 + len(name)
+			quantified = false
+		default:
+			b.WriteByte(c)
+			// ? after ( starts a group, after a quantifier it makes it lazy
+			quantified = c == '*' || c == '+' || c == '}' || (c == '?' && i > 0 && pattern[i-1] != '(' && !quantified)
+		}
+	}
+	return b.String(), nil
+}
+
+// translatePCREClass translates the bracket expression starting at pattern[start],
+// returning the index of its closing bracket
+func translatePCREClass(pattern string, start int) (int, string, error) {
+	var b strings.Builder
+	b.WriteByte('[')
+	i := start + 1
+	if i < len(pattern) && pattern[i] == '^' {
+		b.WriteByte('^')
+		i++
+	}
+	if i < len(pattern) && pattern[i] == ']' {
+		// A ] right after the opening bracket is literal
+		b.WriteString(`\]`)
+		i++
+	}
+	for ; i < len(pattern); i++ {
+		switch c := pattern[i]; {
+		case c == ']':
+			b.WriteByte(']')
+			return i, b.String(), nil
+		case c == '\\' && i+1 < len(pattern):
+			b.WriteString(pattern[i : i+2])
+			i++
+		case strings.HasPrefix(pattern[i:], "[:"):
+			name, _, ok := strings.Cut(pattern[i+2:], ":]")
+			ranges, known := posixClasses[name]
+			if !ok || !known {
+				if strings.HasPrefix(name, "^") {
+					return 0, "", fmt.Errorf("negated POSIX class [:%s:] is not supported", name)
+				}
+				// Not a POSIX class, a literal [
+				b.WriteString(`\[`)
+				continue
+			}
+			b.WriteString(ranges)
+			i += len(name) + 3
+		case c == '[':
+			b.WriteString(`\[`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	// Unterminated, leave it to regexp2 to report
+	return len(pattern) - 1, pattern[start:], nil
+}
+
+// StripControlCodes removes IRC color and formatting codes from text
+func StripControlCodes(text string) string {
+	if !strings.ContainsAny(text, "\x02\x03\x04\x0f\x11\x16\x1d\x1e\x1f") {
+		return text
+	}
+	var b strings.Builder
+	r := []rune(text)
+	for i := 0; i < len(r); i++ {
+		switch r[i] {
+		case 0x02, 0x0f, 0x11, 0x16, 0x1d, 0x1e, 0x1f:
+		case 0x03:
+			// \x03 followed by up to two digits, optionally a comma and up to two more
+			i = skipColor(r, i, 2, isDigit)
+		case 0x04:
+			// Hex colors: \x04RRGGBB[,RRGGBB]
+			i = skipColor(r, i, 6, isHexDigit)
+		default:
+			b.WriteRune(r[i])
+		}
+	}
+	return b.String()
+}
+
+// skipColor returns the index of the last rune of the color code starting at i
+func skipColor(r []rune, i, digits int, valid func(rune) bool) int {
+	count := func(from int) int {
+		n := 0
+		for from+n < len(r) && n < digits && valid(r[from+n]) {
+			n++
+		}
+		return n
+	}
+	n := count(i + 1)
+	i += n
+	if n > 0 && i+1 < len(r) && r[i+1] == ',' {
+		if m := count(i + 2); m > 0 {
+			i += 1 + m
+		}
+	}
+	return i
+}
+
+func isDigit(r rune) bool    { return r >= '0' && r <= '9' }
+func isHexDigit(r rune) bool { return isDigit(r) || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F') }
+
+// CheckRegexBacktracking looks for the constructs that make backtracking regex
+// engines like PCRE2 take exponential or polynomial time on a non-matching
+// text: quantifiers inside quantified groups like (a+)+, repeated alternatives
+// that can match the same text like (a|aa)*, and neighbouring quantifiers that
+// compete for the same characters like \s*\s*. It is a heuristic, so a warning
+// is a reason to look again rather than proof. It only looks at patterns Go's
+// regexp/syntax can parse, not at those using lookarounds or backreferences.
+func CheckRegexBacktracking(pattern string) []string {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil // CompileSpamfilter reports this
+	}
+	var warnings []string
+	seen := make(map[string]bool)
+	warn := func(format string, node *syntax.Regexp) {
+		message := fmt.Sprintf(format, node.String())
+		if !seen[message] {
+			seen[message] = true
+			warnings = append(warnings, message)
+		}
+	}
+
+	var walk func(node *syntax.Regexp)
+	walk = func(node *syntax.Regexp) {
+		if isUnboundedRepeat(node) {
+			body := node.Sub[0]
+			switch {
+			case containsUnboundedRepeat(body):
+				warn("nested quantifier in %s can take exponential time", node)
+			case hasOverlappingAlternatives(body) || hasAmbiguousTail(body):
+				warn("alternatives in %s can match the same text, which can take exponential time", node)
+			}
+		}
+		if node.Op == syntax.OpConcat {
+			for i := 0; i+1 < len(node.Sub); i++ {
+				a, b := unwrapCapture(node.Sub[i]), unwrapCapture(node.Sub[i+1])
+				if isUnboundedRepeat(a) && isUnboundedRepeat(b) && firstRunes(a).overlaps(firstRunes(b)) {
+					warn("neighbouring quantifiers in %s compete for the same characters, which can take polynomial time", &syntax.Regexp{Op: syntax.OpConcat, Sub: []*syntax.Regexp{a, b}})
+				}
+			}
+		}
+		for _, sub := range node.Sub {
+			walk(sub)
+		}
+	}
+	walk(re)
+	return warnings
+}
+
+func isUnboundedRepeat(node *syntax.Regexp) bool {
+	switch node.Op {
+	case syntax.OpStar, syntax.OpPlus:
+		return true
+	case syntax.OpRepeat:
+		return node.Max == -1
+	}
+	return false
+}
+
+func containsUnboundedRepeat(node *syntax.Regexp) bool {
+	if isUnboundedRepeat(node) {
+		return true
+	}
+	for _, sub := range node.Sub {
+		if containsUnboundedRepeat(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+func unwrapCapture(node *syntax.Regexp) *syntax.Regexp {
+	for node.Op == syntax.OpCapture {
+		node = node.Sub[0]
+	}
+	return node
+}
+
+// hasOverlappingAlternatives tells whether two branches of an alternation can start with the same character
+func hasOverlappingAlternatives(node *syntax.Regexp) bool {
+	node = unwrapCapture(node)
+	if node.Op == syntax.OpConcat && len(node.Sub) > 0 {
+		return hasOverlappingAlternatives(node.Sub[0])
+	}
+	if node.Op != syntax.OpAlternate {
+		return false
+	}
+	for i := range node.Sub {
+		for j := i + 1; j < len(node.Sub); j++ {
+			if firstRunes(node.Sub[i]).overlaps(firstRunes(node.Sub[j])) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// hasAmbiguousTail tells whether an optional end of node can match what node
+// starts with, so repeating node can split a text in several ways. Go's parser
+// turns (a|aa)* into (aa?)*, this is how that case is caught.
+func hasAmbiguousTail(node *syntax.Regexp) bool {
+	node = unwrapCapture(node)
+	if node.Op != syntax.OpConcat {
+		return false
+	}
+	first := firstRunes(node)
+	for i := len(node.Sub) - 1; i > 0; i-- {
+		sub := node.Sub[i]
+		if !canMatchEmpty(sub) {
+			break
+		}
+		if firstRunes(sub).overlaps(first) {
+			return true
+		}
+	}
+	return false
+}
+
+// runeSet is the set of characters a regex can start with
+type runeSet struct {
+	any    bool
+	ranges []rune // Pairs of lo, hi like syntax.Regexp.Rune of a char class
+}
+
+func (s runeSet) overlaps(other runeSet) bool {
+	if (s.any && (other.any || len(other.ranges) > 0)) || (other.any && len(s.ranges) > 0) {
+		return true
+	}
+	for i := 0; i+1 < len(s.ranges); i += 2 {
+		for j := 0; j+1 < len(other.ranges); j += 2 {
+			if s.ranges[i] <= other.ranges[j+1] && other.ranges[j] <= s.ranges[i+1] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *runeSet) add(other runeSet) {
+	s.any = s.any || other.any
+	s.ranges = append(s.ranges, other.ranges...)
+}
+
+func firstRunes(node *syntax.Regexp) runeSet {
+	var set runeSet
+	switch node.Op {
+	case syntax.OpLiteral:
+		if len(node.Rune) > 0 {
+			r := node.Rune[0]
+			set.ranges = append(set.ranges, r, r)
+			if node.Flags&syntax.FoldCase != 0 {
+				for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+					set.ranges = append(set.ranges, f, f)
+				}
+			}
+		}
+	case syntax.OpCharClass:
+		set.ranges = append(set.ranges, node.Rune...)
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		set.any = true
+	case syntax.OpCapture, syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		set = firstRunes(node.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range node.Sub {
+			set.add(firstRunes(sub))
+		}
+	case syntax.OpConcat:
+		// Elements that can match nothing let the next one start the match too
+		for _, sub := range node.Sub {
+			set.add(firstRunes(sub))
+			if !canMatchEmpty(sub) {
+				break
+			}
+		}
+	}
+	return set
+}
+
+func canMatchEmpty(node *syntax.Regexp) bool {
+	switch node.Op {
+	case syntax.OpStar, syntax.OpQuest, syntax.OpEmptyMatch,
+		syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return true
+	case syntax.OpRepeat:
+		return node.Min == 0 || canMatchEmpty(node.Sub[0])
+	case syntax.OpCapture, syntax.OpPlus:
+		return canMatchEmpty(node.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range node.Sub {
+			if !canMatchEmpty(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		for _, sub := range node.Sub {
+			if canMatchEmpty(sub) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SpamfilterSample is a piece of text to test spamfilters against
+type SpamfilterSample struct {
+	Target string // Target letter, see SpamfilterTargets
+	Text   string
+	Line   int // Line in the corpus, counting from 1
+}
+
+// Names that can be used instead of target letters in a corpus
+var spamfilterTargetNames = map[string]string{
+	"msg": "c", "chanmsg": "c", "privmsg": "p", "notice": "n", "channotice": "N",
+	"part": "P", "quit": "q", "dcc": "d", "away": "a", "topic": "t", "tag": "T", "user": "u",
+}
+
+// ParseSpamfilterCorpus reads one sample per line. A line can start with a
+// target letter or name and a colon, "q: quit reason" or "user: nick!user@host:realname",
+// and lines without one are channel messages. Empty lines and lines starting with # are skipped.
+func ParseSpamfilterCorpus(text string) []SpamfilterSample {
+	var samples []SpamfilterSample
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sample := SpamfilterSample{Target: "c", Text: line, Line: i + 1}
+		if prefix, rest, ok := strings.Cut(line, ":"); ok {
+			prefix = strings.TrimSpace(prefix)
+			if letter, ok := spamfilterTargetNames[strings.ToLower(prefix)]; ok {
+				sample.Target, sample.Text = letter, strings.TrimPrefix(rest, " ")
+			} else if isSpamfilterTarget(prefix) {
+				sample.Target, sample.Text = prefix, strings.TrimPrefix(rest, " ")
+			}
+		}
+		samples = append(samples, sample)
+	}
+	return samples
+}
+
+// ParseSpamfilterPatterns reads one spamfilter per line as "<simple|regex> <targets> <pattern>",
+// the pattern being the rest of the line. Empty lines and lines starting with # are skipped.
+func ParseSpamfilterPatterns(text string) ([]SpamfilterInfo, error) {
+	var filters []SpamfilterInfo
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 3 || strings.TrimSpace(fields[2]) == "" {
+			return nil, fmt.Errorf("line %d: expected <simple|regex> <targets> <pattern>", i+1)
+		}
+		matchType := strings.ToLower(fields[0])
+		if matchType != "simple" && matchType != "regex" {
+			return nil, fmt.Errorf("line %d: match type must be simple or regex, not %q", i+1, fields[0])
+		}
+		for _, letter := range fields[1] {
+			if !isSpamfilterTarget(string(letter)) {
+				return nil, fmt.Errorf("line %d: unknown target %q", i+1, letter)
+			}
+		}
+		filters = append(filters, SpamfilterInfo{
+			Name:      strings.TrimSpace(fields[2]),
+			MatchType: matchType,
+			Targets:   fields[1],
+		})
+	}
+	return filters, nil
+}
+
+func isSpamfilterTarget(letter string) bool {
+	for _, target := range SpamfilterTargets {
+		if target.Letter == letter {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatSpamfilterPattern is the inverse of ParseSpamfilterPatterns for one spamfilter
+func FormatSpamfilterPattern(filter SpamfilterInfo) string {
+	return fmt.Sprintf("%s %s %s", filter.MatchType, filter.Targets, filter.Name)
+}
@@ -0,0 +1,155 @@
+package rpc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpamfilterMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter SpamfilterInfo
+		target string
+		text   string
+		want   bool
+	}{
+		{"simple whole text", SpamfilterInfo{Name: "*free money*", MatchType: "simple", Targets: "cp"}, "c", "get FREE MONEY now", true},
+		{"simple is anchored", SpamfilterInfo{Name: "free money", MatchType: "simple", Targets: "cp"}, "c", "get free money now", false},
+		{"simple literal star", SpamfilterInfo{Name: "*free*", MatchType: "simple", Targets: "cp"}, "c", "**free money**", true},
+		{"simple question mark", SpamfilterInfo{Name: "h?llo", MatchType: "simple", Targets: "p"}, "p", "HALLO", true},
+		{"simple no match", SpamfilterInfo{Name: "*casino*", MatchType: "simple", Targets: "cp"}, "c", "hello there", false},
+		{"regex anywhere", SpamfilterInfo{Name: `fr[e3]{2} m[o0]ney`, MatchType: "regex", Targets: "c"}, "c", "get free money now", true},
+		{"regex ignores case", SpamfilterInfo{Name: `^join #spam`, MatchType: "regex", Targets: "c"}, "c", "JOIN #SPAM please", true},
+		{"regex no match", SpamfilterInfo{Name: `^join #spam`, MatchType: "regex", Targets: "c"}, "c", "please join #spam", false},
+		{"colors stripped", SpamfilterInfo{Name: "*free money*", MatchType: "simple", Targets: "c"}, "c", "get \x0304,01fr\x02ee\x02 \x1fmoney\x0f now", true},
+		{"hex colors stripped", SpamfilterInfo{Name: `free money`, MatchType: "regex", Targets: "c"}, "c", "\x04FF0000free\x04 money", true},
+		{"other target", SpamfilterInfo{Name: "*free money*", MatchType: "simple", Targets: "c"}, "p", "free money", false},
+		{"one of several targets", SpamfilterInfo{Name: "*free money*", MatchType: "simple", Targets: "cpnN"}, "n", "free money", true},
+		{"regex lookbehind", SpamfilterInfo{Name: `(?<=buy )cheap`, MatchType: "regex", Targets: "c"}, "c", "Buy CHEAP watches", true},
+		{"regex negative lookahead", SpamfilterInfo{Name: `^join #(?!help)`, MatchType: "regex", Targets: "c"}, "c", "join #help", false},
+		{"regex backreference", SpamfilterInfo{Name: `(\w)\1{4}`, MatchType: "regex", Targets: "c"}, "c", "heyyyyy", true},
+		{"regex atomic group", SpamfilterInfo{Name: `(?>a+)ab`, MatchType: "regex", Targets: "c"}, "c", "aaab", false},
+		{"regex POSIX class", SpamfilterInfo{Name: `^[[:digit:][:space:]]+$`, MatchType: "regex", Targets: "c"}, "c", "12 34", true},
+		{"regex quoted", SpamfilterInfo{Name: `\Qa.b\E`, MatchType: "regex", Targets: "c"}, "c", "axb", false},
+		{"regex named group", SpamfilterInfo{Name: `(?P<w>spam)(?P=w)`, MatchType: "regex", Targets: "c"}, "c", "SPAMspam", true},
+	}
+	for _, tt := range tests {
+		compiled, err := CompileSpamfilter(tt.filter)
+		if err != nil {
+			t.Errorf("%s: CompileSpamfilter: %v", tt.name, err)
+			continue
+		}
+		got, err := compiled.Match(tt.target, tt.text)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Match(%q, %q) = %v, %v, want %v", tt.name, tt.target, tt.text, got, err, tt.want)
+		}
+	}
+}
+
+func TestCompileSpamfilterErrors(t *testing.T) {
+	for _, filter := range []SpamfilterInfo{
+		{Name: `a*+b`, MatchType: "regex"}, // Possessive quantifiers
+		{Name: `a?+b`, MatchType: "regex"},
+		{Name: `ab\Kc`, MatchType: "regex"},
+		{Name: `(a|(?R))`, MatchType: "regex"},
+		{Name: `[[:^alpha:]]`, MatchType: "regex"},
+		{Name: `(unclosed`, MatchType: "regex"},
+		{Name: "*", MatchType: "posix"},
+	} {
+		if _, err := CompileSpamfilter(filter); err == nil {
+			t.Errorf("CompileSpamfilter(%q, %s) succeeded, want an error", filter.Name, filter.MatchType)
+		}
+	}
+}
+
+func TestTranslatePCRE(t *testing.T) {
+	tests := []struct {
+		pattern, want string
+	}{
+		{`a+?b*?c??`, `a+?b*?c??`},
+		{`(?:a)?(?=b)`, `(?:a)?(?=b)`},
+		{`[[:alpha:]_]`, `[a-zA-Z_]`},
+		{`[^[:digit:]]`, `[^0-9]`},
+		{`[]a]`, `[\]a]`},
+		{`[[x]`, `[\[x]`},
+		{`\Q*.*\E+`, `\*\.\*+`},
+		{`\Qopen`, `open`},
+		{`\++`, `\++`},
+		{`(?P<n>a)(?P=n)`, `(?<n>a)\k<n>`},
+	}
+	for _, tt := range tests {
+		got, err := translatePCRE(tt.pattern)
+		if err != nil || got != tt.want {
+			t.Errorf("translatePCRE(%q) = %q, %v, want %q", tt.pattern, got, err, tt.want)
+		}
+	}
+}
+
+func TestSpamfilterTimeout(t *testing.T) {
+	compiled, err := CompileSpamfilter(SpamfilterInfo{Name: `^(a+)+$`, MatchType: "regex", Targets: "c"})
+	if err != nil {
+		t.Fatalf("CompileSpamfilter: %v", err)
+	}
+	if len(compiled.Warnings) == 0 {
+		t.Error("no backtracking warning for a nested quantifier")
+	}
+	start := time.Now()
+	if _, err := compiled.Match("c", strings.Repeat("a", 40)+"!"); !errors.Is(err, ErrSpamfilterTimeout) {
+		t.Errorf("Match = %v, want ErrSpamfilterTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*spamfilterMatchTimeout {
+		t.Errorf("Match took %s", elapsed)
+	}
+}
+
+func TestStripControlCodes(t *testing.T) {
+	tests := []struct {
+		text, want string
+	}{
+		{"plain text", "plain text"},
+		{"\x02bold\x02 \x1ditalic\x1d \x1funderline\x1f\x0f", "bold italic underline"},
+		{"\x034red\x03 \x0304,12on blue\x03", "red on blue"},
+		{"\x03,5comma stays", ",5comma stays"},
+		{"\x0312345", "345"},
+		{"\x04FF00AA,000000hex", "hex"},
+		{"\x16reverse\x11mono", "reversemono"},
+	}
+	for _, tt := range tests {
+		if got := StripControlCodes(tt.text); got != tt.want {
+			t.Errorf("StripControlCodes(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRegexBacktracking(t *testing.T) {
+	bad := []string{
+		`(a+)+$`,
+		`(.*)*x`,
+		`^(\w+\s?)*$`,
+		`(a|aa)*b`,
+		`\s*\s*$`,
+		`.*.*=.*`,
+	}
+	for _, pattern := range bad {
+		if warnings := CheckRegexBacktracking(pattern); len(warnings) == 0 {
+			t.Errorf("CheckRegexBacktracking(%q) found nothing, want a warning", pattern)
+		}
+	}
+
+	safe := []string{
+		`free money`,
+		`^join #[a-z]+$`,
+		`https?://\S+`,
+		`(ab)+c`,
+		`(a|b)*c`,
+		`\d+\s+\w+`,
+		`[a-z]{2,10}\.com`,
+	}
+	for _, pattern := range safe {
+		if warnings := CheckRegexBacktracking(pattern); len(warnings) != 0 {
+			t.Errorf("CheckRegexBacktracking(%q) = %q, want no warnings", pattern, warnings)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package rpc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SpamfilterMatchTypes lists the match types accepted by spamfilter.add
+var SpamfilterMatchTypes = []string{"simple", "regex"}
+
+// SpamfilterTargets are the spamfilter target letters and what they filter
+var SpamfilterTargets = []struct {
+	Letter      string
+	Description string
+}{
+	{"c", "Channel messages"},
+	{"p", "Private messages"},
+	{"n", "Private notices"},
+	{"N", "Channel notices"},
+	{"P", "Part reasons"},
+	{"q", "Quit reasons"},
+	{"d", "DCC file names"},
+	{"a", "Away messages"},
+	{"t", "Topics"},
+	{"T", "Message tags"},
+	{"u", "User masks (nick!user@host:realname)"},
+}
+
+// SpamfilterActions lists the actions a spamfilter can take on a match
+var SpamfilterActions = []string{"block", "warn", "report", "kill", "tempshun", "shun", "kline", "gline", "zline", "gzline", "dccblock", "viruschan"}
+
+// GetSpamfilters returns all spamfilters, from the configuration file and added at runtime
+func (r *RPCClient) GetSpamfilters() ([]SpamfilterInfo, error) {
+	result, err := r.query("spamfilter.list", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spamfilters: %w", err)
+	}
+
+	filterList, err := resultList(result)
+	if err != nil {
+		return nil, err
+	}
+
+	var filters []SpamfilterInfo
+	for _, f := range filterList {
+		if filterMap, ok := f.(map[string]interface{}); ok {
+			filters = append(filters, parseSpamfilter(filterMap))
+		}
+	}
+	return filters, nil
+}
+
+// AddSpamfilter adds a spamfilter. banDuration uses the IRC notation ("1d", "2h30m") and
+// only matters for actions that place a ban.
+func (r *RPCClient) AddSpamfilter(name, matchType, targets, action, banDuration, reason string) error {
+	params := map[string]interface{}{
+		"name":               name,
+		"match_type":         matchType,
+		"spamfilter_targets": targets,
+		"ban_action":         action,
+		"reason":             reason,
+	}
+	if banDuration != "" {
+		params["ban_duration"] = banDuration
+	}
+	if _, err := r.query("spamfilter.add", params); err != nil {
+		return fmt.Errorf("failed to add spamfilter %s: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteSpamfilter removes a spamfilter. A spamfilter is identified by its
+// pattern together with its match type, targets and action.
+func (r *RPCClient) DeleteSpamfilter(filter SpamfilterInfo) error {
+	params := map[string]interface{}{
+		"name":               filter.Name,
+		"match_type":         filter.MatchType,
+		"spamfilter_targets": filter.Targets,
+		"ban_action":         filter.Action,
+	}
+	if _, err := r.query("spamfilter.del", params); err != nil {
+		return fmt.Errorf("failed to delete spamfilter %s: %w", filter.Name, err)
+	}
+	return nil
+}
+
+func parseSpamfilter(filterMap map[string]interface{}) SpamfilterInfo {
+	filter := SpamfilterInfo{}
+
+	if name, ok := filterMap["name"].(string); ok {
+		filter.Name = name
+	}
+	if matchType, ok := filterMap["match_type"].(string); ok {
+		filter.MatchType = matchType
+	}
+	if targets, ok := filterMap["spamfilter_targets"].(string); ok {
+		filter.Targets = targets
+	}
+	if action, ok := filterMap["ban_action"].(string); ok {
+		filter.Action = action
+	}
+	if banDuration, ok := filterMap["ban_duration"].(float64); ok {
+		filter.BanDuration = int64(banDuration)
+	}
+	if banDurationString, ok := filterMap["ban_duration_string"].(string); ok {
+		filter.BanDurationString = banDurationString
+	}
+	if reason, ok := filterMap["reason"].(string); ok {
+		filter.Reason = reason
+	}
+	if setBy, ok := filterMap["set_by"].(string); ok {
+		filter.Setby = setBy
+	}
+	if setAt, ok := filterMap["set_at"].(string); ok {
+		filter.CreatedAt = parseRPCTime(setAt)
+	}
+	if expireAt, ok := filterMap["expire_at"].(string); ok {
+		filter.ExpireAt = parseRPCTime(expireAt)
+	}
+	if setInConfig, ok := filterMap["set_in_config"].(bool); ok {
+		filter.SetInConfig = setInConfig
+	}
+	if hits, ok := filterMap["hits"].(float64); ok {
+		filter.Hits = int64(hits)
+	}
+	if hitsExcept, ok := filterMap["hits_except"].(float64); ok {
+		filter.HitsExcept = int64(hitsExcept)
+	}
+
+	return filter
+}
+
+// DescribeSpamfilterTargets spells out target letters, "cp" becomes "Channel messages, Private messages"
+func DescribeSpamfilterTargets(targets string) string {
+	var names []string
+	for _, letter := range targets {
+		name := string(letter)
+		for _, target := range SpamfilterTargets {
+			if target.Letter == string(letter) {
+				name = target.Description
+				break
+			}
+		}
+		names = append(names, name)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,318 @@
+package rpc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// TableColumn is a column of the users or channels table
+type TableColumn struct {
+	Name    string // Also the field name in table queries
+	Title   string
+	Numeric bool // Sorted and compared as a number
+}
+
+// UserColumns are the columns the users table can show
+var UserColumns = []TableColumn{
+	{"nick", "Nick", false},
+	{"account", "Account", false},
+	{"ip", "IP", false},
+	{"server", "Server", false},
+	{"reputation", "Rep", true},
+	{"modes", "Modes", false},
+	{"channels", "Chans", true},
+}
+
+// ChannelColumns are the columns the channels table can show
+var ChannelColumns = []TableColumn{
+	{"name", "Name", false},
+	{"users", "Users", true},
+	{"modes", "Modes", false},
+	{"created", "Created", false},
+	{"topic", "Topic", false},
+}
+
+// UserField returns the value of a users table column for user
+func UserField(user UserInfo, column string) string {
+	switch column {
+	case "nick":
+		return user.Nick
+	case "account":
+		if user.Account == "none" {
+			return ""
+		}
+		return user.Account
+	case "ip":
+		return user.IP
+	case "server":
+		return user.Servername
+	case "reputation":
+		return strconv.Itoa(user.Reputation)
+	case "modes":
+		return user.Modes
+	case "channels":
+		return strconv.Itoa(len(user.Channels))
+	}
+	return ""
+}
+
+// ChannelField returns the value of a channels table column for channel
+func ChannelField(channel ChannelInfo, column string) string {
+	switch column {
+	case "name":
+		return channel.Name
+	case "users":
+		return strconv.Itoa(channel.UserCount)
+	case "modes":
+		return channel.Modes
+	case "created":
+		if channel.Created == 0 {
+			return ""
+		}
+		return time.Unix(channel.Created, 0).Format("2006-01-02 15:04")
+	case "topic":
+		return channel.Topic
+	}
+	return ""
+}
+
+// CompareTableValues orders two column values, numerically for numeric columns
+// and otherwise ignoring case. It returns -1, 0 or 1.
+func CompareTableValues(a, b string, numeric bool) int {
+	if numeric {
+		x, errX := strconv.ParseFloat(a, 64)
+		y, errY := strconv.ParseFloat(b, 64)
+		if errX == nil && errY == nil {
+			return compareNumbers(x, y)
+		}
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+func compareNumbers(x, y float64) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// Table queries
+//
+// A query is a list of terms that must all match. A bare word must appear in
+// the first column (nick or channel name). field:value limits it to a column,
+// and may be a * and ? mask, which never matches an empty value: account:*
+// means having an account. field:<10, field:>=10 and field:=value compare,
+// numerically for numeric columns. A leading - negates a term, double quotes
+// keep spaces in a value. Example:
+//
+//	server:hub1 reputation:<10 -account:*
+
+// TableQuery is a parsed table query, nil matches every row
+type TableQuery struct {
+	terms []tableTerm
+}
+
+type tableTerm struct {
+	column  TableColumn
+	op      string // "" for contains or mask, or one of < <= > >= =
+	value   string // Lower case
+	number  float64
+	negated bool
+}
+
+// ParseTableQuery parses a query for a table with columns, the first of which
+// bare words are matched against
+func ParseTableQuery(query string, columns []TableColumn) (*TableQuery, error) {
+	words, err := splitTableQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	q := &TableQuery{}
+	for _, word := range words {
+		term := tableTerm{column: columns[0]}
+		if strings.HasPrefix(word, "-") && len(word) > 1 {
+			term.negated = true
+			word = word[1:]
+		}
+
+		if field, value, ok := strings.Cut(word, ":"); ok && isTableField(field) {
+			column, found := findTableColumn(columns, strings.ToLower(field))
+			if !found {
+				return nil, fmt.Errorf("unknown column %q", field)
+			}
+			term.column = column
+			word = value
+			for _, op := range []string{"<=", ">=", "<", ">", "="} {
+				if strings.HasPrefix(word, op) {
+					term.op = op
+					word = word[len(op):]
+					break
+				}
+			}
+		}
+		if word == "" {
+			return nil, fmt.Errorf("missing value for %s", term.column.Name)
+		}
+		term.value = strings.ToLower(word)
+
+		if term.op != "" && term.op != "=" && term.column.Numeric {
+			term.number, err = strconv.ParseFloat(word, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s needs a number, not %q", term.column.Name, word)
+			}
+		}
+		q.terms = append(q.terms, term)
+	}
+	return q, nil
+}
+
+// Match reports whether a row matches the query. field returns the value of a column of the row.
+func (q *TableQuery) Match(field func(column string) string) bool {
+	if q == nil {
+		return true
+	}
+	for _, term := range q.terms {
+		if term.match(field(term.column.Name)) == term.negated {
+			return false
+		}
+	}
+	return true
+}
+
+func (t tableTerm) match(value string) bool {
+	switch t.op {
+	case "":
+		if strings.ContainsAny(t.value, "*?") {
+			return value != "" && wildcardMatch(t.value, value)
+		}
+		return strings.Contains(strings.ToLower(value), t.value)
+	case "=":
+		return CompareTableValues(value, t.value, t.column.Numeric) == 0
+	}
+
+	var c int
+	if t.column.Numeric {
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return false
+		}
+		c = compareNumbers(n, t.number)
+	} else {
+		c = CompareTableValues(value, t.value, false)
+	}
+	switch t.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+func isTableField(field string) bool {
+	if field == "" {
+		return false
+	}
+	for _, r := range field {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+	return true
+}
+
+func findTableColumn(columns []TableColumn, name string) (TableColumn, bool) {
+	for _, column := range columns {
+		if column.Name == name {
+			return column, true
+		}
+	}
+	return TableColumn{}, false
+}
+
+// splitTableQuery splits a query on spaces, keeping double quoted parts together
+func splitTableQuery(query string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inQuote, hasWord := false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			hasWord = true
+		case unicode.IsSpace(r) && !inQuote:
+			if hasWord {
+				words = append(words, word.String())
+				word.Reset()
+				hasWord = false
+			}
+		default:
+			word.WriteRune(r)
+			hasWord = true
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("missing closing \" in query")
+	}
+	if hasWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+const tableColumnsKey = "table_columns"
+
+// LoadTableColumns returns the columns saved for a table ("users" or "channels")
+// in the config file, or nil when none were saved
+func LoadTableColumns(table string) ([]string, error) {
+	fields, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	var tables map[string][]string
+	if data, ok := fields[tableColumnsKey]; ok {
+		if err := json.Unmarshal(data, &tables); err != nil {
+			return nil, fmt.Errorf("invalid table columns: %w", err)
+		}
+	}
+	return tables[table], nil
+}
+
+// SaveTableColumns stores the columns shown in a table in the config file
+func SaveTableColumns(table string, columns []string) error {
+	configMu.Lock()
+	defer configMu.Unlock()
+	fields, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	if fields == nil {
+		fields = make(map[string]json.RawMessage)
+	}
+	tables := make(map[string][]string)
+	if data, ok := fields[tableColumnsKey]; ok {
+		json.Unmarshal(data, &tables)
+	}
+	tables[table] = columns
+	data, err := json.Marshal(tables)
+	if err != nil {
+		return err
+	}
+	fields[tableColumnsKey] = data
+	return writeConfigFile(fields)
+}
@@ -0,0 +1,121 @@
+package rpc
+
+import "testing"
+
+func TestTableQueryMatch(t *testing.T) {
+	user := UserInfo{
+		Nick:       "Guest42",
+		Account:    "none",
+		IP:         "192.0.2.7",
+		Servername: "hub1.example.net",
+		Reputation: 5,
+		Modes:      "iwx",
+		Channels:   []string{"#help", "#lobby"},
+	}
+	field := func(column string) string { return UserField(user, column) }
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"   ", true},
+		{"guest", true},
+		{"GUEST4", true},
+		{"hub1", false}, // Bare words only look at the first column
+		{"server:hub1", true},
+		{"server:hub2", false},
+		{"server:hub?.*", true},
+		{"ip:192.0.2.*", true},
+		{"account:*", false}, // A mask never matches an empty value
+		{"-account:*", true},
+		{"reputation:<10", true},
+		{"reputation:<=5", true},
+		{"reputation:<5", false},
+		{"reputation:>=5", true},
+		{"reputation:>5", false},
+		{"reputation:=5", true},
+		{"reputation:=5.0", true},
+		{"channels:2", true},
+		{"channels:>2", false},
+		{"nick:=guest42", true},
+		{"nick:=guest", false},
+		{"nick:<h", true},
+		{"NICK:guest", true},
+		{"server:hub1 reputation:<10 -account:*", true},
+		{"server:hub1 -reputation:<10", false},
+		{"-guest", false},
+		{"-", false}, // A lone - is a word, not a negation
+		{`modes:"iwx"`, true},
+	}
+	for _, tt := range tests {
+		query, err := ParseTableQuery(tt.query, UserColumns)
+		if err != nil {
+			t.Errorf("ParseTableQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if got := query.Match(field); got != tt.want {
+			t.Errorf("query %q: Match = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestTableQueryQuotes(t *testing.T) {
+	channel := ChannelInfo{Name: "#help", Topic: "Ask your question here", UserCount: 12}
+	field := func(column string) string { return ChannelField(channel, column) }
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`topic:"your question"`, true},
+		{`topic:"question your"`, false},
+		{`"topic:your question"`, true},
+		{`-topic:"no topic"`, true},
+		{"users:>=10 help", true},
+	}
+	for _, tt := range tests {
+		query, err := ParseTableQuery(tt.query, ChannelColumns)
+		if err != nil {
+			t.Errorf("ParseTableQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if got := query.Match(field); got != tt.want {
+			t.Errorf("query %q: Match = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseTableQueryErrors(t *testing.T) {
+	for _, query := range []string{
+		"country:NL",
+		"reputation:<many",
+		"nick:",
+		"reputation:>=",
+		`topic:"unclosed`,
+	} {
+		if _, err := ParseTableQuery(query, UserColumns); err == nil {
+			t.Errorf("ParseTableQuery(%q) succeeded, want an error", query)
+		}
+	}
+}
+
+func TestCompareTableValues(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		numeric bool
+		want    int
+	}{
+		{"9", "10", true, -1},
+		{"9", "10", false, 1},
+		{"10", "10.0", true, 0},
+		{"abc", "ABD", false, -1},
+		{"Nick", "nick", false, 0},
+		{"n/a", "5", true, 1}, // Not a number, compared as text
+	}
+	for _, tt := range tests {
+		if got := CompareTableValues(tt.a, tt.b, tt.numeric); got != tt.want {
+			t.Errorf("CompareTableValues(%q, %q, %v) = %d, want %d", tt.a, tt.b, tt.numeric, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package rpc
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// How the certificate of the server is verified, the default is the system store
+const (
+	TLSVerifySystem = "system" // Against the system certificate store
+	TLSVerifyCAFile = "ca"     // Against the CA certificates in TLSSettings.CAFile
+	TLSVerifyPin    = "pin"    // By the SPKI fingerprint in TLSSettings.SPKIPin
+)
+
+// TLSSettings say how a profile verifies the certificate of the server
+type TLSSettings struct {
+	Verify  string `json:"tls_verify,omitempty"`
+	CAFile  string `json:"tls_ca_file,omitempty"`
+	SPKIPin string `json:"tls_spki_fingerprint,omitempty"` // As printed by ./unrealircd spkifp
+}
+
+// DescribeTLS returns how the certificate of the server is verified, for display
+func (t TLSSettings) DescribeTLS() string {
+	switch t.Verify {
+	case TLSVerifyCAFile:
+		return "against CA file " + t.CAFile
+	case TLSVerifyPin:
+		return "pinned to SPKI fingerprint " + t.SPKIPin
+	}
+	return "against the system certificate store"
+}
+
+// UntrustedCertError is returned when the certificate of the server does not
+// verify, with its fingerprint so it can be pinned after comparing it with
+// the output of ./unrealircd spkifp
+type UntrustedCertError struct {
+	Fingerprint string
+	Err         error
+}
+
+func (e *UntrustedCertError) Error() string {
+	return fmt.Sprintf("certificate of the RPC server is not trusted (SPKI fingerprint %s): %v", e.Fingerprint, e.Err)
+}
+
+func (e *UntrustedCertError) Unwrap() error {
+	return e.Err
+}
+
+// SPKIFingerprint returns the base64 SHA256 hash of the public key of cert,
+// the same value ./unrealircd spkifp prints
+func SPKIFingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// tlsClientConfig returns the TLS settings to dial the server of config with
+func tlsClientConfig(config *RPCConfig) (*tls.Config, error) {
+	u, err := url.Parse(config.WSURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebSocket URL %s: %w", config.WSURL, err)
+	}
+	host := u.Hostname()
+
+	var roots *x509.CertPool // nil is the system store
+	pin := strings.TrimSpace(config.SPKIPin)
+	switch config.Verify {
+	case "", TLSVerifySystem:
+	case TLSVerifyCAFile:
+		data, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", config.CAFile)
+		}
+	case TLSVerifyPin:
+		if pin == "" {
+			return nil, fmt.Errorf("no SPKI fingerprint pinned for profile %s", config.Name)
+		}
+	default:
+		return nil, fmt.Errorf("unknown TLS verification %q", config.Verify)
+	}
+
+	// The certificate is checked here rather than by crypto/tls, so a pin can
+	// stand in for a chain and a failure can report the fingerprint
+	verify := func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		var certs []*x509.Certificate
+		for _, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("invalid certificate from the RPC server: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+		if len(certs) == 0 {
+			return fmt.Errorf("the RPC server sent no certificate")
+		}
+		fingerprint := SPKIFingerprint(certs[0])
+
+		if config.Verify == TLSVerifyPin {
+			if fingerprint != pin {
+				return &UntrustedCertError{Fingerprint: fingerprint, Err: fmt.Errorf("it does not match the pinned fingerprint %s", pin)}
+			}
+			return nil
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(x509.VerifyOptions{
+			DNSName:       host,
+			Roots:         roots,
+			Intermediates: intermediates,
+		})
+		if err != nil {
+			return &UntrustedCertError{Fingerprint: fingerprint, Err: err}
+		}
+		return nil
+	}
+
+	return &tls.Config{
+		ServerName:            host,
+		InsecureSkipVerify:    true, // Verified by VerifyPeerCertificate
+		VerifyPeerCertificate: verify,
+	}, nil
+}
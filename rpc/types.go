@@ -2,10 +2,22 @@ package rpc
 
 // Server info
 type ServerInfo struct {
-	Name     string `json:"name"`
-	Uptime   int64  `json:"uptime"`
-	Software string `json:"software"`
-	Users    int    `json:"users"`
+	Name           string `json:"name"`
+	ID             string `json:"id"`
+	Info           string `json:"info"`
+	Hostname       string `json:"hostname"`
+	IP             string `json:"ip"`
+	Uplink         string `json:"uplink"`
+	Uptime         int64  `json:"uptime"`
+	BootTime       int64  `json:"boot_time"`
+	ConnectedSince int64  `json:"connected_since"`
+	Software       string `json:"software"`
+	Protocol       int    `json:"protocol"`
+	Users          int    `json:"users"`
+	Synced         bool   `json:"synced"`
+	Ulined         bool   `json:"ulined"`
+	TLSCipher      string `json:"tls_cipher"`
+	TLSCertFP      string `json:"tls_certfp"`
 }
 
 // User info
@@ -15,6 +27,8 @@ type UserInfo struct {
 	Realname       string   `json:"realname"`
 	Account        string   `json:"account"`
 	IP             string   `json:"ip"`
+	Country        string   `json:"country"` // GeoIP country code
+	Hostname       string   `json:"hostname"`
 	Channels       []string `json:"channels"`
 	Username       string   `json:"username"`
 	Vhost          string   `json:"vhost"`
@@ -27,30 +41,75 @@ type UserInfo struct {
 
 // Channel info
 type ChannelInfo struct {
-	Name      string   `json:"name"`
-	Topic     string   `json:"topic"`
-	Users     []string `json:"users"`
-	UserCount int      `json:"num_users"`
-	Modes     string   `json:"modes"`
-	Created   int64    `json:"created"`
+	Name             string          `json:"name"`
+	Topic            string          `json:"topic"`
+	Users            []string        `json:"users"`
+	Members          []ChannelMember `json:"members"`
+	UserCount        int             `json:"num_users"`
+	Modes            string          `json:"modes"`
+	Created          int64           `json:"created"`
+	Bans             []ListModeEntry `json:"bans"`
+	BanExemptions    []ListModeEntry `json:"ban_exemptions"`
+	InviteExceptions []ListModeEntry `json:"invite_exceptions"`
+}
+
+// Channel member and its prefix modes
+type ChannelMember struct {
+	Nick     string `json:"name"`
+	Level    string `json:"level"` // Prefix mode letters, like "ov"
+	Username string `json:"username"`
+	Hostname string `json:"hostname"`
+	IP       string `json:"ip"`
+}
+
+// Entry of a channel list mode: a ban (+b), ban exemption (+e) or invite exception (+I)
+type ListModeEntry struct {
+	Mask  string `json:"name"`
+	SetBy string `json:"set_by"`
+	SetAt int64  `json:"set_at"`
 }
 
 // Server ban info
 type ServerBanInfo struct {
-	Name      string `json:"name"`
-	Type      string `json:"type"`
-	Reason    string `json:"reason"`
-	Duration  int64  `json:"duration"`
-	Setby     string `json:"setby"`
-	CreatedAt int64  `json:"created_at"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	TypeString     string `json:"type_string"`
+	Reason         string `json:"reason"`
+	Duration       int64  `json:"duration"`
+	DurationString string `json:"duration_string"`
+	Setby          string `json:"setby"`
+	CreatedAt      int64  `json:"created_at"`
+	ExpireAt       int64  `json:"expire_at"` // 0 means the ban never expires
+	SetInConfig    bool   `json:"set_in_config"`
+}
+
+// Server ban exception info
+type BanExceptionInfo struct {
+	Name           string `json:"name"`
+	ExceptionTypes string `json:"exception_types"` // Letters, see BanExceptionTypes
+	Reason         string `json:"reason"`
+	DurationString string `json:"duration_string"`
+	Setby          string `json:"setby"`
+	CreatedAt      int64  `json:"created_at"`
+	ExpireAt       int64  `json:"expire_at"` // 0 means the exception never expires
+	SetInConfig    bool   `json:"set_in_config"`
 }
 
-// Log entry from server logs
-type LogEntry struct {
-	Time    int64  `json:"time"`
-	Level   string `json:"level"`
-	Source  string `json:"source"`
-	Message string `json:"message"`
+// Spamfilter info
+type SpamfilterInfo struct {
+	Name              string `json:"name"`       // The pattern
+	MatchType         string `json:"match_type"` // simple or regex
+	Targets           string `json:"spamfilter_targets"`
+	Action            string `json:"ban_action"`
+	BanDuration       int64  `json:"ban_duration"`
+	BanDurationString string `json:"ban_duration_string"`
+	Reason            string `json:"reason"`
+	Setby             string `json:"set_by"`
+	CreatedAt         int64  `json:"created_at"`
+	ExpireAt          int64  `json:"expire_at"` // 0 means the spamfilter never expires
+	SetInConfig       bool   `json:"set_in_config"`
+	Hits              int64  `json:"hits"`
+	HitsExcept        int64  `json:"hits_except"` // Hits on exempted users
 }
 
 // File-based log entry from JSON log file
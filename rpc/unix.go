@@ -0,0 +1,83 @@
+package rpc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// LocalSocketPath is where UnrealIRCd puts its RPC UNIX socket, relative to the installation
+const LocalSocketPath = "data/rpc.socket"
+
+// LocalProfileName is the name of the profile detected for the local installation
+const LocalProfileName = "local"
+
+// unixTransport is JSON-RPC over the UNIX socket of a local server, one JSON
+// object per line. It needs no rpc-user and no TLS, the permissions of the
+// socket file decide who may connect.
+type unixTransport struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialUnix(path string) (*unixTransport, error) {
+	conn, err := net.DialTimeout("unix", path, handshakeTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &unixTransport{conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+func (u *unixTransport) writeJSON(v interface{}, deadline time.Time) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	u.conn.SetWriteDeadline(deadline)
+	_, err = u.conn.Write(append(data, '\n'))
+	return err
+}
+
+func (u *unixTransport) readMessage() ([]byte, error) {
+	return u.reader.ReadBytes('\n')
+}
+
+// ping does nothing, a local server going away closes the socket
+func (u *unixTransport) ping() error {
+	return nil
+}
+
+func (u *unixTransport) close() error {
+	return u.conn.Close()
+}
+
+func (u *unixTransport) abort() error {
+	return u.conn.Close()
+}
+
+// DetectLocalRPC returns a profile for the RPC socket of the installation in
+// buildDir, or nil when there is none. Passed to LoadRPCProfiles it is listed
+// along with the saved profiles, and used when none of those was chosen.
+func DetectLocalRPC(buildDir string) *RPCConfig {
+	if buildDir == "" {
+		return nil
+	}
+	path := filepath.Join(buildDir, LocalSocketPath)
+	info, err := os.Stat(path)
+	if err != nil || info.Mode()&os.ModeSocket == 0 {
+		return nil
+	}
+	return &RPCConfig{Name: LocalProfileName, Socket: path, Detected: true}
+}
+
+// Target returns what the profile connects to, for display
+func (c *RPCConfig) Target() string {
+	if c.Socket != "" {
+		return fmt.Sprintf("unix:%s", c.Socket)
+	}
+	return c.WSURL
+}
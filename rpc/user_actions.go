@@ -0,0 +1,63 @@
+package rpc
+
+import "fmt"
+
+// userAction runs a user.* method on nick, adding nick to params
+func (r *RPCClient) userAction(method, nick string, params map[string]interface{}) error {
+	params["nick"] = nick
+	if _, err := r.query(method, params); err != nil {
+		return fmt.Errorf("%s on %s failed: %w", method, nick, err)
+	}
+	return nil
+}
+
+// KillUser disconnects a user with the given reason
+func (r *RPCClient) KillUser(nick, reason string) error {
+	return r.userAction("user.kill", nick, map[string]interface{}{
+		"reason": reason,
+	})
+}
+
+// SetUserNick changes the nick of a user
+func (r *RPCClient) SetUserNick(nick, newNick string) error {
+	return r.userAction("user.set_nick", nick, map[string]interface{}{
+		"newnick": newNick,
+	})
+}
+
+// SetUserVhost sets the virtual host of a user
+func (r *RPCClient) SetUserVhost(nick, vhost string) error {
+	return r.userAction("user.set_vhost", nick, map[string]interface{}{
+		"vhost": vhost,
+	})
+}
+
+// SetUserModes sets and unsets user modes, like "+x-i"
+func (r *RPCClient) SetUserModes(nick, modes string) error {
+	return r.userAction("user.set_mode", nick, map[string]interface{}{
+		"modes": modes,
+	})
+}
+
+// SetUserSnomask sets and unsets server notice masks, like "+bc-k"
+func (r *RPCClient) SetUserSnomask(nick, snomask string) error {
+	return r.userAction("user.set_snomask", nick, map[string]interface{}{
+		"snomask": snomask,
+	})
+}
+
+// JoinUser makes a user join a channel. With force set, bans, keys and limits are overridden.
+func (r *RPCClient) JoinUser(nick, channel string, force bool) error {
+	return r.userAction("user.join", nick, map[string]interface{}{
+		"channel": channel,
+		"force":   force,
+	})
+}
+
+// PartUser makes a user leave a channel
+func (r *RPCClient) PartUser(nick, channel string) error {
+	return r.userAction("user.part", nick, map[string]interface{}{
+		"channel": channel,
+		"force":   true,
+	})
+}
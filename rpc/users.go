@@ -0,0 +1,286 @@
+package rpc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	userListDetailLevel = 4   // Full user objects including channel memberships
+	userDetailWorkers   = 8   // Parallel user.get calls when user.list lacks fields
+	userBatchSize       = 250 // Users per batch handed to the StreamUsers callback
+	userBatchInterval   = 250 * time.Millisecond
+)
+
+// errInvalidParams is the JSON-RPC code for a call the server rejects the parameters of
+const errInvalidParams = -32602
+
+// GetUsers returns all users on the network
+func (r *RPCClient) GetUsers() ([]UserInfo, error) {
+	var users []UserInfo
+	err := r.StreamUsers(func(batch []UserInfo) bool {
+		users = append(users, batch...)
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// StreamUsers fetches all users and hands them to onBatch as they become
+// available, so a view can fill in gradually. Everything user.list returns
+// completely is delivered first, users it returned without their channels
+// are then completed with parallel user.get calls. onBatch is never called
+// concurrently, returning false from it stops the remaining lookups.
+func (r *RPCClient) StreamUsers(onBatch func(users []UserInfo) bool) error {
+	result, err := r.query("user.list", map[string]interface{}{"object_detail_level": userListDetailLevel})
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == errInvalidParams {
+		// Older servers do not allow this detail level in user.list
+		result, err = r.query("user.list", map[string]interface{}{"object_detail_level": 2})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	userList, err := resultList(result)
+	if err != nil {
+		return err
+	}
+
+	var parsed []UserInfo
+	var hasChannels []bool
+	var incomplete []string
+	listHasChannels := false
+	for _, u := range userList {
+		switch v := u.(type) {
+		case map[string]interface{}:
+			user, ok := parseUser(v)
+			if user.Nick == "" {
+				continue
+			}
+			parsed = append(parsed, user)
+			hasChannels = append(hasChannels, ok)
+			listHasChannels = listHasChannels || ok
+		case string:
+			// Just a nick, everything else has to be looked up
+			incomplete = append(incomplete, v)
+		}
+	}
+
+	// If the server sent channels for anyone, a user without them is in no channels
+	var complete []UserInfo
+	for i, user := range parsed {
+		if hasChannels[i] || listHasChannels {
+			complete = append(complete, user)
+		} else {
+			incomplete = append(incomplete, user.Nick)
+		}
+	}
+
+	for len(complete) > 0 {
+		n := userBatchSize
+		if n > len(complete) {
+			n = len(complete)
+		}
+		if !onBatch(complete[:n]) {
+			return nil
+		}
+		complete = complete[n:]
+	}
+
+	if len(incomplete) == 0 {
+		return nil
+	}
+	return r.streamUserDetails(incomplete, onBatch)
+}
+
+// streamUserDetails looks up nicks with a bounded number of concurrent user.get
+// calls, batching the results for onBatch. Users that quit in the meantime are skipped.
+func (r *RPCClient) streamUserDetails(nicks []string, onBatch func(users []UserInfo) bool) error {
+	nickChan := make(chan string)
+	results := make(chan *UserInfo)
+	stop := make(chan struct{})
+	defer close(stop) // Releases the feeder and workers however this returns
+
+	var wg sync.WaitGroup
+	for i := 0; i < userDetailWorkers && i < len(nicks); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for nick := range nickChan {
+				user, err := r.GetUserDetails(nick)
+				if err != nil {
+					if errors.Is(err, ErrConnectionClosed) {
+						return
+					}
+					user = nil
+				}
+				select {
+				case results <- user:
+				case <-stop:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(nickChan)
+		for _, nick := range nicks {
+			select {
+			case nickChan <- nick:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	ticker := time.NewTicker(userBatchInterval)
+	defer ticker.Stop()
+
+	var batch []UserInfo
+	flush := func() bool {
+		if len(batch) == 0 {
+			return true
+		}
+		keepGoing := onBatch(batch)
+		batch = nil
+		return keepGoing
+	}
+
+	received := 0
+	for {
+		select {
+		case user, ok := <-results:
+			if !ok {
+				flush()
+				if received < len(nicks) {
+					// The workers gave up on a closed connection, the list is incomplete
+					err := r.Connect()
+					if err == nil {
+						err = ErrConnectionClosed
+					}
+					return fmt.Errorf("failed to get details of %d of %d users: %w", len(nicks)-received, len(nicks), err)
+				}
+				return nil
+			}
+			received++
+			if user != nil && user.Nick != "" {
+				batch = append(batch, *user)
+			}
+			if len(batch) >= userBatchSize && !flush() {
+				return nil
+			}
+		case <-ticker.C:
+			if !flush() {
+				return nil
+			}
+		}
+	}
+}
+
+func (r *RPCClient) GetUserDetails(nick string) (*UserInfo, error) {
+	userData, err := r.query("user.get", map[string]interface{}{"nick": nick, "object_detail_level": 4})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user details for %s: %w", nick, err)
+	}
+
+	// The user object is wrapped in a "client" key
+	if resultMap, ok := userData.(map[string]interface{}); ok {
+		if clientMap, ok := resultMap["client"].(map[string]interface{}); ok {
+			userData = clientMap
+		}
+	}
+
+	userMap, ok := userData.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected user details format: %T", userData)
+	}
+	user, _ := parseUser(userMap)
+	return &user, nil
+}
+
+// parseUser parses a client object, also reporting whether it included the channel list
+func parseUser(userMap map[string]interface{}) (UserInfo, bool) {
+	user := UserInfo{}
+	hasChannels := false
+
+	if name, ok := userMap["name"].(string); ok { // name is at top level
+		user.Name = name
+		user.Nick = name
+	}
+	if ip, ok := userMap["ip"].(string); ok { // IP is at top level
+		user.IP = ip
+	}
+	if hostname, ok := userMap["hostname"].(string); ok {
+		user.Hostname = hostname
+	}
+	if geoip, ok := userMap["geoip"].(map[string]interface{}); ok {
+		if country, ok := geoip["country_code"].(string); ok {
+			user.Country = country
+		}
+	}
+
+	// The actual user data is under "user" key, fall back to top level if missing
+	userData, ok := userMap["user"].(map[string]interface{})
+	if !ok {
+		userData = userMap
+	}
+
+	if realname, ok := userData["realname"].(string); ok {
+		user.Realname = realname
+	}
+	if account, ok := userData["account"].(string); ok {
+		user.Account = account
+	}
+	if username, ok := userData["username"].(string); ok {
+		user.Username = username
+	}
+	if vhost, ok := userData["vhost"].(string); ok {
+		user.Vhost = vhost
+	}
+	if cloakedhost, ok := userData["cloakedhost"].(string); ok {
+		user.Cloakedhost = cloakedhost
+	}
+	if servername, ok := userData["servername"].(string); ok {
+		user.Servername = servername
+	}
+	if reputation, ok := userData["reputation"].(float64); ok {
+		user.Reputation = int(reputation)
+	}
+	if modes, ok := userData["modes"].(string); ok {
+		user.Modes = modes
+	}
+	// Channels are objects at the highest detail level, plain names below that
+	if channelsData, ok := userData["channels"].([]interface{}); ok {
+		hasChannels = true
+		for _, ch := range channelsData {
+			if chMap, ok := ch.(map[string]interface{}); ok {
+				if chName, ok := chMap["name"].(string); ok {
+					user.Channels = append(user.Channels, chName)
+				}
+			} else if chName, ok := ch.(string); ok {
+				user.Channels = append(user.Channels, chName)
+			}
+		}
+	}
+	// Extract security-groups
+	if sgData, ok := userData["security-groups"].([]interface{}); ok {
+		for _, sg := range sgData {
+			if sgStr, ok := sg.(string); ok {
+				user.SecurityGroups = append(user.SecurityGroups, sgStr)
+			}
+		}
+	}
+
+	return user, hasChannels
+}
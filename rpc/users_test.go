@@ -0,0 +1,159 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeTransport answers calls with handle, which drops the connection by returning false
+type fakeTransport struct {
+	handle  func(req rpcRequest) (interface{}, bool)
+	replies chan []byte
+	done    chan struct{}
+	once    sync.Once
+}
+
+func (f *fakeTransport) writeJSON(v interface{}, deadline time.Time) error {
+	req := v.(rpcRequest)
+	result, ok := f.handle(req)
+	if !ok {
+		return f.abort()
+	}
+	data, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	if err != nil {
+		return err
+	}
+	select {
+	case f.replies <- data:
+	case <-f.done:
+	}
+	return nil
+}
+
+func (f *fakeTransport) readMessage() ([]byte, error) {
+	select {
+	case data := <-f.replies:
+		return data, nil
+	case <-f.done:
+		return nil, errors.New("connection reset by peer")
+	}
+}
+
+func (f *fakeTransport) ping() error  { return nil }
+func (f *fakeTransport) close() error { return f.abort() }
+
+func (f *fakeTransport) abort() error {
+	f.once.Do(func() { close(f.done) })
+	return nil
+}
+
+// newFakeClient returns a client whose calls are answered by handle
+func newFakeClient(handle func(req rpcRequest) (interface{}, bool)) *RPCClient {
+	t := &fakeTransport{handle: handle, replies: make(chan []byte, 100), done: make(chan struct{})}
+	c := &conn{t: t, pending: make(map[int64]chan *rpcMessage), closed: make(chan struct{})}
+	go c.readLoop()
+	return &RPCClient{conn: c, events: make(chan *rpcMessage, 100)}
+}
+
+// userGetHandler answers user.get for any nick, and drops the connection after limit calls when limit > 0
+func userGetHandler(limit int64) func(req rpcRequest) (interface{}, bool) {
+	var calls atomic.Int64
+	return func(req rpcRequest) (interface{}, bool) {
+		if limit > 0 && calls.Add(1) > limit {
+			return nil, false
+		}
+		nick := req.Params.(map[string]interface{})["nick"]
+		return map[string]interface{}{"client": map[string]interface{}{"name": nick, "user": map[string]interface{}{"channels": []string{}}}}, true
+	}
+}
+
+func testNicks(n int) []string {
+	nicks := make([]string, n)
+	for i := range nicks {
+		nicks[i] = "nick" + string(rune('a'+i%26)) + string(rune('a'+i/26))
+	}
+	return nicks
+}
+
+// waitGoroutines waits for the number of goroutines to drop back to at most n
+func waitGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Errorf("%d goroutines left running, want at most %d", runtime.NumGoroutine(), n)
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStreamUserDetails(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	client := newFakeClient(userGetHandler(0))
+
+	nicks := testNicks(100)
+	seen := make(map[string]bool)
+	err := client.streamUserDetails(nicks, func(users []UserInfo) bool {
+		for _, user := range users {
+			seen[user.Nick] = true
+		}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("streamUserDetails: %v", err)
+	}
+	for _, nick := range nicks {
+		if !seen[nick] {
+			t.Errorf("no details for %s", nick)
+		}
+	}
+
+	client.Close()
+	waitGoroutines(t, baseline)
+}
+
+func TestStreamUserDetailsConnectionLost(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	client := newFakeClient(userGetHandler(20))
+
+	var received int
+	err := client.streamUserDetails(testNicks(100), func(users []UserInfo) bool {
+		received += len(users)
+		return true
+	})
+	if !errors.Is(err, ErrConnectionClosed) {
+		t.Errorf("streamUserDetails = %v, want ErrConnectionClosed", err)
+	}
+	if received >= 100 {
+		t.Errorf("got details of %d users over a dropped connection", received)
+	}
+
+	// The nicks not handed to a worker must not keep the feeder waiting
+	waitGoroutines(t, baseline)
+}
+
+func TestStreamUserDetailsStopped(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	client := newFakeClient(userGetHandler(0))
+
+	batches := 0
+	err := client.streamUserDetails(testNicks(600), func(users []UserInfo) bool {
+		batches++
+		return false
+	})
+	if err != nil {
+		t.Errorf("streamUserDetails: %v", err)
+	}
+	if batches != 1 {
+		t.Errorf("onBatch called %d times after returning false, want 1", batches)
+	}
+
+	client.Close()
+	waitGoroutines(t, baseline)
+}
@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// banPreviewUsers is how many matching users a ban confirmation lists
+const banPreviewUsers = 15
+
+// banBuilderUsers is how many matching users the mask builder lists
+const banBuilderUsers = 200
+
+// heldUsersTable is the users table of the remote control menu. Ban masks are
+// matched against the users it holds, or against a fresh list when it has none.
+var heldUsersTable *listTable
+
+// loadHeldUsers calls onLoaded on the UI goroutine with the connected users
+func loadHeldUsers(app *tview.Application, config *rpc.RPCConfig, onLoaded func(users []rpc.UserInfo, err error)) {
+	table := heldUsersTable
+	go func() {
+		var users []rpc.UserInfo
+		if table != nil {
+			table.Read(func(m *tableModel) {
+				// Live updates replace users rather than change them
+				users = append(users, m.items.(userItems)...)
+			})
+		}
+
+		var err error
+		if len(users) == 0 {
+			var client *rpc.RPCClient
+			client, err = rpc.Sessions.Get(config).Client()
+			if err == nil {
+				users, err = client.GetUsers()
+			}
+		}
+		app.QueueUpdateDraw(func() {
+			onLoaded(users, err)
+		})
+	}()
+}
+
+// banMatchWarning returns a warning when a ban hits a large part of the network, or ""
+func banMatchWarning(matches, total int) string {
+	if total > 1 && matches == total {
+		return "WARNING: this matches every connected user!"
+	}
+	if total > 0 && matches*10 > total {
+		return fmt.Sprintf("WARNING: this matches %.0f%% of the connected users!", float64(matches)*100/float64(total))
+	}
+	return ""
+}
+
+// formatBanMatchSummary lists the first users a ban matches, for a confirmation modal
+func formatBanMatchSummary(matches []rpc.UserInfo, total int) string {
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("Matches %d of %d connected users", len(matches), total))
+	if warning := banMatchWarning(len(matches), total); warning != "" {
+		result.WriteString("\n" + warning)
+	}
+	for i, user := range matches {
+		if i == banPreviewUsers {
+			result.WriteString(fmt.Sprintf("\n...and %d more", len(matches)-i))
+			break
+		}
+		result.WriteString(fmt.Sprintf("\n%s (%s@%s)", user.Nick, user.Username, user.Hostname))
+	}
+	return result.String()
+}
+
+// parseBanMask parses mask as a server ban of banType, or as a channel ban when banType is ""
+func parseBanMask(banType, mask string) (*rpc.BanMask, error) {
+	if banType == "" {
+		return rpc.ParseBanMask(mask)
+	}
+	return rpc.ParseServerBanMask(banType, mask)
+}
+
+// showBanMatchConfirm asks question with the connected users a server ban of
+// banType on mask matches listed below it, and calls onYes if confirmed
+func showBanMatchConfirm(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, banType, mask, question string, onYes func()) {
+	loadHeldUsers(app, config, func(users []rpc.UserInfo, err error) {
+		var summary string
+		parsed, parseErr := rpc.ParseServerBanMask(banType, mask)
+		switch {
+		case err != nil:
+			summary = fmt.Sprintf("Connected users could not be fetched to check the mask: %v", err)
+		case parseErr != nil:
+			summary = fmt.Sprintf("The mask could not be checked against the connected users: %v", parseErr)
+		default:
+			summary = formatBanMatchSummary(rpc.MatchBanMask(parsed, users), len(users))
+		}
+		showConfirmModal(pages, "ban_match_confirm_modal", question+"\n\n"+summary, onYes)
+	})
+}
+
+// showBanMaskBuilder lets the user type a ban mask or pick one of templates,
+// showing the connected users it matches as it changes. banType is the server
+// ban type the mask is for, or "" for a channel ban. onApply gets the mask and
+// a summary of its matches for the confirmation.
+func showBanMaskBuilder(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, title, banType, mask string, templates []string, onApply func(mask, summary string)) {
+	var users []rpc.UserInfo
+	var loadErr error
+	loaded := false
+
+	form := tview.NewForm()
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.SetItemPadding(0)
+
+	preview := tview.NewTextView()
+	preview.SetBorder(true).SetTitle("Matching Users")
+	preview.SetDynamicColors(true)
+	preview.SetWordWrap(true)
+	preview.SetText("Loading users...")
+
+	// matchMask checks the mask against the users, with the error of an invalid mask
+	matchMask := func(text string) ([]rpc.UserInfo, error) {
+		parsed, err := parseBanMask(banType, text)
+		if err != nil {
+			return nil, err
+		}
+		return rpc.MatchBanMask(parsed, users), nil
+	}
+
+	updatePreview := func(text string) {
+		if !loaded {
+			return
+		}
+		matches, err := matchMask(text)
+		if err != nil {
+			preview.SetText(fmt.Sprintf("[red]%s[-]", tview.Escape(err.Error())))
+			return
+		}
+
+		var result strings.Builder
+		result.WriteString(fmt.Sprintf("[green]%d of %d connected users match[-]\n", len(matches), len(users)))
+		if warning := banMatchWarning(len(matches), len(users)); warning != "" {
+			result.WriteString(fmt.Sprintf("[red]%s[-]\n", warning))
+		}
+		result.WriteString("\n")
+		for i, user := range matches {
+			if i == banBuilderUsers {
+				result.WriteString(fmt.Sprintf("...and %d more\n", len(matches)-i))
+				break
+			}
+			account := user.Account
+			if account == "" || account == "none" {
+				account = "-"
+			}
+			result.WriteString(fmt.Sprintf("[blue]%-16s[white] %s@%s  %s  account %s\n",
+				tview.Escape(user.Nick), tview.Escape(user.Username), tview.Escape(user.Hostname), user.IP, tview.Escape(account)))
+		}
+		preview.SetText(result.String())
+		preview.ScrollToBeginning()
+	}
+
+	maskField := tview.NewInputField().
+		SetLabel("Mask: ").
+		SetText(mask).
+		SetFieldWidth(60).
+		SetChangedFunc(updatePreview)
+	form.AddFormItem(maskField)
+	if len(templates) > 0 {
+		form.AddDropDown("Template: ", templates, -1, func(option string, index int) {
+			if index >= 0 {
+				maskField.SetText(option)
+			}
+		})
+	}
+
+	closeModal := func() {
+		pages.RemovePage("ban_mask_builder_modal")
+	}
+
+	// Masks that cannot be checked here, like ~certfp, can still be used
+	form.AddButton("Use Mask", func() {
+		text := strings.TrimSpace(maskField.GetText())
+		if text == "" || (!loaded && loadErr == nil) {
+			return
+		}
+		var summary string
+		matches, err := matchMask(text)
+		switch {
+		case loadErr != nil:
+			summary = fmt.Sprintf("Connected users could not be fetched to check the mask: %v", loadErr)
+		case err != nil:
+			summary = fmt.Sprintf("The mask could not be checked against the connected users: %v", err)
+		default:
+			summary = formatBanMatchSummary(matches, len(users))
+		}
+		closeModal()
+		onApply(text, summary)
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	help := tview.NewTextView()
+	help.SetDynamicColors(true)
+	help.SetWordWrap(true)
+	help.SetText("[gray]nick!user@host or user@host with * and ?, a network like *@192.0.2.0/24, or ~account:name, ~account:0, ~country:NL, ~security-group:name, ~realname:mask, ~channel:#chan[-]")
+
+	formHeight := 4
+	if len(templates) > 0 {
+		formHeight++
+	}
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.SetBorder(true).SetTitle(title)
+	layout.AddItem(form, formHeight, 0, true)
+	layout.AddItem(help, 2, 0, false)
+	layout.AddItem(preview, 0, 1, false)
+
+	pages.AddPage("ban_mask_builder_modal", centeredModal(layout, 100, 32), true, true)
+	app.SetFocus(form)
+
+	loadHeldUsers(app, config, func(heldUsers []rpc.UserInfo, err error) {
+		if err != nil {
+			loadErr = err
+			preview.SetText(fmt.Sprintf("[red]Error fetching users: %v[-]", err))
+			return
+		}
+		users = heldUsers
+		loaded = true
+		updatePreview(maskField.GetText())
+	})
+}
@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func remoteBanExceptionsPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	var exceptions []rpc.BanExceptionInfo
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	exceptionsList := tview.NewList()
+	exceptionsList.SetBorder(true)
+	exceptionsList.SetTitle("Ban Exceptions")
+	exceptionsList.SetBorderColor(tcell.ColorBlue)
+
+	exceptionDetailsView := tview.NewTextView()
+	exceptionDetailsView.SetBorder(true)
+	exceptionDetailsView.SetTitle("Exception Details")
+	exceptionDetailsView.SetDynamicColors(true)
+	exceptionDetailsView.SetWordWrap(true)
+	exceptionDetailsView.SetText("Loading ban exceptions...")
+
+	showExceptionDetails := func(index int) {
+		if index < 0 || index >= len(exceptions) {
+			exceptionDetailsView.SetText("No ban exceptions.")
+			return
+		}
+		exceptionDetailsView.SetText(formatBanExceptionDetails(exceptions[index]))
+	}
+
+	renderExceptions := func() {
+		sort.SliceStable(exceptions, func(i, j int) bool {
+			return exceptions[i].Name < exceptions[j].Name
+		})
+		exceptionsList.SetTitle(fmt.Sprintf("Ban Exceptions (%d)", len(exceptions)))
+		exceptionsList.Clear()
+		for _, exception := range exceptions {
+			mainText := fmt.Sprintf("%-8s %s", exception.ExceptionTypes, tview.Escape(exception.Name))
+			secondaryText := fmt.Sprintf("  by %s, expires %s - %s", tview.Escape(exception.Setby), formatExpiry(exception.ExpireAt), tview.Escape(exception.Reason))
+			exceptionsList.AddItem(mainText, secondaryText, 0, nil)
+		}
+		if len(exceptions) > 0 {
+			exceptionsList.SetCurrentItem(0)
+		}
+		showExceptionDetails(exceptionsList.GetCurrentItem())
+	}
+
+	loadExceptions := func() {
+		exceptionDetailsView.SetText("Loading ban exceptions...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					exceptionDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			newExceptions, err := client.GetBanExceptions()
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					exceptionDetailsView.SetText(fmt.Sprintf("Error fetching ban exceptions: %v", err))
+					return
+				}
+				exceptions = newExceptions
+				renderExceptions()
+			})
+		}()
+	}
+
+	exceptionsList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showExceptionDetails(index)
+	})
+
+	addException := func() {
+		showAddBanExceptionModal(app, pages, config, loadExceptions)
+	}
+
+	deleteException := func() {
+		index := exceptionsList.GetCurrentItem()
+		if index < 0 || index >= len(exceptions) {
+			return
+		}
+		exception := exceptions[index]
+		if exception.SetInConfig {
+			showMessageModal(pages, "ban_exception_config_modal", fmt.Sprintf("The exception for %s is set in the configuration file and cannot be removed over RPC.", exception.Name))
+			return
+		}
+		showConfirmModal(pages, "ban_exception_delete_modal", fmt.Sprintf("Remove the ban exception for %s?", exception.Name), func() {
+			go func() {
+				client, err := rpc.Sessions.Get(config).Client()
+				if err == nil {
+					err = client.DeleteBanException(exception.Name)
+				}
+				app.QueueUpdateDraw(func() {
+					if err != nil {
+						showMessageModal(pages, "ban_exception_error_modal", fmt.Sprintf("Error removing ban exception: %v", err))
+						return
+					}
+					loadExceptions()
+				})
+			}()
+		})
+	}
+
+	back := func() {
+		pages.RemovePage("remote_ban_exceptions")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	exceptionsList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			addException()
+			return nil
+		case 'd':
+			deleteException()
+			return nil
+		case 'r':
+			loadExceptions()
+			return nil
+		}
+		if event.Key() == tcell.KeyDelete {
+			deleteException()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(exceptionsList, 0, 2, true)
+	contentFlex.AddItem(exceptionDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Add Exception").SetSelectedFunc(addException), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Delete Exception").SetSelectedFunc(deleteException), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadExceptions), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("a: Add | d: Delete | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_ban_exceptions", flex, true, true)
+	app.SetFocus(exceptionsList)
+
+	loadExceptions()
+}
+
+// showAddBanExceptionModal shows the form for adding a ban exception, with a checkbox per exception type
+func showAddBanExceptionModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, onAdded func()) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Add Ban Exception")
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.SetItemPadding(0)
+
+	form.AddInputField("Mask:", "", 40, nil, nil)
+	form.AddInputField("Duration:", "0", 20, nil, nil)
+	form.AddInputField("Reason:", "", 40, nil, nil)
+	for _, exceptionType := range rpc.BanExceptionTypes {
+		form.AddCheckbox(fmt.Sprintf("%s (%s)", exceptionType.Description, exceptionType.Letter), false, nil)
+	}
+
+	form.AddButton("Add", func() {
+		name := strings.TrimSpace(form.GetFormItemByLabel("Mask:").(*tview.InputField).GetText())
+		duration := strings.TrimSpace(form.GetFormItemByLabel("Duration:").(*tview.InputField).GetText())
+		reason := strings.TrimSpace(form.GetFormItemByLabel("Reason:").(*tview.InputField).GetText())
+
+		var exceptionTypes strings.Builder
+		for _, exceptionType := range rpc.BanExceptionTypes {
+			label := fmt.Sprintf("%s (%s)", exceptionType.Description, exceptionType.Letter)
+			if form.GetFormItemByLabel(label).(*tview.Checkbox).IsChecked() {
+				exceptionTypes.WriteString(exceptionType.Letter)
+			}
+		}
+
+		if name == "" || reason == "" {
+			showMessageModal(pages, "ban_exception_validation_modal", "Mask and reason are required.")
+			return
+		}
+		if exceptionTypes.Len() == 0 {
+			showMessageModal(pages, "ban_exception_validation_modal", "Choose at least one type of ban to be exempt from.")
+			return
+		}
+
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err == nil {
+				err = client.AddBanException(name, exceptionTypes.String(), duration, reason)
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "ban_exception_error_modal", fmt.Sprintf("Error adding ban exception: %v", err))
+					return
+				}
+				pages.RemovePage("ban_exception_add_modal")
+				if onAdded != nil {
+					onAdded()
+				}
+			})
+		}()
+	})
+
+	form.AddButton("Cancel", func() {
+		pages.RemovePage("ban_exception_add_modal")
+	})
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("ban_exception_add_modal", centeredModal(form, 64, 23), true, true)
+}
+
+func formatBanExceptionDetails(exception rpc.BanExceptionInfo) string {
+	setAt := "Unknown"
+	if exception.CreatedAt > 0 {
+		setAt = time.Unix(exception.CreatedAt, 0).Format("2006-01-02 15:04:05")
+	}
+
+	source := "RPC / IRC"
+	if exception.SetInConfig {
+		source = "Configuration file"
+	}
+
+	return fmt.Sprintf(
+		"[green]Mask:[white]\n  %s\n"+
+			"[green]Exempt From:[white]\n  %s (%s)\n"+
+			"[green]Reason:[white]\n  %s\n"+
+			"[green]Set By:[white]\n  %s\n"+
+			"[green]Set At:[white]\n  %s\n"+
+			"[green]Expires:[white]\n  %s\n"+
+			"[green]Duration:[white]\n  %s\n"+
+			"[green]Source:[white]\n  %s",
+		tview.Escape(exception.Name), rpc.DescribeBanExceptionTypes(exception.ExceptionTypes), exception.ExceptionTypes,
+		tview.Escape(exception.Reason), tview.Escape(exception.Setby), setAt, formatExpiry(exception.ExpireAt),
+		exception.DurationString, source)
+}
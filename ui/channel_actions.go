@@ -0,0 +1,322 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// channelActionKeys describes the keys handleChannelActionKey understands
+const channelActionKeys = "t: Topic | m: Modes | k: Kick | u: Go to User | b: Bans | e: Exempts | I: Invex"
+
+// handleChannelActionKey starts the administration action bound to key on channel.
+// onDone is called after the action succeeded, onSelectUser with the nick of the member
+// picked to be shown in the Users pane. It returns false for keys without an action.
+func handleChannelActionKey(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, channel rpc.ChannelInfo, key rune, onDone func(), onSelectUser func(nick string)) bool {
+	name := channel.Name
+	switch key {
+	case 't':
+		showInputModal(app, pages, "Topic of "+name, "Topic:", channel.Topic, "", func(topic string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Set the topic of %s to %q?", name, topic), func(client *rpc.RPCClient) error {
+				return client.SetChannelTopic(name, topic)
+			}, onDone)
+		})
+	case 'm':
+		showInputModal(app, pages, fmt.Sprintf("Modes of %s (now %s)", name, channel.Modes), "Modes (+l-s 10):", "", "", func(input string, _ bool) {
+			modes, parameters, _ := strings.Cut(input, " ")
+			runRPCAction(app, pages, config, fmt.Sprintf("Set modes %s on %s?", input, name), func(client *rpc.RPCClient) error {
+				return client.SetChannelMode(name, modes, strings.TrimSpace(parameters))
+			}, onDone)
+		})
+	case 'k':
+		showKickMemberModal(app, pages, config, channel, onDone)
+	case 'u':
+		if len(channel.Members) == 0 {
+			showMessageModal(pages, "rpc_action_error_modal", fmt.Sprintf("No members of %s are known yet.", name))
+			break
+		}
+		showMemberPickerModal(app, pages, "Go to User in "+name, channel.Members, onSelectUser)
+	case 'b', 'e', 'I':
+		channelListModePage(app, pages, config, name, string(key))
+	default:
+		return false
+	}
+	return true
+}
+
+// showKickMemberModal lets the user pick a member of channel, then asks for the kick reason
+func showKickMemberModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, channel rpc.ChannelInfo, onDone func()) {
+	kick := func(nick string) {
+		showInputModal(app, pages, fmt.Sprintf("Kick %s from %s", nick, channel.Name), "Reason:", "", "", func(reason string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Kick %s from %s (%s)?", nick, channel.Name, reason), func(client *rpc.RPCClient) error {
+				return client.KickUser(channel.Name, nick, reason)
+			}, onDone)
+		})
+	}
+
+	if len(channel.Members) == 0 {
+		showInputModal(app, pages, "Kick from "+channel.Name, "Nick:", "", "", func(nick string, _ bool) {
+			kick(nick)
+		})
+		return
+	}
+
+	showMemberPickerModal(app, pages, "Kick from "+channel.Name, channel.Members, kick)
+}
+
+// showMemberPickerModal lets the user pick one of members, grouped by prefix as in the channel details
+func showMemberPickerModal(app *tview.Application, pages *tview.Pages, title string, members []rpc.ChannelMember, onSelect func(nick string)) {
+	membersList := tview.NewList()
+	membersList.SetBorder(true)
+	membersList.SetTitle(title + " (Enter: Select | c: Cancel)")
+	membersList.ShowSecondaryText(false)
+
+	closeModal := func() {
+		pages.RemovePage("channel_member_modal")
+	}
+
+	for _, member := range members {
+		nick := member.Nick
+		membersList.AddItem(tview.Escape(member.Prefix()+nick), "", 0, func() {
+			closeModal()
+			onSelect(nick)
+		})
+	}
+	membersList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'c' {
+			closeModal()
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("channel_member_modal", centeredModal(membersList, 48, 20), true, true)
+	app.SetFocus(membersList)
+}
+
+// channelListModePage shows and edits one list mode ("b", "e" or "I") of a channel
+func channelListModePage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, channelName, mode string) {
+	var entries []rpc.ListModeEntry
+
+	title := "+" + mode
+	for _, listMode := range rpc.ChannelListModes {
+		if listMode.Letter == mode {
+			title = fmt.Sprintf("%s (+%s)", listMode.Description, mode)
+		}
+	}
+	title = fmt.Sprintf("%s on %s", title, channelName)
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	entriesList := tview.NewList()
+	entriesList.SetBorder(true)
+	entriesList.SetTitle(title)
+	entriesList.SetBorderColor(tcell.ColorBlue)
+
+	entryDetailsView := tview.NewTextView()
+	entryDetailsView.SetBorder(true)
+	entryDetailsView.SetTitle("Entry Details")
+	entryDetailsView.SetDynamicColors(true)
+	entryDetailsView.SetWordWrap(true)
+	entryDetailsView.SetText("Loading entries...")
+
+	showEntryDetails := func(index int) {
+		if index < 0 || index >= len(entries) {
+			entryDetailsView.SetText("No entries.")
+			return
+		}
+		entryDetailsView.SetText(formatListModeEntryDetails(entries[index]))
+	}
+
+	renderEntries := func() {
+		entriesList.SetTitle(fmt.Sprintf("%s (%d)", title, len(entries)))
+		entriesList.Clear()
+		for _, entry := range entries {
+			secondaryText := fmt.Sprintf("  by %s", tview.Escape(entry.SetBy))
+			entriesList.AddItem(tview.Escape(entry.Mask), secondaryText, 0, nil)
+		}
+		if len(entries) > 0 {
+			entriesList.SetCurrentItem(0)
+		}
+		showEntryDetails(entriesList.GetCurrentItem())
+	}
+
+	loadEntries := func() {
+		entryDetailsView.SetText("Loading entries...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					entryDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			channel, err := client.GetChannelDetails(channelName)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					entryDetailsView.SetText(fmt.Sprintf("Error getting channel details: %v", err))
+					return
+				}
+				entries = channel.ListModeEntries(mode)
+				renderEntries()
+			})
+		}()
+	}
+
+	entriesList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showEntryDetails(index)
+	})
+
+	addEntry := func() {
+		setEntry := func(mask, question string) {
+			runRPCAction(app, pages, config, question, func(client *rpc.RPCClient) error {
+				return client.AddChannelListEntry(channelName, mode, mask)
+			}, loadEntries)
+		}
+		// Bans are built with a preview of the users they hit
+		if mode == "b" {
+			showBanMaskBuilder(app, pages, config, "Ban on "+channelName, "", "", nil, func(mask, summary string) {
+				setEntry(mask, fmt.Sprintf("Set +b %s on %s?\n\n%s", mask, channelName, summary))
+			})
+			return
+		}
+		showInputModal(app, pages, title, "Mask:", "", "", func(mask string, _ bool) {
+			setEntry(mask, fmt.Sprintf("Set +%s %s on %s?", mode, mask, channelName))
+		})
+	}
+
+	deleteEntry := func() {
+		index := entriesList.GetCurrentItem()
+		if index < 0 || index >= len(entries) {
+			return
+		}
+		mask := entries[index].Mask
+		runRPCAction(app, pages, config, fmt.Sprintf("Set -%s %s on %s?", mode, mask, channelName), func(client *rpc.RPCClient) error {
+			return client.DeleteChannelListEntry(channelName, mode, mask)
+		}, loadEntries)
+	}
+
+	back := func() {
+		pages.RemovePage("remote_channel_list_mode")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	entriesList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			addEntry()
+			return nil
+		case 'd':
+			deleteEntry()
+			return nil
+		case 'r':
+			loadEntries()
+			return nil
+		}
+		if event.Key() == tcell.KeyDelete {
+			deleteEntry()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(entriesList, 0, 2, true)
+	contentFlex.AddItem(entryDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Add Entry").SetSelectedFunc(addEntry), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Delete Entry").SetSelectedFunc(deleteEntry), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadEntries), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("a: Add | d: Delete | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_channel_list_mode", flex, true, true)
+	app.SetFocus(entriesList)
+
+	loadEntries()
+}
+
+func formatListModeEntryDetails(entry rpc.ListModeEntry) string {
+	setAt := "Unknown"
+	if entry.SetAt > 0 {
+		setAt = time.Unix(entry.SetAt, 0).Format("2006-01-02 15:04:05")
+	}
+
+	return fmt.Sprintf(
+		"[green]Mask:[white]\n  %s\n"+
+			"[green]Set By:[white]\n  %s\n"+
+			"[green]Set At:[white]\n  %s",
+		tview.Escape(entry.Mask), tview.Escape(entry.SetBy), setAt)
+}
+
+// formatChannelDetails formats the details of a channel as returned by GetChannelDetails
+func formatChannelDetails(channel rpc.ChannelInfo) string {
+	return fmt.Sprintf(
+		"[green]Name:[white]\n  %s\n"+
+			"[green]Topic:[white]\n  %s\n"+
+			"[green]Modes:[white]\n  %s\n"+
+			"[green]Created:[white]\n  %s\n"+
+			"[green]Lists:[white]\n  %d bans, %d exempts, %d invex\n"+
+			"[green]Members:[white]%s",
+		tview.Escape(channel.Name),
+		tview.Escape(channel.Topic),
+		channel.Modes,
+		time.Unix(channel.Created, 0).Format("2006-01-02 15:04:05"),
+		len(channel.Bans), len(channel.BanExemptions), len(channel.InviteExceptions),
+		formatChannelMembers(channel.Members))
+}
+
+// formatChannelMembers lists members grouped by their highest prefix mode, with a
+// count per level. members are expected to be sorted by SortChannelMembers.
+func formatChannelMembers(members []rpc.ChannelMember) string {
+	if len(members) == 0 {
+		return "\n  None"
+	}
+
+	counts := make([]int, len(rpc.ChannelPrefixes)+1)
+	for _, member := range members {
+		counts[member.Rank()]++
+	}
+
+	var summary []string
+	for i, prefix := range rpc.ChannelPrefixes {
+		summary = append(summary, fmt.Sprintf("%s%d", prefix.Symbol, counts[i]))
+	}
+	summary = append(summary, fmt.Sprintf("regular %d", counts[len(rpc.ChannelPrefixes)]))
+
+	var lines []string
+	lines = append(lines, fmt.Sprintf("  %d total: %s", len(members), tview.Escape(strings.Join(summary, " "))))
+	rank := -1
+	for _, member := range members {
+		if member.Rank() != rank {
+			rank = member.Rank()
+			group := "Regular"
+			if rank < len(rpc.ChannelPrefixes) {
+				group = fmt.Sprintf("%s (%s)", rpc.ChannelPrefixes[rank].Description, rpc.ChannelPrefixes[rank].Symbol)
+			}
+			lines = append(lines, fmt.Sprintf("  [yellow]%s - %d[white]", tview.Escape(group), counts[rank]))
+		}
+
+		userHost := ""
+		if member.Username != "" && member.Hostname != "" {
+			userHost = fmt.Sprintf(" (%s@%s)", member.Username, member.Hostname)
+		} else if member.Username != "" && member.IP != "" {
+			userHost = fmt.Sprintf(" (%s@%s)", member.Username, member.IP)
+		}
+		lines = append(lines, "    [blue]"+tview.Escape(member.Prefix()+member.Nick)+"[white]"+tview.Escape(userHost))
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
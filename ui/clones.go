@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// clonePreviewUsers is how many users of a group a ban or kill preview lists
+const clonePreviewUsers = 15
+
+// remoteClonesPage shows the connected users sharing an IP, network or account,
+// largest groups first, with actions to ban or kill a whole group
+func remoteClonesPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	var users []rpc.UserInfo
+	var groups []rpc.CloneGroup
+	grouping := 0
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	groupsList := tview.NewList()
+	groupsList.SetBorder(true)
+	groupsList.SetTitle("Clones")
+	groupsList.SetBorderColor(tcell.ColorBlue)
+
+	groupDetailsView := tview.NewTextView()
+	groupDetailsView.SetBorder(true)
+	groupDetailsView.SetTitle("Group Details")
+	groupDetailsView.SetDynamicColors(true)
+	groupDetailsView.SetWordWrap(true)
+	groupDetailsView.SetText("Loading users...")
+
+	showGroupDetails := func(index int) {
+		if index < 0 || index >= len(groups) {
+			groupDetailsView.SetText(fmt.Sprintf("No clones by %s.", rpc.CloneGroupings[grouping]))
+			return
+		}
+		groupDetailsView.SetText(formatCloneGroupDetails(groups[index]))
+	}
+
+	// Group the users again and rebuild the list
+	renderGroups := func() {
+		by := rpc.CloneGroupings[grouping]
+		groups = rpc.GroupClones(users, by)
+		groupsList.SetTitle(fmt.Sprintf("Clones (%d groups of %d users) - by %s", len(groups), len(users), by))
+		groupsList.Clear()
+		for _, group := range groups {
+			var nicks []string
+			for _, user := range group.Users {
+				nicks = append(nicks, user.Nick)
+			}
+			mainText := fmt.Sprintf("%4d  %s", len(group.Users), tview.Escape(group.Key))
+			groupsList.AddItem(mainText, "      "+tview.Escape(strings.Join(nicks, ", ")), 0, nil)
+		}
+		if len(groups) > 0 {
+			groupsList.SetCurrentItem(0)
+		}
+		showGroupDetails(groupsList.GetCurrentItem())
+	}
+
+	loadUsers := func() {
+		groupDetailsView.SetText("Loading users...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					groupDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			newUsers, err := client.GetUsers()
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					groupDetailsView.SetText(fmt.Sprintf("Error fetching users: %v", err))
+					return
+				}
+				users = newUsers
+				renderGroups()
+			})
+		}()
+	}
+
+	groupsList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showGroupDetails(index)
+	})
+
+	selectedGroup := func() (rpc.CloneGroup, bool) {
+		index := groupsList.GetCurrentItem()
+		if index < 0 || index >= len(groups) {
+			return rpc.CloneGroup{}, false
+		}
+		return groups[index], true
+	}
+
+	// Both actions show the users they hit before anything is done
+	banGroup := func() {
+		group, ok := selectedGroup()
+		if !ok {
+			return
+		}
+		mask := group.BanMask()
+		preview := fmt.Sprintf("Ban %s?\n\n%s", mask, formatCloneGroupPreview(group))
+		showConfirmModal(pages, "clone_ban_preview_modal", preview, func() {
+			showAddServerBanModal(app, pages, config, mask, loadUsers)
+		})
+	}
+
+	killGroup := func() {
+		group, ok := selectedGroup()
+		if !ok {
+			return
+		}
+		showInputModal(app, pages, fmt.Sprintf("Kill %d users of %s", len(group.Users), group.Key), "Reason:", "", "", func(reason string, _ bool) {
+			question := fmt.Sprintf("Kill these users (%s)?\n\n%s", reason, formatCloneGroupPreview(group))
+			runRPCAction(app, pages, config, question, func(client *rpc.RPCClient) error {
+				var failed []string
+				var lastErr error
+				for _, user := range group.Users {
+					if err := client.KillUser(user.Nick, reason); err != nil {
+						failed = append(failed, user.Nick)
+						lastErr = err
+					}
+				}
+				if lastErr != nil {
+					return fmt.Errorf("failed to kill %s: %w", strings.Join(failed, ", "), lastErr)
+				}
+				return nil
+			}, loadUsers)
+		})
+	}
+
+	cycleGrouping := func() {
+		grouping = (grouping + 1) % len(rpc.CloneGroupings)
+		renderGroups()
+	}
+
+	back := func() {
+		pages.RemovePage("remote_clones")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	groupsList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'b':
+			banGroup()
+			return nil
+		case 'k':
+			killGroup()
+			return nil
+		case 'g':
+			cycleGrouping()
+			return nil
+		case 'r':
+			loadUsers()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(groupsList, 0, 2, true)
+	contentFlex.AddItem(groupDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Ban Group").SetSelectedFunc(banGroup), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Kill Group").SetSelectedFunc(killGroup), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Group By").SetSelectedFunc(cycleGrouping), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadUsers), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("b: Ban Group | k: Kill Group | g: Group By (ip/network/account) | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_clones", flex, true, true)
+	app.SetFocus(groupsList)
+
+	loadUsers()
+}
+
+func formatCloneGroupDetails(group rpc.CloneGroup) string {
+	var members []string
+	for _, user := range group.Users {
+		account := user.Account
+		if account == "" || account == "none" {
+			account = "-"
+		}
+		members = append(members, fmt.Sprintf("  [blue]%s[white] %s@%s\n    %s, account %s, %s",
+			tview.Escape(user.Nick), tview.Escape(user.Username), tview.Escape(user.Hostname), user.IP, tview.Escape(account), user.Servername))
+	}
+
+	return fmt.Sprintf(
+		"[green]Grouped By:[white]\n  %s\n"+
+			"[green]Shared:[white]\n  %s\n"+
+			"[green]Ban Mask:[white]\n  %s\n"+
+			"[green]Users (%d):[white]\n%s",
+		group.By, tview.Escape(group.Key), tview.Escape(group.BanMask()), len(group.Users), strings.Join(members, "\n"))
+}
+
+// formatCloneGroupPreview lists the users an action on group hits, for a confirmation
+func formatCloneGroupPreview(group rpc.CloneGroup) string {
+	var lines []string
+	for i, user := range group.Users {
+		if i == clonePreviewUsers {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(group.Users)-i))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s (%s@%s)", user.Nick, user.Username, user.IP))
+	}
+	return fmt.Sprintf("%d users:\n%s", len(group.Users), strings.Join(lines, "\n"))
+}
@@ -0,0 +1,121 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"utui/rpc"
+
+	"github.com/rivo/tview"
+)
+
+// liveFlushInterval is how often received events are applied to a live pane
+const liveFlushInterval = 250 * time.Millisecond
+
+// stopLiveView stops the live updates of the Users or Channels pane shown last
+var stopLiveView func()
+
+// liveView keeps a pane current from subscribed events and shows in its status
+// line how long ago it was last brought up to date
+type liveView struct {
+	status  *tview.TextView
+	keys    string
+	updated time.Time
+	err     error
+	stopped bool
+}
+
+// startLiveView subscribes to rpc.LiveSources on the shared session and calls
+// onEvents with the events received, in batches. onEvents runs off the UI
+// goroutine, so that applying them to a large network does not hold up drawing.
+// When events were lost, what was built from them is off: reload is called on
+// the UI goroutine instead, to load the pane again. It stops the live view
+// started before it.
+func startLiveView(app *tview.Application, config *rpc.RPCConfig, status *tview.TextView, keys string, onEvents func(events []rpc.Event), reload func()) *liveView {
+	if stopLiveView != nil {
+		stopLiveView()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	view := &liveView{status: status, keys: keys}
+	stopLiveView = func() {
+		cancel()
+		view.stopped = true
+	}
+	view.render()
+
+	go func() {
+		events, err := rpc.Sessions.Get(config).Subscribe(ctx, rpc.LiveSources)
+		if err != nil {
+			app.QueueUpdateDraw(func() {
+				view.err = err
+				view.render()
+			})
+			return
+		}
+
+		ticker := time.NewTicker(liveFlushInterval)
+		defer ticker.Stop()
+
+		var batch []rpc.Event
+		lastDraw := time.Now()
+		for {
+			select {
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event.Type == rpc.EventResync {
+					app.QueueUpdateDraw(func() {
+						if !view.stopped {
+							reload()
+						}
+					})
+					return
+				}
+				batch = append(batch, event)
+			case <-ticker.C:
+				// Without events, only the age in the status line needs a redraw, once a second
+				if len(batch) == 0 && time.Since(lastDraw) < time.Second {
+					continue
+				}
+				lastDraw = time.Now()
+				applied := len(batch) > 0
+				if applied && ctx.Err() == nil {
+					onEvents(batch)
+				}
+				batch = nil
+				app.QueueUpdateDraw(func() {
+					if view.stopped {
+						return
+					}
+					// The pane is only up to date once it was loaded
+					if applied && !view.updated.IsZero() {
+						view.updated = time.Now()
+					}
+					view.render()
+				})
+			}
+		}
+	}()
+
+	return view
+}
+
+// touch marks the pane as up to date
+func (v *liveView) touch() {
+	v.updated = time.Now()
+	v.render()
+}
+
+func (v *liveView) render() {
+	age := "loading..."
+	if !v.updated.IsZero() {
+		age = fmt.Sprintf("updated %s ago", time.Since(v.updated).Truncate(time.Second))
+	}
+	state := "[green]live[-]"
+	if v.err != nil {
+		state = fmt.Sprintf("[red]not live: %v[-]", tview.Escape(v.err.Error()))
+	}
+	v.status.SetText(fmt.Sprintf(" %s (%s) | r: Refresh | %s", age, state, v.keys))
+}
@@ -0,0 +1,86 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/rivo/tview"
+)
+
+var logExportFormats = []rpc.LogExportFormat{rpc.ExportJSONL, rpc.ExportCSV, rpc.ExportText}
+
+// showLogExportModal asks for a format and file name and writes entries to it
+func showLogExportModal(app *tview.Application, pages *tview.Pages, entries []*rpc.FileLogEntry) {
+	if len(entries) == 0 {
+		showMessageModal(pages, "log_export_result_modal", "There are no log entries to export. Change the filters first.")
+		return
+	}
+
+	dir, err := os.UserHomeDir()
+	if err != nil {
+		dir = "."
+	}
+	baseName := filepath.Join(dir, "utui-logs-"+time.Now().Format("20060102-150405"))
+	format := rpc.ExportJSONL
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(fmt.Sprintf("Export %d Log Entries", len(entries)))
+	form.AddInputField("File", baseName+"."+string(format), 50, nil, nil)
+	form.AddInputField("CSV columns", strings.Join(rpc.DefaultLogExportColumns, ","), 50, nil, nil)
+	form.AddDropDown("Format", []string{"JSONL (raw log lines)", "CSV", "Text"}, 0, func(option string, index int) {
+		if index < 0 || index >= len(logExportFormats) {
+			return
+		}
+		// Keep the extension in line with the format
+		fileInput := form.GetFormItemByLabel("File").(*tview.InputField)
+		path := fileInput.GetText()
+		if ext := filepath.Ext(path); ext == "."+string(format) {
+			fileInput.SetText(strings.TrimSuffix(path, ext) + "." + string(logExportFormats[index]))
+		}
+		format = logExportFormats[index]
+	})
+
+	helpView := tview.NewTextView()
+	helpView.SetDynamicColors(true)
+	helpView.SetWordWrap(true)
+	helpView.SetText(" [gray]Columns: " + strings.Join(rpc.LogExportColumns, ", ") + "[-]")
+
+	closeModal := func() {
+		pages.RemovePage("log_export_modal")
+	}
+
+	form.AddButton("Export", func() {
+		path := strings.TrimSpace(form.GetFormItemByLabel("File").(*tview.InputField).GetText())
+		if path == "" {
+			helpView.SetText(" [red]Enter a file name[-]")
+			return
+		}
+		if strings.HasPrefix(path, "~/") {
+			path = filepath.Join(dir, path[2:])
+		}
+		columns := splitList(form.GetFormItemByLabel("CSV columns").(*tview.InputField).GetText())
+		for i := range columns {
+			columns[i] = strings.ToLower(columns[i])
+		}
+
+		if err := rpc.ExportLogEntries(path, entries, format, columns); err != nil {
+			helpView.SetText(fmt.Sprintf(" [red]Export failed: %s[-]", tview.Escape(err.Error())))
+			return
+		}
+		closeModal()
+		showMessageModal(pages, "log_export_result_modal", fmt.Sprintf("Exported %d log entries to %s", len(entries), path))
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+
+	modalFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	modalFlex.AddItem(form, 0, 1, true)
+	modalFlex.AddItem(helpView, 2, 0, false)
+
+	pages.AddPage("log_export_modal", centeredModal(modalFlex, 72, 16), true, true)
+	app.SetFocus(form)
+}
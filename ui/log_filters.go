@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showLogFilterModal edits the field filters of filter, levels and search are left as they are
+func showLogFilterModal(app *tview.Application, pages *tview.Pages, filter rpc.LogFilter, onApply func(rpc.LogFilter)) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Log Filters")
+	form.AddInputField("Subsystems", strings.Join(filter.Subsystems, ", "), 30, nil, nil)
+	form.AddInputField("Event IDs", strings.Join(filter.EventIDs, ", "), 30, nil, nil)
+	form.AddInputField("Log sources", strings.Join(filter.LogSources, ", "), 30, nil, nil)
+	form.AddInputField("Client IP", filter.ClientIP, 30, nil, nil)
+	form.AddInputField("Client nick", filter.ClientNick, 30, nil, nil)
+	form.AddInputField("From", filter.From, 30, nil, nil)
+	form.AddInputField("To", filter.To, 30, nil, nil)
+
+	errorView := tview.NewTextView()
+	errorView.SetDynamicColors(true)
+	errorView.SetText(" [gray]Lists are comma separated and may use * wildcards, the IP may be a CIDR range. Times: 15m, 2h, today, 2024-05-14 13:00[-]")
+	errorView.SetWordWrap(true)
+
+	text := func(label string) string {
+		return strings.TrimSpace(form.GetFormItemByLabel(label).(*tview.InputField).GetText())
+	}
+
+	closeModal := func() {
+		pages.RemovePage("log_filter_modal")
+	}
+
+	form.AddButton("Apply", func() {
+		edited := filter
+		edited.Subsystems = splitList(text("Subsystems"))
+		edited.EventIDs = splitList(text("Event IDs"))
+		edited.LogSources = splitList(text("Log sources"))
+		edited.ClientIP = text("Client IP")
+		edited.ClientNick = text("Client nick")
+		edited.From = text("From")
+		edited.To = text("To")
+
+		if _, err := edited.Compile(time.Now()); err != nil {
+			errorView.SetText(fmt.Sprintf(" [red]%v[-]", err))
+			return
+		}
+		closeModal()
+		onApply(edited)
+	})
+	form.AddButton("Clear", func() {
+		cleared := rpc.LogFilter{Levels: filter.Levels, Search: filter.Search}
+		closeModal()
+		onApply(cleared)
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+
+	modalFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	modalFlex.AddItem(form, 0, 1, true)
+	modalFlex.AddItem(errorView, 3, 0, false)
+
+	pages.AddPage("log_filter_modal", centeredModal(modalFlex, 60, 25), true, true)
+	app.SetFocus(form)
+}
+
+// showLogPresetsModal lists the saved filter presets. Enter loads one, d deletes
+// it, and the current filter can be saved under a new name.
+func showLogPresetsModal(app *tview.Application, pages *tview.Pages, current rpc.LogFilter, onLoad func(rpc.LogFilter)) {
+	presets, err := rpc.LoadLogFilterPresets()
+	if err != nil {
+		showMessageModal(pages, "log_presets_error_modal", fmt.Sprintf("Error loading presets: %v", err))
+		return
+	}
+
+	presetList := tview.NewList()
+	presetList.SetBorder(true)
+	presetList.SetTitle("Filter Presets")
+
+	statusView := tview.NewTextView()
+	statusView.SetDynamicColors(true)
+
+	closeModal := func() {
+		pages.RemovePage("log_presets_modal")
+	}
+
+	var names []string
+	refresh := func() {
+		names = names[:0]
+		for name := range presets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		presetList.Clear()
+		for _, name := range names {
+			presetList.AddItem(tview.Escape(name), "  "+tview.Escape(formatLogFilterLine(presets[name])), 0, nil)
+		}
+		if len(names) == 0 {
+			statusView.SetText(" No presets saved yet")
+		}
+	}
+	refresh()
+
+	presetList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if index < len(names) {
+			closeModal()
+			onLoad(presets[names[index]])
+		}
+	})
+
+	nameInput := tview.NewInputField().SetLabel("Save current as: ").SetFieldWidth(30)
+	save := func() {
+		name := strings.TrimSpace(nameInput.GetText())
+		if name == "" {
+			statusView.SetText(" [red]Enter a name for the preset[-]")
+			return
+		}
+		presets[name] = current
+		if err := rpc.SaveLogFilterPresets(presets); err != nil {
+			statusView.SetText(fmt.Sprintf(" [red]Error saving presets: %v[-]", err))
+			return
+		}
+		nameInput.SetText("")
+		refresh()
+		statusView.SetText(fmt.Sprintf(" Saved preset %s", tview.Escape(name)))
+	}
+	nameInput.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			save()
+		}
+		app.SetFocus(presetList)
+	})
+
+	presetList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Rune() == 'd' || event.Key() == tcell.KeyDelete:
+			index := presetList.GetCurrentItem()
+			if index < 0 || index >= len(names) {
+				return nil
+			}
+			name := names[index]
+			showConfirmModal(pages, "log_preset_delete_modal", fmt.Sprintf("Delete preset %s?", name), func() {
+				delete(presets, name)
+				if err := rpc.SaveLogFilterPresets(presets); err != nil {
+					statusView.SetText(fmt.Sprintf(" [red]Error saving presets: %v[-]", err))
+					return
+				}
+				refresh()
+				statusView.SetText(fmt.Sprintf(" Deleted preset %s", tview.Escape(name)))
+				app.SetFocus(presetList)
+			})
+			return nil
+		case event.Rune() == 's':
+			app.SetFocus(nameInput)
+			return nil
+		case event.Rune() == 'c':
+			closeModal()
+			return nil
+		}
+		return event
+	})
+
+	modalFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	modalFlex.AddItem(presetList, 0, 1, true)
+	modalFlex.AddItem(nameInput, 1, 0, false)
+	modalFlex.AddItem(statusView, 1, 0, false)
+	modalFlex.AddItem(CreateFooter("Enter: Load | s: Save Current | d: Delete | c: Close"), 3, 0, false)
+
+	pages.AddPage("log_presets_modal", centeredModal(modalFlex, 70, 22), true, true)
+	app.SetFocus(presetList)
+}
+
+// formatLogFilter describes the field filters of filter, one per line
+func formatLogFilter(filter rpc.LogFilter) string {
+	var lines []string
+	add := func(label, value string) {
+		if value != "" {
+			lines = append(lines, fmt.Sprintf("[green]%s:[white] %s", label, tview.Escape(value)))
+		}
+	}
+	add("Subsystems", strings.Join(filter.Subsystems, ", "))
+	add("Event IDs", strings.Join(filter.EventIDs, ", "))
+	add("Log sources", strings.Join(filter.LogSources, ", "))
+	add("Client IP", filter.ClientIP)
+	add("Client nick", filter.ClientNick)
+	add("From", filter.From)
+	add("To", filter.To)
+	if len(lines) == 0 {
+		return "None"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatLogFilterLine describes a whole filter on a single line
+func formatLogFilterLine(filter rpc.LogFilter) string {
+	var parts []string
+	if len(filter.Levels) > 0 {
+		parts = append(parts, "levels="+strings.Join(filter.Levels, ","))
+	}
+	if len(filter.Subsystems) > 0 {
+		parts = append(parts, "subsystems="+strings.Join(filter.Subsystems, ","))
+	}
+	if len(filter.EventIDs) > 0 {
+		parts = append(parts, "events="+strings.Join(filter.EventIDs, ","))
+	}
+	if len(filter.LogSources) > 0 {
+		parts = append(parts, "sources="+strings.Join(filter.LogSources, ","))
+	}
+	if filter.ClientIP != "" {
+		parts = append(parts, "ip="+filter.ClientIP)
+	}
+	if filter.ClientNick != "" {
+		parts = append(parts, "nick="+filter.ClientNick)
+	}
+	if filter.From != "" || filter.To != "" {
+		parts = append(parts, fmt.Sprintf("time=%s..%s", filter.From, filter.To))
+	}
+	if filter.Search != "" {
+		parts = append(parts, fmt.Sprintf("search=%q", filter.Search))
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,195 @@
+package ui
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+const logHistoryPageSize = 500
+
+// remoteLogHistoryPage searches the whole ircd.json.log through the log index
+func remoteLogHistoryPage(app *tview.Application, pages *tview.Pages, buildDir string) {
+	logFilePath := filepath.Join(buildDir, "logs", "ircd.json.log")
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Search")
+	form.AddInputField("From", "", 22, nil, nil)
+	form.AddInputField("To", "", 22, nil, nil)
+	form.AddInputField("Levels", "", 22, nil, nil)
+	form.AddInputField("Subsystems", "", 22, nil, nil)
+	form.AddInputField("Event IDs", "", 22, nil, nil)
+
+	helpView := tview.NewTextView()
+	helpView.SetDynamicColors(true)
+	helpView.SetWordWrap(true)
+	helpView.SetText("[yellow]Times:[-] 2024-05-14, 2024-05-14 13:00, today, yesterday, tuesday, 2h, 3d\n" +
+		"[yellow]Lists:[-] comma separated, event IDs may end in * (LINK_*)")
+
+	resultsList := tview.NewList()
+	resultsList.ShowSecondaryText(false)
+	resultsList.SetBorder(true)
+	resultsList.SetTitle("Results")
+	resultsList.SetHighlightFullLine(true)
+	resultsList.SetSelectedTextColor(tcell.ColorBlack)
+	resultsList.SetSelectedBackgroundColor(tcell.ColorYellow)
+
+	statusView := tview.NewTextView()
+	statusView.SetDynamicColors(true)
+	statusView.SetText(" Enter a query and press Search")
+
+	// Paging state, only touched on the UI goroutine
+	var query rpc.LogQuery
+	var cursors []int64 // Before of every page shown so far, the last one is the current page
+	var current *rpc.LogPage
+	searching := false
+
+	showPage := func(before int64) {
+		if searching {
+			return
+		}
+		searching = true
+		q := query
+		q.Before = before
+		statusView.SetText(" [yellow]Searching...[-]")
+
+		go func() {
+			start := time.Now()
+			index, err := rpc.GetLogIndex(logFilePath)
+			if err == nil {
+				app.QueueUpdateDraw(func() {
+					statusView.SetText(" [yellow]Updating the log index, slow the first time on a large log...[-]")
+				})
+				err = index.Update()
+			}
+			var page *rpc.LogPage
+			if err == nil {
+				page, err = index.Query(q)
+			}
+			elapsed := time.Since(start)
+
+			app.QueueUpdateDraw(func() {
+				searching = false
+				if err != nil {
+					statusView.SetText(fmt.Sprintf(" [red]Search failed:[-] %v", err))
+					return
+				}
+				current = page
+				resultsList.Clear()
+				for _, entry := range page.Entries {
+					timestamp := parseLogTimestamp(entry.Timestamp).Format("2006-01-02 15:04:05")
+					resultsList.AddItem(fmt.Sprintf("[%s] %s%s[-]: %s: %s: %s", timestamp, logLevelColor(entry.Level),
+						entry.Level, entry.Subsystem, entry.EventID, tview.Escape(entry.Msg)), "", 0, nil)
+				}
+				if len(page.Entries) > 0 {
+					resultsList.SetCurrentItem(len(page.Entries) - 1)
+				}
+				resultsList.SetTitle(fmt.Sprintf("Results (page %d)", len(cursors)))
+
+				older := ""
+				if page.HasMore {
+					older = " | n: Older"
+				}
+				newer := ""
+				if len(cursors) > 1 {
+					newer = " | p: Newer"
+				}
+				statusView.SetText(fmt.Sprintf(" %d entries, %d index records scanned in %s%s%s | Enter: Inspect",
+					len(page.Entries), page.Scanned, elapsed.Round(time.Millisecond), older, newer))
+				app.SetFocus(resultsList)
+			})
+		}()
+	}
+
+	search := func() {
+		now := time.Now()
+		from, _, err := rpc.ParseTimeRange(form.GetFormItemByLabel("From").(*tview.InputField).GetText(), now)
+		if err != nil {
+			statusView.SetText(fmt.Sprintf(" [red]From:[-] %v", err))
+			return
+		}
+		_, to, err := rpc.ParseTimeRange(form.GetFormItemByLabel("To").(*tview.InputField).GetText(), now)
+		if err != nil {
+			statusView.SetText(fmt.Sprintf(" [red]To:[-] %v", err))
+			return
+		}
+		query = rpc.LogQuery{
+			From:       from,
+			To:         to,
+			Levels:     splitList(form.GetFormItemByLabel("Levels").(*tview.InputField).GetText()),
+			Subsystems: splitList(form.GetFormItemByLabel("Subsystems").(*tview.InputField).GetText()),
+			EventIDs:   splitList(form.GetFormItemByLabel("Event IDs").(*tview.InputField).GetText()),
+			Limit:      logHistoryPageSize,
+		}
+		cursors = []int64{0}
+		showPage(0)
+	}
+
+	back := func() {
+		pages.RemovePage("remote_log_history")
+		pages.SwitchToPage("remote_log_streaming")
+	}
+
+	form.AddButton("Search", search)
+	form.AddButton("Back", back)
+
+	resultsList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if current != nil && index >= 0 && index < len(current.Entries) {
+			showLogEntryInspector(pages, current.Entries[index], "Log Entry")
+		}
+	})
+
+	resultsList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Rune() == 'n':
+			if current != nil && current.HasMore && !searching {
+				cursors = append(cursors, current.Next)
+				showPage(current.Next)
+			}
+			return nil
+		case event.Rune() == 'p':
+			if len(cursors) > 1 && !searching {
+				cursors = cursors[:len(cursors)-1]
+				showPage(cursors[len(cursors)-1])
+			}
+			return nil
+		case event.Rune() == 'f':
+			app.SetFocus(form)
+			return nil
+		}
+		return event
+	})
+
+	leftFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	leftFlex.AddItem(form, 0, 1, true)
+	leftFlex.AddItem(helpView, 5, 0, false)
+
+	rightFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	rightFlex.AddItem(resultsList, 0, 1, false)
+	rightFlex.AddItem(statusView, 1, 0, false)
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(leftFlex, 40, 0, true)
+	contentFlex.AddItem(rightFlex, 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(CreateFooter("f: Back to Form | n/p: Older/Newer Page | Enter: Inspect | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_log_history", flex, true, true)
+	app.SetFocus(form)
+}
+
+// splitList splits comma or space separated input into its items
+func splitList(text string) []string {
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
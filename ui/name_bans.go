@@ -0,0 +1,201 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func remoteNameBansPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	var bans []rpc.ServerBanInfo
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	bansList := tview.NewList()
+	bansList.SetBorder(true)
+	bansList.SetTitle("Name Bans")
+	bansList.SetBorderColor(tcell.ColorBlue)
+
+	banDetailsView := tview.NewTextView()
+	banDetailsView.SetBorder(true)
+	banDetailsView.SetTitle("Name Ban Details")
+	banDetailsView.SetDynamicColors(true)
+	banDetailsView.SetWordWrap(true)
+	banDetailsView.SetText("Loading name bans...")
+
+	showBanDetails := func(index int) {
+		if index < 0 || index >= len(bans) {
+			banDetailsView.SetText("No name bans.")
+			return
+		}
+		banDetailsView.SetText(formatServerBanDetails(bans[index]))
+	}
+
+	renderBans := func() {
+		sortServerBans(bans, "")
+		bansList.SetTitle(fmt.Sprintf("Name Bans (%d)", len(bans)))
+		bansList.Clear()
+		for _, ban := range bans {
+			secondaryText := fmt.Sprintf("  by %s, expires %s - %s", tview.Escape(ban.Setby), formatBanExpiry(ban), tview.Escape(ban.Reason))
+			bansList.AddItem(tview.Escape(ban.Name), secondaryText, 0, nil)
+		}
+		if len(bans) > 0 {
+			bansList.SetCurrentItem(0)
+		}
+		showBanDetails(bansList.GetCurrentItem())
+	}
+
+	loadBans := func() {
+		banDetailsView.SetText("Loading name bans...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					banDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			newBans, err := client.GetNameBans()
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					banDetailsView.SetText(fmt.Sprintf("Error fetching name bans: %v", err))
+					return
+				}
+				bans = newBans
+				renderBans()
+			})
+		}()
+	}
+
+	bansList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showBanDetails(index)
+	})
+
+	addBan := func() {
+		showAddNameBanModal(app, pages, config, loadBans)
+	}
+
+	deleteBan := func() {
+		index := bansList.GetCurrentItem()
+		if index < 0 || index >= len(bans) {
+			return
+		}
+		ban := bans[index]
+		if ban.SetInConfig {
+			showMessageModal(pages, "name_ban_config_modal", fmt.Sprintf("The name ban on %s is set in the configuration file and cannot be removed over RPC.", ban.Name))
+			return
+		}
+		showConfirmModal(pages, "name_ban_delete_modal", fmt.Sprintf("Remove the name ban on %s?", ban.Name), func() {
+			go func() {
+				client, err := rpc.Sessions.Get(config).Client()
+				if err == nil {
+					err = client.DeleteNameBan(ban.Name)
+				}
+				app.QueueUpdateDraw(func() {
+					if err != nil {
+						showMessageModal(pages, "name_ban_error_modal", fmt.Sprintf("Error removing name ban: %v", err))
+						return
+					}
+					loadBans()
+				})
+			}()
+		})
+	}
+
+	back := func() {
+		pages.RemovePage("remote_name_bans")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	bansList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			addBan()
+			return nil
+		case 'd':
+			deleteBan()
+			return nil
+		case 'r':
+			loadBans()
+			return nil
+		}
+		if event.Key() == tcell.KeyDelete {
+			deleteBan()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(bansList, 0, 2, true)
+	contentFlex.AddItem(banDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Add Name Ban").SetSelectedFunc(addBan), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Delete Name Ban").SetSelectedFunc(deleteBan), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadBans), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("a: Add | d: Delete | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_name_bans", flex, true, true)
+	app.SetFocus(bansList)
+
+	loadBans()
+}
+
+// showAddNameBanModal shows the form for reserving a nick or channel mask
+func showAddNameBanModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, onAdded func()) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Add Name Ban")
+	form.SetBackgroundColor(tcell.ColorDefault)
+
+	form.AddInputField("Nick or channel:", "", 40, nil, nil)
+	form.AddInputField("Duration:", "0", 20, nil, nil)
+	form.AddInputField("Reason:", "", 40, nil, nil)
+
+	form.AddButton("Add", func() {
+		name := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		duration := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+		reason := strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText())
+
+		if name == "" || reason == "" {
+			showMessageModal(pages, "name_ban_validation_modal", "Nick or channel mask and reason are required.")
+			return
+		}
+
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err == nil {
+				err = client.AddNameBan(name, duration, reason)
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "name_ban_error_modal", fmt.Sprintf("Error adding name ban: %v", err))
+					return
+				}
+				pages.RemovePage("name_ban_add_modal")
+				if onAdded != nil {
+					onAdded()
+				}
+			})
+		}()
+	})
+
+	form.AddButton("Cancel", func() {
+		pages.RemovePage("name_ban_add_modal")
+	})
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("name_ban_add_modal", centeredModal(form, 64, 11), true, true)
+}
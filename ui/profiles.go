@@ -0,0 +1,291 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// profileStatusInterval is how often the profiles page shows the connection state again
+const profileStatusInterval = time.Second
+
+// stopProfileStatus stops the status updates of the profiles page shown last
+var stopProfileStatus func()
+
+// profileState returns the connection state of the session of profile, without connecting
+func profileState(profile *rpc.RPCConfig) rpc.StateEvent {
+	if session := rpc.Sessions.Peek(profile); session != nil {
+		return session.State()
+	}
+	return rpc.StateEvent{State: rpc.StateDisconnected}
+}
+
+// remoteProfilesPage lists the connection profiles with the state of their
+// connections, to switch the remote control menu to another server or network.
+// local is the profile detected for the installation, listed while it is not nil.
+func remoteProfilesPage(app *tview.Application, pages *tview.Pages, config, local *rpc.RPCConfig, buildDir string) {
+	if stopProfileStatus != nil {
+		stopProfileStatus()
+	}
+
+	var profiles []rpc.RPCConfig
+	active := config.Name
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	profilesList := tview.NewList()
+	profilesList.SetBorder(true)
+	profilesList.SetTitle("Profiles")
+	profilesList.SetBorderColor(tcell.ColorBlue)
+
+	profileDetailsView := tview.NewTextView()
+	profileDetailsView.SetBorder(true)
+	profileDetailsView.SetTitle("Profile Details")
+	profileDetailsView.SetDynamicColors(true)
+	profileDetailsView.SetWordWrap(true)
+
+	selectedProfile := func() (*rpc.RPCConfig, bool) {
+		index := profilesList.GetCurrentItem()
+		if index < 0 || index >= len(profiles) {
+			return nil, false
+		}
+		return &profiles[index], true
+	}
+
+	showProfileDetails := func() {
+		profile, ok := selectedProfile()
+		if !ok {
+			profileDetailsView.SetText("No profiles. Press a to add one.")
+			return
+		}
+		profileDetailsView.SetText(formatProfileDetails(profile, profile.Name == active))
+	}
+
+	// Show the state of every profile again, keeping the list as it is
+	showStates := func() {
+		for i := range profiles {
+			mainText, _ := profilesList.GetItemText(i)
+			profilesList.SetItemText(i, mainText, formatConnectionState(profileState(&profiles[i]), profiles[i].Target()))
+		}
+		showProfileDetails()
+	}
+
+	loadProfiles := func() {
+		loaded, current, err := rpc.LoadRPCProfiles(local)
+		if err != nil {
+			profileDetailsView.SetText(fmt.Sprintf("Error loading profiles: %v", err))
+			return
+		}
+		selected := active
+		if profile, ok := selectedProfile(); ok {
+			selected = profile.Name
+		}
+		profiles, active = loaded, current
+
+		profilesList.Clear()
+		profilesList.SetTitle(fmt.Sprintf("Profiles (%d)", len(profiles)))
+		for i, profile := range profiles {
+			name := tview.Escape(profile.Name)
+			if profile.Detected {
+				name += " (this server)"
+			}
+			mainText := "  " + name
+			if profile.Name == active {
+				mainText = "[green]* " + name + " (active)[-]"
+			}
+			profilesList.AddItem(mainText, "", 0, nil)
+			if profile.Name == selected {
+				profilesList.SetCurrentItem(i)
+			}
+		}
+		showStates()
+	}
+
+	profilesList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showProfileDetails()
+	})
+
+	// The remote control menu is built again for the profile switched to.
+	// Connections of other profiles stay open until they are disconnected.
+	switchProfile := func() {
+		profile, ok := selectedProfile()
+		if !ok {
+			return
+		}
+		if err := rpc.SetActiveRPCProfile(profile.Name); err != nil {
+			showMessageModal(pages, "profile_error_modal", fmt.Sprintf("Error switching profile: %v", err))
+			return
+		}
+		reopenRemoteControlMenu(app, pages, buildDir)
+	}
+
+	addProfile := func() {
+		showRPCSetupModal(app, pages, buildDir, nil)
+	}
+
+	// The profile of the local socket is not saved, there is nothing to edit or delete
+	detectedMessage := "This profile is detected from the RPC socket of the local installation and needs no settings."
+
+	editProfile := func() {
+		profile, ok := selectedProfile()
+		if !ok {
+			return
+		}
+		if profile.Detected {
+			showMessageModal(pages, "profile_error_modal", detectedMessage)
+			return
+		}
+		edited := *profile
+		showRPCSetupModal(app, pages, buildDir, &edited)
+	}
+
+	deleteProfile := func() {
+		profile, ok := selectedProfile()
+		if !ok {
+			return
+		}
+		if profile.Detected {
+			showMessageModal(pages, "profile_error_modal", detectedMessage)
+			return
+		}
+		deleted := *profile
+		showConfirmModal(pages, "profile_delete_modal", fmt.Sprintf("Delete profile %s (%s)?", deleted.Name, deleted.Target()), func() {
+			rpc.Sessions.Remove(&deleted)
+			if err := rpc.DeleteRPCProfile(deleted.Name); err != nil {
+				showMessageModal(pages, "profile_error_modal", fmt.Sprintf("Error deleting profile: %v", err))
+				return
+			}
+			if deleted.Name == active {
+				// The menu belongs to the deleted profile, show the next one or the setup
+				reopenRemoteControlMenu(app, pages, buildDir)
+				return
+			}
+			loadProfiles()
+		})
+	}
+
+	connectProfile := func() {
+		if profile, ok := selectedProfile(); ok {
+			go rpc.Sessions.Get(profile).Client()
+		}
+	}
+
+	disconnectProfile := func() {
+		profile, ok := selectedProfile()
+		if !ok {
+			return
+		}
+		if profile.Name == active {
+			showMessageModal(pages, "profile_error_modal", "The active profile stays connected, switch to another profile first.")
+			return
+		}
+		rpc.Sessions.Remove(profile)
+		showStates()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopProfileStatus = cancel
+	go func() {
+		ticker := time.NewTicker(profileStatusInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				app.QueueUpdateDraw(func() {
+					if ctx.Err() == nil {
+						showStates()
+					}
+				})
+			}
+		}
+	}()
+
+	back := func() {
+		stopProfileStatus()
+		pages.RemovePage("remote_profiles")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	profilesList.SetSelectedFunc(func(int, string, string, rune) {
+		switchProfile()
+	})
+	profilesList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			addProfile()
+			return nil
+		case 'e':
+			editProfile()
+			return nil
+		case 'd':
+			deleteProfile()
+			return nil
+		case 'c':
+			connectProfile()
+			return nil
+		case 'x':
+			disconnectProfile()
+			return nil
+		case 'r':
+			loadProfiles()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(profilesList, 0, 2, true)
+	contentFlex.AddItem(profileDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Switch").SetSelectedFunc(switchProfile), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Add").SetSelectedFunc(addProfile), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Edit").SetSelectedFunc(editProfile), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Delete").SetSelectedFunc(deleteProfile), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("Enter: Switch | a: Add | e: Edit | d: Delete | c: Connect | x: Disconnect | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_profiles", flex, true, true)
+	app.SetFocus(profilesList)
+
+	loadProfiles()
+}
+
+func formatProfileDetails(profile *rpc.RPCConfig, active bool) string {
+	var details strings.Builder
+	details.WriteString(fmt.Sprintf("[green]Profile:[white]\n  %s\n", tview.Escape(profile.Name)))
+	if active {
+		details.WriteString("  [yellow]Active in the remote control menu[-]\n")
+	}
+	if profile.Socket != "" {
+		details.WriteString(fmt.Sprintf("[green]UNIX socket:[white]\n  %s\n", tview.Escape(profile.Socket)))
+		if profile.Detected {
+			details.WriteString("  [yellow]Detected for the local installation, no credentials needed[-]\n")
+		}
+		details.WriteString(fmt.Sprintf("[green]Connection:[white]\n %s\n", formatConnectionState(profileState(profile), profile.Target())))
+		return details.String()
+	}
+	details.WriteString(fmt.Sprintf("[green]WebSocket URL:[white]\n  %s\n", tview.Escape(profile.WSURL)))
+	details.WriteString(fmt.Sprintf("[green]Username:[white]\n  %s\n", tview.Escape(profile.Username)))
+	details.WriteString(fmt.Sprintf("[green]Password:[white]\n  %s\n", tview.Escape(profile.DescribePassword())))
+	if strings.HasPrefix(profile.WSURL, "wss://") {
+		details.WriteString(fmt.Sprintf("[green]TLS certificate:[white]\n  Verified %s\n", tview.Escape(profile.DescribeTLS())))
+	}
+	details.WriteString(fmt.Sprintf("[green]Connection:[white]\n %s\n", formatConnectionState(profileState(profile), profile.Target())))
+	return details.String()
+}
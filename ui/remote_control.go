@@ -1,13 +1,14 @@
 package ui
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 	"utui/rpc"
 
@@ -33,25 +34,50 @@ func CreateFooter(shortcuts string) *tview.TextView {
 	return footer
 }
 
-func parseLogTimestamp(timestampStr string) time.Time {
-	// Debug: log the timestamp string we're trying to parse
-	debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	fmt.Fprintf(debugFile, "[DEBUG] Parsing timestamp: '%s'\n", timestampStr)
-	debugFile.Close()
+// showMessageModal shows a modal with a single OK button that removes itself when pressed
+func showMessageModal(pages *tview.Pages, name, text string) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"OK"}).
+		SetDoneFunc(func(int, string) {
+			pages.RemovePage(name)
+		})
+	pages.AddPage(name, modal, true, true)
+}
+
+// showConfirmModal asks a yes/no question and calls onYes if confirmed
+func showConfirmModal(pages *tview.Pages, name, text string, onYes func()) {
+	modal := tview.NewModal().
+		SetText(text).
+		AddButtons([]string{"Yes", "No"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.RemovePage(name)
+			if buttonLabel == "Yes" {
+				onYes()
+			}
+		})
+	pages.AddPage(name, modal, true, true)
+}
 
+// centeredModal centers a primitive (usually a form) on the screen with a fixed size
+func centeredModal(p tview.Primitive, width, height int) *tview.Flex {
+	return tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tview.NewTextView(), 0, 1, false).
+		AddItem(tview.NewFlex().
+			AddItem(tview.NewTextView(), 0, 1, false).
+			AddItem(p, width, 0, true).
+			AddItem(tview.NewTextView(), 0, 1, false), height, 0, true).
+		AddItem(tview.NewTextView(), 0, 1, false)
+}
+
+func parseLogTimestamp(timestampStr string) time.Time {
 	// Try RFC3339 with nanoseconds first (format like "2025-11-10T02:31:41.077Z")
 	if t, err := time.Parse(time.RFC3339Nano, timestampStr); err == nil {
-		debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Successfully parsed as RFC3339Nano: %v\n", t)
-		debugFile.Close()
 		return t
 	}
 
 	// Try RFC3339
 	if t, err := time.Parse(time.RFC3339, timestampStr); err == nil {
-		debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Successfully parsed as RFC3339: %v\n", t)
-		debugFile.Close()
 		return t
 	}
 
@@ -61,9 +87,6 @@ func parseLogTimestamp(timestampStr string) time.Time {
 	}
 
 	// If all parsing fails, return current time
-	debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	fmt.Fprintf(debugFile, "[DEBUG] Failed to parse timestamp '%s', using current time\n", timestampStr)
-	debugFile.Close()
 	return time.Now()
 }
 
@@ -154,6 +177,52 @@ func formatJSONTree(data interface{}, indent string) string {
 }
 
 // isPrimitive checks if a value is a JSON primitive (string, number, bool, null)
+// showLogEntryInspector shows every field of a log entry as a tree
+func showLogEntryInspector(pages *tview.Pages, entry *rpc.FileLogEntry, title string) {
+	// Parse the raw JSON to get all fields including nested objects
+	var entryData map[string]interface{}
+	if err := json.Unmarshal([]byte(entry.RawJSON), &entryData); err != nil {
+		showMessageModal(pages, "json_error_modal", "Error parsing log entry JSON: "+err.Error())
+		return
+	}
+
+	// Format as tree structure
+	formattedText := formatJSONTree(entryData, "")
+
+	// Create a text view to display the formatted JSON
+	jsonView := tview.NewTextView()
+	jsonView.SetBorder(true).SetTitle(title)
+	jsonView.SetDynamicColors(true) // Enable color tags for syntax highlighting
+	jsonView.SetWordWrap(true)
+	jsonView.SetScrollable(true)
+	jsonView.SetText(formattedText)
+
+	// Create modal with the JSON view
+	jsonFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	jsonFlex.AddItem(jsonView, 0, 1, true)
+	closeBtn := tview.NewButton("Close").SetSelectedFunc(func() {
+		pages.RemovePage("json_inspect_modal")
+	})
+	jsonFlex.AddItem(closeBtn, 3, 0, false)
+
+	pages.AddPage("json_inspect_modal", jsonFlex, true, true)
+}
+
+// logLevelColor returns the color tag log lines of a level are shown in
+func logLevelColor(level string) string {
+	switch strings.ToLower(level) {
+	case "fatal", "error":
+		return "[red]"
+	case "warning", "warn":
+		return "[yellow]"
+	case "info":
+		return "[green]"
+	case "debug":
+		return "[blue]"
+	}
+	return "[white]"
+}
+
 func isPrimitive(value interface{}) bool {
 	switch value.(type) {
 	case string, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64, bool:
@@ -165,8 +234,12 @@ func isPrimitive(value interface{}) bool {
 	}
 }
 
+// RemoteControlMenuPage shows the remote control menu for the active profile.
+// The RPC socket of the installation in buildDir is used without any setup,
+// the setup modal is only shown when there is neither it nor a saved profile.
 func RemoteControlMenuPage(app *tview.Application, pages *tview.Pages, buildDir string) {
-	config, err := rpc.LoadRPCConfig()
+	local := rpc.DetectLocalRPC(buildDir)
+	config, err := rpc.LoadRPCConfig(local)
 
 	if err != nil {
 		// Error loading config
@@ -178,131 +251,273 @@ func RemoteControlMenuPage(app *tview.Application, pages *tview.Pages, buildDir
 			})
 		pages.AddPage("rpc_error_modal", errorModal, true, true)
 	} else if config == nil {
-		// No config exists, show setup form in modal
-		showRPCSetupModal(app, pages, buildDir)
+		// No profile exists, show setup form in modal
+		showRPCSetupModal(app, pages, buildDir, nil)
+	} else if legacy := legacyPasswordProfiles(); len(legacy) > 0 {
+		// Passwords from older versions are moved out of the config file first
+		showLegacyPasswordModal(app, pages, legacy, func() {
+			RemoteControlMenuPage(app, pages, buildDir)
+		})
 	} else {
-		// Config exists, show main remote control menu
-		flex := tview.NewFlex().SetDirection(tview.FlexRow)
-		showRemoteControlOptions(app, pages, config, flex, buildDir)
-		pages.AddPage("remote_control_menu", flex, true, true)
+		// Trying the known passphrases derives keys, which takes a moment
+		go func() {
+			locked := rpc.PasswordLocked(config)
+			app.QueueUpdateDraw(func() {
+				if locked {
+					// The password is encrypted, it is needed before anything is shown
+					showPassphraseModal(app, pages, config, func() {
+						RemoteControlMenuPage(app, pages, buildDir)
+					})
+					return
+				}
+				// Config exists, show main remote control menu
+				flex := tview.NewFlex().SetDirection(tview.FlexRow)
+				showRemoteControlOptions(app, pages, config, local, flex, buildDir)
+				pages.AddPage("remote_control_menu", flex, true, true)
+			})
+		}()
+	}
+}
+
+// legacyPasswordProfiles returns the profiles with a plain text password in the config file
+func legacyPasswordProfiles() []rpc.RPCConfig {
+	profiles, _, _ := rpc.LoadRPCProfiles(nil)
+	var legacy []rpc.RPCConfig
+	for _, profile := range profiles {
+		if profile.LegacyPassword != "" {
+			legacy = append(legacy, profile)
+		}
 	}
+	return legacy
 }
 
-func showRPCSetupModal(app *tview.Application, pages *tview.Pages, buildDir string) {
+// showRPCSetupModal asks for the connection settings of a profile, a new one
+// when existing is nil. The saved profile becomes the active one.
+func showRPCSetupModal(app *tview.Application, pages *tview.Pages, buildDir string, existing *rpc.RPCConfig) {
+	title := "RPC Profile Setup"
+	profile := rpc.RPCConfig{WSURL: "wss://127.0.0.1:8600/"}
+	if existing != nil {
+		title = fmt.Sprintf("Edit RPC Profile %s", existing.Name)
+		profile = *existing
+	} else if profiles, _, _ := rpc.LoadRPCProfiles(nil); len(profiles) == 0 {
+		profile.Name = "default"
+	}
+
 	setupForm := tview.NewForm()
-	setupForm.SetBorder(true).SetTitle("RPC Configuration Setup")
+	setupForm.SetBorder(true).SetTitle(title)
 	setupForm.SetBackgroundColor(tcell.ColorDefault)
 
-	setupForm.AddInputField("Username:", "", 30, nil, nil)
+	// The password itself is never saved, only where it comes from
+	source, reference := 0, ""
+	switch {
+	case profile.Env != "":
+		source, reference = 1, profile.Env
+	case profile.Command != "":
+		source, reference = 2, profile.Command
+	}
+
+	setupForm.AddInputField("Profile name:", profile.Name, 30, nil, nil)
+	setupForm.AddInputField("Username:", profile.Username, 30, nil, nil)
+	setupForm.AddDropDown("Password from:", passwordSources, source, nil)
 	setupForm.AddPasswordField("Password:", "", 30, '*', nil)
-	setupForm.AddInputField("WebSocket URL:", "wss://127.0.0.1:8600/", 40, nil, nil)
+	setupForm.AddPasswordField("Passphrase:", "", 30, '*', nil)
+	setupForm.AddInputField("Variable or command:", reference, 40, nil, nil)
+	setupForm.AddInputField("WebSocket URL:", profile.WSURL, 40, nil, nil)
+
+	tlsVerify, tlsValue := 0, ""
+	switch profile.Verify {
+	case rpc.TLSVerifyCAFile:
+		tlsVerify, tlsValue = 1, profile.CAFile
+	case rpc.TLSVerifyPin:
+		tlsVerify, tlsValue = 2, profile.SPKIPin
+	}
+	setupForm.AddDropDown("Verify TLS with:", tlsVerifyOptions, tlsVerify, nil)
+	setupForm.AddInputField("CA file or fingerprint:", tlsValue, 46, nil, nil)
+
+	// formConfig returns the profile as filled in, or what is missing. A
+	// password to encrypt is returned with its passphrase for withEncryptedPassword.
+	formConfig := func() (config *rpc.RPCConfig, password, passphrase, problem string) {
+		config = &rpc.RPCConfig{
+			Name:     strings.TrimSpace(setupForm.GetFormItem(0).(*tview.InputField).GetText()),
+			Username: setupForm.GetFormItem(1).(*tview.InputField).GetText(),
+			WSURL:    setupForm.GetFormItem(6).(*tview.InputField).GetText(),
+		}
+		if config.Name == "" || config.Username == "" || config.WSURL == "" {
+			return nil, "", "", "All fields are required. Please fill in profile name, username, and WebSocket URL."
+		}
 
-	setupForm.AddButton("Test Connection", func() {
-		// Get values from form fields
-		username := setupForm.GetFormItem(0).(*tview.InputField).GetText()
-		password := setupForm.GetFormItem(1).(*tview.InputField).GetText()
-		wsURL := setupForm.GetFormItem(2).(*tview.InputField).GetText()
-
-		testConfig := &rpc.RPCConfig{
-			Username: username,
-			Password: password,
-			WSURL:    wsURL,
+		source, _ := setupForm.GetFormItem(2).(*tview.DropDown).GetCurrentOption()
+		password = setupForm.GetFormItem(3).(*tview.InputField).GetText()
+		passphrase = setupForm.GetFormItem(4).(*tview.InputField).GetText()
+		reference := strings.TrimSpace(setupForm.GetFormItem(5).(*tview.InputField).GetText())
+		switch source {
+		case 0:
+			if password == "" && existing != nil && existing.Encrypted != "" {
+				config.Encrypted = existing.Encrypted // Unchanged
+				break
+			}
+			if password == "" || passphrase == "" {
+				return nil, "", "", "Please fill in the password and a passphrase to encrypt it with."
+			}
+		case 1:
+			if reference == "" {
+				return nil, "", "", "Please fill in the environment variable holding the password."
+			}
+			config.Env = reference
+			password = ""
+		case 2:
+			if reference == "" {
+				return nil, "", "", "Please fill in the command printing the password, like pass show irc/rpc."
+			}
+			config.Command = reference
+			password = ""
+		}
+
+		tlsVerify, _ := setupForm.GetFormItem(7).(*tview.DropDown).GetCurrentOption()
+		tlsValue := strings.TrimSpace(setupForm.GetFormItem(8).(*tview.InputField).GetText())
+		switch tlsVerify {
+		case 1:
+			if tlsValue == "" {
+				return nil, "", "", "Please fill in the CA file to verify the server certificate with."
+			}
+			config.TLSSettings = rpc.TLSSettings{Verify: rpc.TLSVerifyCAFile, CAFile: tlsValue}
+		case 2:
+			if tlsValue == "" {
+				return nil, "", "", "Please fill in the SPKI fingerprint, as printed by ./unrealircd spkifp on the server."
+			}
+			config.TLSSettings = rpc.TLSSettings{Verify: rpc.TLSVerifyPin, SPKIPin: tlsValue}
+		}
+		return config, password, passphrase, ""
+	}
+	showFormError := func(problem string) {
+		errorModal := tview.NewModal().
+			SetText(problem).
+			AddButtons([]string{"OK"}).
+			SetDoneFunc(func(int, string) {
+				pages.RemovePage("rpc_validation_error_modal")
+			})
+		pages.AddPage("rpc_validation_error_modal", errorModal, true, true)
+	}
+
+	// saveProfile stores newConfig and shows the remote control menu for it
+	saveProfile := func(newConfig *rpc.RPCConfig, onSaved func()) {
+		oldName := ""
+		if existing != nil {
+			oldName = existing.Name
 		}
-		if err := rpc.TestRPCConnection(testConfig); err != nil {
+		if err := rpc.SaveRPCProfile(oldName, newConfig); err != nil {
 			errorModal := tview.NewModal().
-				SetText(fmt.Sprintf("Connection test failed: %v", err)).
+				SetText(fmt.Sprintf("Error saving config: %v", err)).
 				AddButtons([]string{"OK"}).
 				SetDoneFunc(func(int, string) {
-					pages.RemovePage("rpc_test_error_modal")
+					pages.RemovePage("rpc_save_error_modal")
 				})
-			pages.AddPage("rpc_test_error_modal", errorModal, true, true)
-		} else {
-			successModal := tview.NewModal().
-				SetText("Connection test successful! Saving configuration...").
-				AddButtons([]string{"OK"}).
-				SetDoneFunc(func(int, string) {
-					pages.RemovePage("rpc_success_modal")
-					newConfig := &rpc.RPCConfig{
-						Username: username,
-						Password: password,
-						WSURL:    wsURL,
-					}
-					if err := rpc.SaveRPCConfig(newConfig); err != nil {
+			pages.AddPage("rpc_save_error_modal", errorModal, true, true)
+			return
+		}
+		if existing != nil {
+			// Drop the connection made with the old settings
+			rpc.Sessions.Remove(existing)
+		}
+		onSaved()
+	}
+	reopen := func() {
+		pages.RemovePage("rpc_setup_modal")
+		reopenRemoteControlMenu(app, pages, buildDir)
+	}
+
+	// withEncryptedPassword encrypts password, when there is one, off the UI
+	// goroutine as deriving the key takes a while, then calls onReady on it
+	withEncryptedPassword := func(config *rpc.RPCConfig, password, passphrase string, onReady func(config *rpc.RPCConfig)) {
+		if password == "" {
+			onReady(config)
+			return
+		}
+		go func() {
+			encrypted, err := rpc.EncryptPassword(password, passphrase)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showFormError(fmt.Sprintf("Error encrypting the password: %v", err))
+					return
+				}
+				config.Encrypted = encrypted
+				onReady(config)
+			})
+		}()
+	}
+
+	setupForm.AddButton("Test Connection", func() {
+		testConfig, password, passphrase, problem := formConfig()
+		if problem != "" {
+			showFormError(problem)
+			return
+		}
+		withEncryptedPassword(testConfig, password, passphrase, func(testConfig *rpc.RPCConfig) {
+			go func() {
+				err := rpc.TestRPCConnection(testConfig)
+				app.QueueUpdateDraw(func() {
+					if err != nil {
+						if trustOnFirstUse(pages, testConfig, err, func(pinned *rpc.RPCConfig) {
+							saveProfile(pinned, reopen)
+						}) {
+							return
+						}
 						errorModal := tview.NewModal().
-							SetText(fmt.Sprintf("Error saving config: %v", err)).
+							SetText(fmt.Sprintf("Connection test failed: %v", err)).
 							AddButtons([]string{"OK"}).
 							SetDoneFunc(func(int, string) {
-								pages.RemovePage("rpc_save_error_modal")
+								pages.RemovePage("rpc_test_error_modal")
 							})
-						pages.AddPage("rpc_save_error_modal", errorModal, true, true)
-					} else {
-						// Refresh the page
-						pages.RemovePage("rpc_setup_modal")
-						RemoteControlMenuPage(app, pages, buildDir)
-						pages.SwitchToPage("remote_control_menu")
+						pages.AddPage("rpc_test_error_modal", errorModal, true, true)
+						return
 					}
+					successModal := tview.NewModal().
+						SetText("Connection test successful! Saving configuration...").
+						AddButtons([]string{"OK"}).
+						SetDoneFunc(func(int, string) {
+							pages.RemovePage("rpc_success_modal")
+							saveProfile(testConfig, reopen)
+						})
+					pages.AddPage("rpc_success_modal", successModal, true, true)
 				})
-			pages.AddPage("rpc_success_modal", successModal, true, true)
-		}
+			}()
+		})
 	})
 
 	setupForm.AddButton("Save", func() {
-		// Get values from form fields
-		username := setupForm.GetFormItem(0).(*tview.InputField).GetText()
-		password := setupForm.GetFormItem(1).(*tview.InputField).GetText()
-		wsURL := setupForm.GetFormItem(2).(*tview.InputField).GetText()
-
-		// Basic validation
-		if username == "" || password == "" || wsURL == "" {
-			errorModal := tview.NewModal().
-				SetText("All fields are required. Please fill in username, password, and WebSocket URL.").
-				AddButtons([]string{"OK"}).
-				SetDoneFunc(func(int, string) {
-					pages.RemovePage("rpc_validation_error_modal")
-				})
-			pages.AddPage("rpc_validation_error_modal", errorModal, true, true)
+		newConfig, password, passphrase, problem := formConfig()
+		if problem != "" {
+			showFormError(problem)
 			return
 		}
 
-		newConfig := &rpc.RPCConfig{
-			Username: username,
-			Password: password,
-			WSURL:    wsURL,
-		}
-		if err := rpc.SaveRPCConfig(newConfig); err != nil {
-			errorModal := tview.NewModal().
-				SetText(fmt.Sprintf("Error saving config: %v", err)).
-				AddButtons([]string{"OK"}).
-				SetDoneFunc(func(int, string) {
-					pages.RemovePage("rpc_save_error_modal")
-				})
-			pages.AddPage("rpc_save_error_modal", errorModal, true, true)
-		} else {
-			successModal := tview.NewModal().
-				SetText("Configuration saved successfully!").
-				AddButtons([]string{"OK"}).
-				SetDoneFunc(func(int, string) {
-					pages.RemovePage("rpc_save_success_modal")
-					// Refresh the page
-					pages.RemovePage("rpc_setup_modal")
-					RemoteControlMenuPage(app, pages, buildDir)
-					pages.SwitchToPage("remote_control_menu")
-				})
-			pages.AddPage("rpc_save_success_modal", successModal, true, true)
-		}
+		withEncryptedPassword(newConfig, password, passphrase, func(newConfig *rpc.RPCConfig) {
+			saveProfile(newConfig, func() {
+				successModal := tview.NewModal().
+					SetText("Configuration saved successfully!").
+					AddButtons([]string{"OK"}).
+					SetDoneFunc(func(int, string) {
+						pages.RemovePage("rpc_save_success_modal")
+						reopen()
+					})
+				pages.AddPage("rpc_save_success_modal", successModal, true, true)
+			})
+		})
 	})
 
 	setupForm.AddButton("Cancel", func() {
 		pages.RemovePage("rpc_setup_modal")
-		pages.SwitchToPage("main_menu")
+		if !pages.HasPage("remote_control_menu") {
+			pages.SwitchToPage("main_menu")
+		}
 	})
 
 	// Set button alignment to center
 	setupForm.SetButtonsAlign(tview.AlignCenter)
 
 	// Create centered modal layout
-	formHeight := 12 // Approximate height for form with inputs and buttons
-	formWidth := 60  // Approximate width for form
+	formHeight := 23 // Approximate height for form with inputs and buttons
+	formWidth := 70  // Approximate width for form
 
 	centeredFlex := tview.NewFlex().SetDirection(tview.FlexRow).
 		AddItem(tview.NewTextView(), 0, 1, false).
@@ -315,26 +530,229 @@ func showRPCSetupModal(app *tview.Application, pages *tview.Pages, buildDir stri
 	pages.AddPage("rpc_setup_modal", centeredFlex, true, true)
 }
 
-func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, flex *tview.Flex, buildDir string) {
+// passwordSources are the choices of the setup form for where the password comes from
+var passwordSources = []string{"Encrypted with a passphrase", "Environment variable", "Command (like pass show)"}
+
+// tlsVerifyOptions are the choices of the setup form for verifying the server certificate
+var tlsVerifyOptions = []string{"System certificate store", "CA file", "Pinned SPKI fingerprint"}
+
+// trustOnFirstUse offers to pin the certificate of a server that does not
+// verify against the system store, showing its fingerprint to compare with
+// ./unrealircd spkifp. It returns false when there is nothing to offer, like
+// for a pinned profile whose fingerprint changed.
+func trustOnFirstUse(pages *tview.Pages, config *rpc.RPCConfig, err error, onTrust func(pinned *rpc.RPCConfig)) bool {
+	var untrusted *rpc.UntrustedCertError
+	if !errors.As(err, &untrusted) || (config.Verify != "" && config.Verify != rpc.TLSVerifySystem) {
+		return false
+	}
+	text := fmt.Sprintf("The TLS certificate of %s is not trusted:\n%v\n\nIts SPKI fingerprint is\n%s\n\nCompare it with the output of ./unrealircd spkifp on the server. Pin this fingerprint and trust the server from now on?",
+		config.WSURL, untrusted.Err, untrusted.Fingerprint)
+	showConfirmModal(pages, "tls_trust_modal", text, func() {
+		pinned := *config
+		pinned.TLSSettings = rpc.TLSSettings{Verify: rpc.TLSVerifyPin, SPKIPin: untrusted.Fingerprint}
+		onTrust(&pinned)
+	})
+	return true
+}
+
+// showPassphraseModal asks for the passphrase the password of config is
+// encrypted with and calls onUnlocked once it decrypts
+func showPassphraseModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, onUnlocked func()) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(fmt.Sprintf("Passphrase for %s", config.Name))
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.AddPasswordField("Passphrase:", "", 40, '*', nil)
+
+	closeModal := func() {
+		pages.RemovePage("passphrase_modal")
+		if !pages.HasPage("remote_control_menu") {
+			pages.SwitchToPage("main_menu")
+		}
+	}
+
+	// Deriving the key takes a while, it is done off the UI goroutine
+	form.AddButton("Unlock", func() {
+		passphrase := form.GetFormItem(0).(*tview.InputField).GetText()
+		go func() {
+			err := rpc.UnlockPassword(config, passphrase)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "passphrase_error_modal", fmt.Sprintf("Could not decrypt the password: %v", err))
+					return
+				}
+				pages.RemovePage("passphrase_modal")
+				onUnlocked()
+			})
+		}()
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("passphrase_modal", centeredModal(form, 64, 7), true, true)
+	app.SetFocus(form)
+}
+
+// showLegacyPasswordModal asks where the plain text passwords of legacy
+// should be kept instead, one profile at a time, and rewrites the config file
+// without them before calling onConverted. A passphrase is only asked for
+// once, the passwords after it are encrypted with it too.
+func showLegacyPasswordModal(app *tview.Application, pages *tview.Pages, legacy []rpc.RPCConfig, onConverted func()) {
+	sources := make(map[string]rpc.PasswordSource)
+	passphrase := ""
+
+	closeModal := func() {
+		pages.RemovePage("legacy_password_modal")
+		if !pages.HasPage("remote_control_menu") {
+			pages.SwitchToPage("main_menu")
+		}
+	}
+
+	finish := func() {
+		go func() {
+			err := rpc.ConvertLegacyPasswords(sources)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "legacy_password_error_modal", fmt.Sprintf("Error saving config: %v", err))
+					return
+				}
+				onConverted()
+			})
+		}()
+	}
+
+	var convert func(i int)
+	encrypt := func(i int) {
+		go func() {
+			encrypted, err := rpc.EncryptPassword(legacy[i].LegacyPassword, passphrase)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "legacy_password_error_modal", fmt.Sprintf("Error encrypting the password: %v", err))
+					return
+				}
+				sources[legacy[i].Name] = rpc.PasswordSource{Encrypted: encrypted}
+				convert(i + 1)
+			})
+		}()
+	}
+
+	convert = func(i int) {
+		if i == len(legacy) {
+			finish()
+			return
+		}
+		if passphrase != "" {
+			encrypt(i)
+			return
+		}
+
+		form := tview.NewForm()
+		form.SetBorder(true).SetTitle(fmt.Sprintf("Plain Text Password of %s", legacy[i].Name))
+		form.SetBackgroundColor(tcell.ColorDefault)
+		form.AddTextView("", "The RPC password of this profile is stored in plain text. Choose where it comes from instead, the config file is then saved without it.", 56, 3, true, false)
+		form.AddDropDown("Password from:", passwordSources, 0, nil)
+		form.AddPasswordField("Passphrase:", "", 30, '*', nil)
+		form.AddInputField("Variable or command:", "", 40, nil, nil)
+
+		form.AddButton("Convert", func() {
+			source, _ := form.GetFormItem(1).(*tview.DropDown).GetCurrentOption()
+			text := form.GetFormItem(2).(*tview.InputField).GetText()
+			reference := strings.TrimSpace(form.GetFormItem(3).(*tview.InputField).GetText())
+			switch source {
+			case 0:
+				if text == "" {
+					showMessageModal(pages, "legacy_password_error_modal", "Please fill in a passphrase to encrypt the password with.")
+					return
+				}
+				passphrase = text
+			case 1:
+				if reference == "" {
+					showMessageModal(pages, "legacy_password_error_modal", "Please fill in the environment variable holding the password.")
+					return
+				}
+				sources[legacy[i].Name] = rpc.PasswordSource{Env: reference}
+			case 2:
+				if reference == "" {
+					showMessageModal(pages, "legacy_password_error_modal", "Please fill in the command printing the password, like pass show irc/rpc.")
+					return
+				}
+				sources[legacy[i].Name] = rpc.PasswordSource{Command: reference}
+			}
+			pages.RemovePage("legacy_password_modal")
+			if source == 0 {
+				encrypt(i)
+				return
+			}
+			convert(i + 1)
+		})
+		form.AddButton("Cancel", closeModal)
+		form.SetCancelFunc(closeModal)
+		form.SetButtonsAlign(tview.AlignCenter)
+
+		pages.AddPage("legacy_password_modal", centeredModal(form, 64, 14), true, true)
+		app.SetFocus(form)
+	}
+	convert(0)
+}
+
+// reopenRemoteControlMenu builds the remote control menu again for the active
+// profile, closing the profiles page and the live views of the old menu
+func reopenRemoteControlMenu(app *tview.Application, pages *tview.Pages, buildDir string) {
+	if stopLiveView != nil {
+		stopLiveView()
+	}
+	if stopProfileStatus != nil {
+		stopProfileStatus()
+	}
+	pages.RemovePage("remote_profiles")
+	pages.RemovePage("remote_control_menu")
+	pages.SwitchToPage("main_menu") // Under the setup modal when no profile is left
+	RemoteControlMenuPage(app, pages, buildDir)
+	if pages.HasPage("remote_control_menu") {
+		pages.SwitchToPage("remote_control_menu")
+	}
+}
+
+// showRemoteControlOptions fills flex with the menu for config. local is the
+// profile detected for the installation in buildDir, nil when there is none.
+func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config, local *rpc.RPCConfig, flex *tview.Flex, buildDir string) {
 	// Left: Menu list
 	list := tview.NewList()
 	list.SetBorder(true)
-	list.SetTitle("Remote Control")
+	list.SetTitle(fmt.Sprintf("Remote Control - %s", config.Name))
 	list.SetBorderColor(tcell.ColorGreen)
 
 	list.AddItem("• Channels", "  View and manage channels", 0, nil)
 	list.AddItem("• Users", "  View and manage users", 0, nil)
+	list.AddItem("• Clones", "  Users sharing an IP, network or account", 0, func() {
+		remoteClonesPage(app, pages, config)
+	})
+	list.AddItem("• Reputation", "  Reputation scores and their trend", 0, func() {
+		remoteReputationPage(app, pages, config)
+	})
 	list.AddItem("• Servers", "  View server information", 0, func() {
 		remoteServersPage(app, pages, config)
 	})
 	list.AddItem("• Server Bans", "  View and manage bans (G-lines, K-lines, etc)", 0, func() {
 		remoteServerBansPage(app, pages, config)
 	})
+	list.AddItem("• Name Bans", "  Reserved nicks and channels (Q-lines)", 0, func() {
+		remoteNameBansPage(app, pages, config)
+	})
+	list.AddItem("• Ban Exceptions", "  Exempt masks from bans (E-lines)", 0, func() {
+		remoteBanExceptionsPage(app, pages, config)
+	})
+	list.AddItem("• Spamfilters", "  View and manage spamfilters", 0, func() {
+		remoteSpamfiltersPage(app, pages, config)
+	})
 	list.AddItem("• Log Streaming", "  Stream server logs in real-time", 0, func() {
 		remoteLogStreamingPage(app, pages, config, buildDir)
 	})
-	list.AddItem("• Configure RPC", "  Update RPC credentials", 0, func() {
-		reconfigureRPC(app, pages, buildDir)
+	list.AddItem("• Profiles", "  Switch between servers and networks", 0, func() {
+		remoteProfilesPage(app, pages, config, local, buildDir)
+	})
+	list.AddItem("• Configure RPC", "  Edit the active profile", 0, func() {
+		reconfigureRPC(app, pages, config, buildDir)
 	})
 
 	// Right: Dynamic content area
@@ -350,16 +768,20 @@ func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config
 		"Select an option to view or manage:\n\n" +
 			"• [green]Channels[-] - View all channels, topics, and member lists\n" +
 			"• [green]Users[-] - View online users and their details\n" +
+			"• [green]Clones[-] - Find users sharing an IP, network or account\n" +
+			"• [green]Reputation[-] - See how reputation scores are spread and change\n" +
 			"• [green]Servers[-] - View server information and statistics\n" +
 			"• [green]Server Bans[-] - Manage G-lines, K-lines, and Z-lines\n" +
+			"• [green]Name Bans[-] - Reserve nick and channel masks (Q-lines)\n" +
+			"• [green]Ban Exceptions[-] - Exempt masks from bans and checks (E-lines)\n" +
+			"• [green]Spamfilters[-] - Manage spamfilters and see their hit counts\n" +
 			"• [green]Log Streaming[-] - Stream server logs in real-time\n" +
-			"• [green]Configure RPC[-] - Update your connection settings")
+			"• [green]Profiles[-] - Switch between saved servers and networks\n" +
+			"• [green]Configure RPC[-] - Update the connection settings of this profile")
 
-	// Users list view
-	usersList := tview.NewList()
-	usersList.SetBorder(true)
-	usersList.SetTitle("Users")
-	usersList.SetBorderColor(tcell.ColorBlue)
+	// Users table
+	usersTable := newListTable(app, pages, "users", "Users", rpc.UserColumns, userItems(nil))
+	heldUsersTable = usersTable
 
 	// User details view
 	userDetailsView := tview.NewTextView()
@@ -368,11 +790,8 @@ func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config
 	userDetailsView.SetDynamicColors(true)
 	userDetailsView.SetWordWrap(true)
 
-	// Channels list view
-	channelsList := tview.NewList()
-	channelsList.SetBorder(true)
-	channelsList.SetTitle("Channels")
-	channelsList.SetBorderColor(tcell.ColorBlue)
+	// Channels table
+	channelsTable := newListTable(app, pages, "channels", "Channels", rpc.ChannelColumns, channelItems(nil))
 
 	// Channel details view
 	channelDetailsView := tview.NewTextView()
@@ -381,9 +800,21 @@ func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config
 	channelDetailsView.SetDynamicColors(true)
 	channelDetailsView.SetWordWrap(true)
 
+	// Keys and freshness of the live Users or Channels pane
+	statusView := tview.NewTextView()
+	statusView.SetDynamicColors(true)
+
 	// Initially show info view
 	contentArea.AddItem(infoView, 0, 1, false)
 
+	// Nick to select in the Users pane, set when jumping there from a channel member
+	selectUser := ""
+	jumpToUser := func(nick string) {
+		selectUser = nick
+		list.SetCurrentItem(1) // Index 1 is "Users"
+		app.SetFocus(usersTable.table)
+	}
+
 	list.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
 		// Clear current content
 		contentArea.Clear()
@@ -391,26 +822,40 @@ func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config
 		switch mainText {
 		case "• Channels":
 			// Show channels list and details
-			loadChannelsList(app, channelsList, channelDetailsView, config)
+			loadChannelsList(app, pages, channelsTable, channelDetailsView, statusView, config, jumpToUser)
 			channelsFlex := tview.NewFlex()
-			channelsFlex.AddItem(channelsList, 0, 1, true)
+			channelsFlex.AddItem(channelsTable, 0, 2, true)
 			channelsFlex.AddItem(channelDetailsView, 0, 1, false)
 			contentArea.AddItem(channelsFlex, 0, 1, false)
+			contentArea.AddItem(statusView, 1, 0, false)
 		case "• Users":
 			// Show users list and details
-			loadUsersList(app, usersList, userDetailsView, config)
+			loadUsersList(app, pages, usersTable, userDetailsView, statusView, config, selectUser)
+			selectUser = ""
 			userFlex := tview.NewFlex()
-			userFlex.AddItem(usersList, 0, 1, true)
+			userFlex.AddItem(usersTable, 0, 2, true)
 			userFlex.AddItem(userDetailsView, 0, 1, false)
 			contentArea.AddItem(userFlex, 0, 1, false)
+			contentArea.AddItem(statusView, 1, 0, false)
 		default:
+			// Other items are not updated live
+			if stopLiveView != nil {
+				stopLiveView()
+			}
+
 			// Show info for other items
 			descriptions := map[string]string{
-				"• Channels":      "Display all channels on the network with topic, user count, and modes.",
-				"• Users":         "List all connected users with nick, realname, account, and channel memberships.",
-				"• Servers":       "Show server information including uptime, software version, and user count.",
-				"• Server Bans":   "View and manage all active bans (G-lines, K-lines, Z-lines, etc).",
-				"• Configure RPC": "Update your RPC API credentials for UnrealIRCd connection.",
+				"• Channels":       "Display all channels on the network with topic, user count, and modes.",
+				"• Users":          "List all connected users with nick, realname, account, and channel memberships.",
+				"• Clones":         "Group users by IP, /24 or /64 network and account, largest groups first, and ban or kill a group.",
+				"• Reputation":     "Histogram of reputation scores, low reputation users per server and the trend over periodic snapshots.",
+				"• Servers":        "Show server information including uptime, software version, and user count.",
+				"• Server Bans":    "View and manage all active bans (G-lines, K-lines, Z-lines, etc).",
+				"• Name Bans":      "View and manage reserved nick and channel masks (Q-lines).",
+				"• Ban Exceptions": "View and manage ban exceptions (E-lines) and the bans and checks they exempt from.",
+				"• Spamfilters":    "View, add and remove spamfilters, with their targets, actions and hit counts.",
+				"• Profiles":       "Pick, add, edit and remove connection profiles, with the connection status of each.",
+				"• Configure RPC":  "Update the RPC API credentials of the active profile.",
 			}
 			if desc, ok := descriptions[mainText]; ok {
 				infoView.SetText(desc)
@@ -424,6 +869,9 @@ func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config
 
 	backBtn := tview.NewButton("Back")
 	backBtn.SetSelectedFunc(func() {
+		if stopLiveView != nil {
+			stopLiveView()
+		}
 		pages.SwitchToPage("main_menu")
 	})
 
@@ -436,425 +884,614 @@ func showRemoteControlOptions(app *tview.Application, pages *tview.Pages, config
 	contentFlex.AddItem(list, 30, 0, true)
 	contentFlex.AddItem(contentArea, 0, 1, false)
 
-	flex.AddItem(tview.NewTextView().SetText(""), 1, 0, false)
+	// Connection status bar, fed by the shared session
+	statusBar := tview.NewTextView()
+	statusBar.SetDynamicColors(true)
+	watchConnectionState(app, pages, statusBar, config, buildDir)
+
+	flex.AddItem(statusBar, 1, 0, false)
 	flex.AddItem(contentFlex, 0, 1, true)
 	flex.AddItem(buttonBar, 3, 0, false)
 }
 
-func loadUsersList(app *tview.Application, usersList *tview.List, userDetailsView *tview.TextView, config *rpc.RPCConfig) {
-	// Debug: write to file
-	debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	fmt.Fprintf(debugFile, "DEBUG: loadUsersList called\n")
-	debugFile.Close()
+// stopStatusWatch stops the goroutine feeding the current remote control status bar
+var stopStatusWatch func()
 
-	// Clear existing items
-	usersList.Clear()
+// watchConnectionState shows the connection state of config's session in
+// statusBar, offering to trust the server the first time its certificate fails
+func watchConnectionState(app *tview.Application, pages *tview.Pages, statusBar *tview.TextView, config *rpc.RPCConfig, buildDir string) {
+	if stopStatusWatch != nil {
+		stopStatusWatch()
+	}
 
-	// Show loading message
-	userDetailsView.SetText("Loading users...")
+	session := rpc.Sessions.Get(config)
+	states, stop := session.Watch()
+	stopStatusWatch = stop
 
-	// Fetch users synchronously to avoid UI corruption
-	client, err := rpc.NewRPCClient(config)
-	if err != nil {
-		userDetailsView.SetText(fmt.Sprintf("Error creating RPC client: %v", err))
-		return
-	}
-	defer client.Close()
+	go func() {
+		offered := false
+		for state := range states {
+			text := formatConnectionState(state, fmt.Sprintf("%s (%s)", config.Name, config.Target()))
+			app.QueueUpdateDraw(func() {
+				statusBar.SetText(text)
+			})
 
-	if err := client.Connect(); err != nil {
-		userDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
-		return
-	}
+			var untrusted *rpc.UntrustedCertError
+			if offered || !errors.As(state.Err, &untrusted) {
+				continue
+			}
+			offered = true
+			app.QueueUpdateDraw(func() {
+				trustOnFirstUse(pages, config, state.Err, func(pinned *rpc.RPCConfig) {
+					if err := rpc.SaveRPCProfile(config.Name, pinned); err != nil {
+						showMessageModal(pages, "tls_trust_error_modal", fmt.Sprintf("Error saving config: %v", err))
+						return
+					}
+					rpc.Sessions.Remove(config)
+					reopenRemoteControlMenu(app, pages, buildDir)
+				})
+			})
+		}
+	}()
 
-	users, err := client.GetUsers()
-	if err != nil {
-		userDetailsView.SetText(fmt.Sprintf("Error fetching users: %v", err))
-		return
-	}
+	// Connect in the background so the status is known before the first view needs it
+	go session.Client()
+}
 
-	// Debug: also get details for the first user
-	if len(users) > 0 {
-		_, err := client.GetUserDetails(users[0].Nick)
-		if err != nil {
-			// fmt.Printf("DEBUG: GetUserDetails error: %v\n", err)
+// formatConnectionState describes state for a TextView with dynamic colors. The
+// target and the error, from the server or the dial, are escaped.
+func formatConnectionState(state rpc.StateEvent, target string) string {
+	target = tview.Escape(target)
+	var reason string
+	if state.Err != nil {
+		reason = tview.Escape(state.Err.Error())
+	}
+	switch state.State {
+	case rpc.StateConnected:
+		return fmt.Sprintf(" [green]● Connected[-] to %s since %s", target, state.Time.Format("15:04:05"))
+	case rpc.StateConnecting:
+		return fmt.Sprintf(" [yellow]● Connecting[-] to %s...", target)
+	case rpc.StateReconnecting:
+		return fmt.Sprintf(" [yellow]● Reconnecting[-] to %s (attempt %d in %s): %s", target, state.Attempt, state.RetryIn, reason)
+	case rpc.StateDisconnected:
+		if state.Err != nil {
+			return fmt.Sprintf(" [red]● Disconnected[-] from %s: %s", target, reason)
 		}
+		return fmt.Sprintf(" [gray]● Not connected[-] to %s", target)
 	}
+	return fmt.Sprintf(" [gray]● %s[-]", state.State)
+}
 
-	// Debug: show what we got
-	debugText := fmt.Sprintf("DEBUG: Got %d users\n", len(users))
-	for i, user := range users {
-		debugText += fmt.Sprintf("User %d: Nick='%s', Channels=%v\n", i, user.Nick, user.Channels)
+// usersLoadGen identifies the latest loadUsersList call, batches of older loads are dropped
+var usersLoadGen int64
+
+// loadUsersList fills usersTable with all users, then keeps it current from
+// subscribed events, showing how fresh it is in statusView. When selectNick is
+// set, that user is selected as soon as it is loaded. Users are loaded and
+// updated off the UI goroutine.
+func loadUsersList(app *tview.Application, pages *tview.Pages, usersTable *listTable, userDetailsView *tview.TextView, statusView *tview.TextView, config *rpc.RPCConfig, selectNick string) {
+	gen := atomic.AddInt64(&usersLoadGen, 1)
+	current := func() bool { return atomic.LoadInt64(&usersLoadGen) == gen }
+
+	showSelected := func() {
+		if user, ok := tableUser(usersTable, usersTable.Selected()); ok {
+			userDetailsView.SetText(formatUserDetails(user))
+		} else if _, total := usersTable.model.counts(); total == 0 {
+			userDetailsView.SetText("No users found.")
+		}
 	}
-	if len(users) == 0 {
-		debugText += "No users found (empty list)"
+	usersTable.onSelect = func(nick string) {
+		showSelected()
 	}
-	// fmt.Printf("DEBUG UI: %s\n", debugText) // Print to console for debugging
+	usersTable.onChange = showSelected
 
-	// Update UI synchronously
-	// userDetailsView.SetText(debugText)
-	userDetailsView.SetText("Select a user to view details.")
+	usersTable.SetTitle("Loading users")
+	usersTable.SetItems(userItems(nil))
+	userDetailsView.SetText("Loading users...")
 
-	for _, user := range users {
-		// Skip empty/invalid users
-		if user.Nick == "" && user.Name == "" {
-			continue
+	// Events are held back until the initial load is done. Both are only
+	// touched inside usersTable.Update.
+	loading := true
+	var pending []rpc.Event
+	// Each event is applied to the user it is about only
+	applyEvents := func(m *tableModel, events []rpc.Event) {
+		for _, event := range events {
+			var users []rpc.UserInfo
+			if i := m.find(event.Nick); i >= 0 {
+				users = append(users, m.items.(userItems)[i])
+			}
+			users, changed := rpc.ApplyUserEvent(users, event)
+			if !changed {
+				continue
+			}
+			if len(users) == 0 || event.Type == rpc.EventNickChange {
+				m.remove(event.Nick)
+			}
+			if len(users) > 0 {
+				m.put(users[0].Nick, users[0])
+			}
+			if event.Type == rpc.EventNickChange {
+				m.renamed(event.Nick, event.NewNick)
+			}
 		}
+	}
+	var live *liveView
+	live = startLiveView(app, config, statusView, userActionKeys+" | "+tableKeys, func(events []rpc.Event) {
+		usersTable.Update(func(m *tableModel) {
+			if !current() {
+				return
+			}
+			if loading {
+				pending = append(pending, events...)
+				return
+			}
+			applyEvents(m, events)
+		})
+	}, func() {
+		loadUsersList(app, pages, usersTable, userDetailsView, statusView, config, usersTable.Selected())
+	})
 
-		displayName := user.Nick
-		if user.Account != "" {
-			displayName += fmt.Sprintf(" (%s)", user.Account)
+	// Table keys, then moderation actions on the selected user. After an action
+	// only that user is fetched again, the events of the action update the rest.
+	usersTable.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
 		}
-		if displayName == "" {
-			displayName = user.Name // fallback to name if nick is empty
+		if usersTable.handleKey(event.Rune()) {
+			return nil
 		}
-		if displayName == "" {
-			displayName = "Unknown" // fallback if both are empty
+		if event.Rune() == 'r' {
+			loadUsersList(app, pages, usersTable, userDetailsView, statusView, config, usersTable.Selected())
+			return nil
 		}
-		secondaryText := fmt.Sprintf("  %s (%s) - %d channels", user.Realname, user.IP, len(user.Channels))
-
-		usersList.AddItem(displayName, secondaryText, 0, nil)
-	}
-
-	// Set current item to first user
-	if len(users) > 0 {
-		usersList.SetCurrentItem(0)
-	}
+		user, ok := tableUser(usersTable, usersTable.Selected())
+		if !ok {
+			return event
+		}
+		reload := func() {
+			go func() {
+				client, err := rpc.Sessions.Get(config).Client()
+				if err != nil {
+					return
+				}
+				details, err := client.GetUserDetails(user.Nick)
+				if err != nil {
+					return
+				}
+				usersTable.Update(func(m *tableModel) {
+					if m.find(user.Nick) >= 0 && current() {
+						m.put(details.Nick, *details)
+					}
+				})
+			}()
+		}
+		if handleUserActionKey(app, pages, config, user, event.Rune(), reload) {
+			return nil
+		}
+		return event
+	})
 
-	// Set up selection handler for user details
-	usersList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
-		if index >= 0 && index < len(users) {
-			user := users[index]
+	// Fetch in the background, the table fills in as batches arrive
+	go func() {
+		client, err := rpc.Sessions.Get(config).Client()
+		if err != nil {
+			usersTable.Update(func(m *tableModel) {
+				if current() {
+					loading = false
+					pending = nil
+				}
+			})
+			app.QueueUpdateDraw(func() {
+				if current() {
+					userDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				}
+			})
+			return
+		}
 
-			// Format account display
-			accountDisplay := user.Account
-			if accountDisplay == "" || accountDisplay == "none" {
-				accountDisplay = "None (not logged in)"
+		err = client.StreamUsers(func(batch []rpc.UserInfo) bool {
+			if !current() {
+				return false
+			}
+			usersTable.Update(func(m *tableModel) {
+				if !current() {
+					return
+				}
+				for _, user := range batch {
+					m.put(user.Nick, user)
+				}
+			})
+			if selectNick != "" && usersIndex(batch, selectNick) >= 0 {
+				app.QueueUpdateDraw(func() {
+					if current() {
+						usersTable.SelectKey(selectNick)
+					}
+				})
 			}
+			return true
+		})
 
-			// Format channels
-			channelsStr := ""
-			if len(user.Channels) > 0 {
-				var colored []string
-				for _, ch := range user.Channels {
-					colored = append(colored, "  [blue]"+ch+"[white]")
-				}
-				channelsStr = "\n" + strings.Join(colored, "\n")
-			} else {
-				channelsStr = "\n  None"
+		usersTable.Update(func(m *tableModel) {
+			if !current() {
+				return
 			}
-
-			// Format security groups
-			securityGroupsStr := ""
-			if len(user.SecurityGroups) > 0 {
-				var colored []string
-				for _, sg := range user.SecurityGroups {
-					colored = append(colored, "  [blue]"+sg+"[white]")
-				}
-				securityGroupsStr = "\n" + strings.Join(colored, "\n")
-			} else {
-				securityGroupsStr = "\n  None"
+			loading = false
+			events := pending
+			pending = nil
+			applyEvents(m, events)
+		})
+		app.QueueUpdateDraw(func() {
+			if !current() {
+				return
+			}
+			usersTable.SetTitle("Users")
+			if err != nil {
+				userDetailsView.SetText(fmt.Sprintf("Error fetching users: %v", err))
+				return
 			}
+			showSelected()
+			live.touch()
+		})
+	}()
+}
 
-			details := fmt.Sprintf(
-				"[green]Nick:[white]\n  %s\n"+
-					"[green]Real Name:[white]\n  %s\n"+
-					"[green]Account:[white]\n  %s\n"+
-					"[green]IP:[white]\n  %s\n"+
-					"[green]Username:[white]\n  %s\n"+
-					"[green]Vhost:[white]\n  %s\n"+
-					"[green]Cloaked Host:[white]\n  %s\n"+
-					"[green]Server Name:[white]\n  %s\n"+
-					"[green]Reputation:[white]\n  %d\n"+
-					"[green]Modes:[white]\n  %s\n"+
-					"[green]Security Groups:[white]%s\n"+
-					"[green]Channels:[white]%s",
-				user.Nick, user.Realname, accountDisplay, user.IP, user.Username, user.Vhost, user.Cloakedhost, user.Servername, user.Reputation, user.Modes, securityGroupsStr, channelsStr)
-			userDetailsView.SetText(details)
+// tableUser returns the user with nick in usersTable. Live updates replace
+// users rather than change them, so it can be kept.
+func tableUser(usersTable *listTable, nick string) (rpc.UserInfo, bool) {
+	var user rpc.UserInfo
+	found := false
+	usersTable.Read(func(m *tableModel) {
+		if i := m.find(nick); i >= 0 {
+			user = m.items.(userItems)[i]
+			found = true
 		}
 	})
+	return user, found
+}
 
-	// Show first user details by default
-	if len(users) > 0 {
-		user := users[0]
-
-		// Format account display
-		accountDisplay := user.Account
-		if accountDisplay == "" || accountDisplay == "none" {
-			accountDisplay = "None (not logged in)"
-		}
-
-		// Format channels
-		channelsStr := ""
-		if len(user.Channels) > 0 {
-			var colored []string
-			for _, ch := range user.Channels {
-				colored = append(colored, "  [blue]"+ch+"[white]")
-			}
-			channelsStr = "\n" + strings.Join(colored, "\n")
-		} else {
-			channelsStr = "\n  None"
-		}
-
-		// Format security groups
-		securityGroupsStr := ""
-		if len(user.SecurityGroups) > 0 {
-			var colored []string
-			for _, sg := range user.SecurityGroups {
-				colored = append(colored, "  [blue]"+sg+"[white]")
-			}
-			securityGroupsStr = "\n" + strings.Join(colored, "\n")
-		} else {
-			securityGroupsStr = "\n  None"
+// usersIndex returns the index of the user with nick in users, or -1
+func usersIndex(users []rpc.UserInfo, nick string) int {
+	if nick == "" {
+		return -1
+	}
+	for i, user := range users {
+		if strings.EqualFold(user.Nick, nick) {
+			return i
 		}
-
-		details := fmt.Sprintf(
-			"[green]Nick:[white]\n  %s\n"+
-				"[green]Real Name:[white]\n  %s\n"+
-				"[green]Account:[white]\n  %s\n"+
-				"[green]IP:[white]\n  %s\n"+
-				"[green]Username:[white]\n  %s\n"+
-				"[green]Vhost:[white]\n  %s\n"+
-				"[green]Cloaked Host:[white]\n  %s\n"+
-				"[green]Server Name:[white]\n  %s\n"+
-				"[green]Reputation:[white]\n  %d\n"+
-				"[green]Modes:[white]\n  %s\n"+
-				"[green]Security Groups:[white]%s\n"+
-				"[green]Channels:[white]%s",
-			user.Nick, user.Realname, accountDisplay, user.IP, user.Username, user.Vhost, user.Cloakedhost, user.Servername, user.Reputation, user.Modes, securityGroupsStr, channelsStr)
-		userDetailsView.SetText(details)
 	}
+	return -1
 }
 
-func loadChannelsList(app *tview.Application, channelsList *tview.List, channelDetailsView *tview.TextView, config *rpc.RPCConfig) {
-	// Clear existing items
-	channelsList.Clear()
-
-	// Show loading message
-	channelDetailsView.SetText("Loading channels...")
+func formatUserDetails(user rpc.UserInfo) string {
+	// Format account display
+	accountDisplay := user.Account
+	if accountDisplay == "" || accountDisplay == "none" {
+		accountDisplay = "None (not logged in)"
+	}
 
-	// Fetch channels synchronously
-	client, err := rpc.NewRPCClient(config)
-	if err != nil {
-		channelDetailsView.SetText(fmt.Sprintf("Error creating RPC client: %v", err))
-		return
+	countryDisplay := user.Country
+	if countryDisplay == "" {
+		countryDisplay = "Unknown"
 	}
-	defer client.Close()
 
-	if err := client.Connect(); err != nil {
-		channelDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
-		return
+	// Format channels
+	channelsStr := ""
+	if len(user.Channels) > 0 {
+		var colored []string
+		for _, ch := range user.Channels {
+			colored = append(colored, "  [blue]"+ch+"[white]")
+		}
+		channelsStr = "\n" + strings.Join(colored, "\n")
+	} else {
+		channelsStr = "\n  None"
 	}
 
-	channels, err := client.GetChannels()
-	if err != nil {
-		channelDetailsView.SetText(fmt.Sprintf("Error fetching channels: %v", err))
-		return
+	// Format security groups
+	securityGroupsStr := ""
+	if len(user.SecurityGroups) > 0 {
+		var colored []string
+		for _, sg := range user.SecurityGroups {
+			colored = append(colored, "  [blue]"+sg+"[white]")
+		}
+		securityGroupsStr = "\n" + strings.Join(colored, "\n")
+	} else {
+		securityGroupsStr = "\n  None"
 	}
 
-	// Update UI
-	channelDetailsView.SetText("Select a channel to view details.")
+	return fmt.Sprintf(
+		"[green]Nick:[white]\n  %s\n"+
+			"[green]Real Name:[white]\n  %s\n"+
+			"[green]Account:[white]\n  %s\n"+
+			"[green]IP:[white]\n  %s\n"+
+			"[green]Country:[white]\n  %s\n"+
+			"[green]Hostname:[white]\n  %s\n"+
+			"[green]Username:[white]\n  %s\n"+
+			"[green]Vhost:[white]\n  %s\n"+
+			"[green]Cloaked Host:[white]\n  %s\n"+
+			"[green]Server Name:[white]\n  %s\n"+
+			"[green]Reputation:[white]\n  %d\n"+
+			"[green]Modes:[white]\n  %s\n"+
+			"[green]Security Groups:[white]%s\n"+
+			"[green]Channels:[white]%s",
+		user.Nick, user.Realname, accountDisplay, user.IP, countryDisplay, user.Hostname, user.Username, user.Vhost, user.Cloakedhost, user.Servername, user.Reputation, user.Modes, securityGroupsStr, channelsStr)
+}
 
-	for _, channel := range channels {
-		displayName := channel.Name
-		if channel.Topic != "" {
-			// Truncate long topics
-			topic := channel.Topic
-			if len(topic) > 50 {
-				topic = topic[:47] + "..."
-			}
-			displayName += fmt.Sprintf(" - %s", topic)
+// channelsLoadGen identifies the latest loadChannelsList call, older loads are dropped
+var channelsLoadGen int64
+
+// loadChannelsList fills channelsTable with all channels, then keeps it current
+// from subscribed events, showing how fresh it is in statusView. Channels are
+// loaded and updated off the UI goroutine.
+func loadChannelsList(app *tview.Application, pages *tview.Pages, channelsTable *listTable, channelDetailsView *tview.TextView, statusView *tview.TextView, config *rpc.RPCConfig, onSelectUser func(nick string)) {
+	gen := atomic.AddInt64(&channelsLoadGen, 1)
+	current := func() bool { return atomic.LoadInt64(&channelsLoadGen) == gen }
+
+	// Lower case names of the channels with a member list, which quits and nick
+	// changes of members change. Only touched inside channelsTable.Update.
+	withMembers := make(map[string]bool)
+
+	// Load the detailed info of a channel in the background. The table is
+	// updated too, as actions change the topic, modes and member count.
+	showChannelDetails := func(name string) {
+		if name == "" {
+			channelDetailsView.SetText("No channels.")
+			return
 		}
-		secondaryText := fmt.Sprintf("  %d users, modes: %s", channel.UserCount, channel.Modes)
 
-		channelsList.AddItem(displayName, secondaryText, 0, nil)
-	}
+		// Show loading message
+		channelDetailsView.SetText("Loading channel details...")
+
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					channelDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			detailedChannel, err := client.GetChannelDetails(name)
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					channelDetailsView.SetText(fmt.Sprintf("Error getting channel details: %v", err))
+				})
+				return
+			}
 
-	// Set current item to first channel
-	if len(channels) > 0 {
-		channelsList.SetCurrentItem(0)
+			// Redrawing the table shows the details, unless another channel was selected meanwhile
+			channelsTable.Update(func(m *tableModel) {
+				if m.find(name) >= 0 && current() {
+					m.put(detailedChannel.Name, *detailedChannel)
+					withMembers[strings.ToLower(detailedChannel.Name)] = true
+				}
+			})
+		}()
 	}
 
-	// Set up selection handler for channel details
-	channelsList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
-		if index >= 0 && index < len(channels) {
-			channel := channels[index]
+	// Details are only fetched again when the selection moves to another
+	// channel, live updates keep the member list current
+	channelsTable.onSelect = showChannelDetails
+	channelsTable.onChange = func() {
+		if channel, ok := tableChannel(channelsTable, channelsTable.Selected()); ok && channel.Members != nil {
+			channelDetailsView.SetText(formatChannelDetails(channel))
+		}
+	}
 
-			// Show loading message
-			channelDetailsView.SetText("Loading channel details...")
+	// Show loading message
+	channelsTable.SetTitle("Loading channels")
+	channelsTable.SetItems(channelItems(nil))
+	channelDetailsView.SetText("Loading channels...")
 
-			// Get detailed channel info in background
-			go func() {
-				client, err := rpc.NewRPCClient(config)
-				if err != nil {
-					app.QueueUpdateDraw(func() {
-						channelDetailsView.SetText(fmt.Sprintf("Error creating RPC client: %v", err))
-					})
+	// Events are held back until the initial load is done. Both are only
+	// touched inside channelsTable.Update.
+	loading := true
+	var pending []rpc.Event
+	// Each event is applied to the channels it changes only
+	applyEvents := func(m *tableModel, events []rpc.Event) {
+		for _, event := range events {
+			var channels []rpc.ChannelInfo
+			add := func(name string) {
+				i := m.find(name)
+				if i < 0 {
 					return
 				}
-				defer client.Close()
-
-				if err := client.Connect(); err != nil {
-					app.QueueUpdateDraw(func() {
-						channelDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
-					})
-					return
+				channel := m.items.(channelItems)[i]
+				for _, added := range channels {
+					if strings.EqualFold(added.Name, channel.Name) {
+						return
+					}
 				}
-
-				detailedChannel, err := client.GetChannelDetails(channel.Name)
-				if err != nil {
-					app.QueueUpdateDraw(func() {
-						channelDetailsView.SetText(fmt.Sprintf("Error getting channel details: %v", err))
-					})
-					return
+				channels = append(channels, channel)
+			}
+			for _, name := range rpc.EventChannels(event) {
+				add(name)
+			}
+			if event.Type == rpc.EventUserQuit || event.Type == rpc.EventNickChange {
+				for name := range withMembers {
+					add(name)
 				}
+			}
 
-				// Format users list
-				usersStr := ""
-				if len(detailedChannel.Users) > 0 {
-					var colored []string
-					for _, user := range detailedChannel.Users {
-						colored = append(colored, "  [blue]"+user+"[white]")
-					}
-					usersStr = "\n" + strings.Join(colored, "\n")
-				} else {
-					usersStr = "\n  None"
+			changed, ok := rpc.ApplyChannelEvent(channels, event)
+			if !ok {
+				continue
+			}
+			for _, channel := range channels {
+				if channelsIndex(changed, channel.Name) < 0 {
+					m.remove(channel.Name)
+					delete(withMembers, strings.ToLower(channel.Name))
 				}
+			}
+			for _, channel := range changed {
+				m.put(channel.Name, channel)
+			}
+		}
+	}
+	// stopLoading stops holding events back when there are no channels to apply them to
+	stopLoading := func() {
+		channelsTable.Update(func(m *tableModel) {
+			if current() {
+				loading = false
+				pending = nil
+			}
+		})
+	}
+	var live *liveView
+	live = startLiveView(app, config, statusView, channelActionKeys+" | "+tableKeys, func(events []rpc.Event) {
+		channelsTable.Update(func(m *tableModel) {
+			if !current() {
+				return
+			}
+			if loading {
+				pending = append(pending, events...)
+				return
+			}
+			applyEvents(m, events)
+		})
+	}, func() {
+		loadChannelsList(app, pages, channelsTable, channelDetailsView, statusView, config, onSelectUser)
+	})
 
-				details := fmt.Sprintf(
-					"[green]Name:[white]\n  %s\n"+
-						"[green]Topic:[white]\n  %s\n"+
-						"[green]Modes:[white]\n  %s\n"+
-						"[green]Created:[white]\n  %s\n"+
-						"[green]Users:[white]%s",
-					detailedChannel.Name,
-					detailedChannel.Topic,
-					detailedChannel.Modes,
-					time.Unix(detailedChannel.Created, 0).Format("2006-01-02 15:04:05"),
-					usersStr)
-
-				// Update UI in main thread
-				app.QueueUpdateDraw(func() {
-					channelDetailsView.SetText(details)
-				})
-			}()
+	channelsTable.table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		if channelsTable.handleKey(event.Rune()) {
+			return nil
+		}
+		if event.Rune() == 'r' {
+			loadChannelsList(app, pages, channelsTable, channelDetailsView, statusView, config, onSelectUser)
+			return nil
 		}
+		channel, ok := tableChannel(channelsTable, channelsTable.Selected())
+		if !ok {
+			return event
+		}
+		reload := func() {
+			showChannelDetails(channel.Name)
+		}
+		if handleChannelActionKey(app, pages, config, channel, event.Rune(), reload, onSelectUser) {
+			return nil
+		}
+		return event
 	})
 
-	// Show first channel details by default - but need to load detailed info
-	if len(channels) > 0 {
-		channel := channels[0]
+	// Fetch in the background, selecting the first channel loads its details
+	go func() {
+		client, err := rpc.Sessions.Get(config).Client()
+		if err != nil {
+			stopLoading()
+			app.QueueUpdateDraw(func() {
+				if current() {
+					channelDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				}
+			})
+			return
+		}
 
-		// Show loading message initially
-		channelDetailsView.SetText("Select a channel to view details.")
+		channels, err := client.GetChannels()
+		if err != nil {
+			stopLoading()
+			app.QueueUpdateDraw(func() {
+				if current() {
+					channelDetailsView.SetText(fmt.Sprintf("Error fetching channels: %v", err))
+				}
+			})
+			return
+		}
 
-		// Auto-load first channel details
-		go func() {
-			client, err := rpc.NewRPCClient(config)
-			if err != nil {
-				app.QueueUpdateDraw(func() {
-					channelDetailsView.SetText(fmt.Sprintf("Error creating RPC client: %v", err))
-				})
+		// Events that came in while fetching are applied on top
+		channelsTable.Update(func(m *tableModel) {
+			if !current() {
 				return
 			}
-			defer client.Close()
-
-			if err := client.Connect(); err != nil {
-				app.QueueUpdateDraw(func() {
-					channelDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
-				})
+			loading = false
+			events := pending
+			pending = nil
+			m.replace(channelItems(channels))
+			applyEvents(m, events)
+		})
+		app.QueueUpdateDraw(func() {
+			if !current() {
 				return
 			}
-
-			detailedChannel, err := client.GetChannelDetails(channel.Name)
-			if err != nil {
-				app.QueueUpdateDraw(func() {
-					channelDetailsView.SetText(fmt.Sprintf("Error getting channel details: %v", err))
-				})
-				return
+			channelsTable.SetTitle("Channels")
+			live.touch()
+			if len(channels) == 0 {
+				channelDetailsView.SetText("No channels.")
 			}
+		})
+	}()
+}
 
-			// Format users list
-			usersStr := ""
-			if len(detailedChannel.Users) > 0 {
-				var colored []string
-				for _, user := range detailedChannel.Users {
-					colored = append(colored, "  [blue]"+user+"[white]")
-				}
-				usersStr = "\n" + strings.Join(colored, "\n")
-			} else {
-				usersStr = "\n  None"
-			}
+// tableChannel returns the channel called name in channelsTable. Like users,
+// live updates replace channels rather than change them.
+func tableChannel(channelsTable *listTable, name string) (rpc.ChannelInfo, bool) {
+	var channel rpc.ChannelInfo
+	found := false
+	channelsTable.Read(func(m *tableModel) {
+		if i := m.find(name); i >= 0 {
+			channel = m.items.(channelItems)[i]
+			found = true
+		}
+	})
+	return channel, found
+}
 
-			details := fmt.Sprintf(
-				"[green]Name:[white]\n  %s\n"+
-					"[green]Topic:[white]\n  %s\n"+
-					"[green]Modes:[white]\n  %s\n"+
-					"[green]Created:[white]\n  %s\n"+
-					"[green]Users:[white]%s",
-				detailedChannel.Name,
-				detailedChannel.Topic,
-				detailedChannel.Modes,
-				time.Unix(detailedChannel.Created, 0).Format("2006-01-02 15:04:05"),
-				usersStr)
-
-			// Update UI in main thread
-			app.QueueUpdateDraw(func() {
-				channelDetailsView.SetText(details)
-			})
-		}()
+// channelsIndex returns the index of the channel called name in channels, or -1
+func channelsIndex(channels []rpc.ChannelInfo, name string) int {
+	if name == "" {
+		return -1
+	}
+	for i, channel := range channels {
+		if strings.EqualFold(channel.Name, name) {
+			return i
+		}
 	}
+	return -1
 }
 
-func remoteServersPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
-	// TODO: Implement servers page
-	modal := tview.NewModal().
-		SetText("Servers view coming soon...").
-		AddButtons([]string{"OK"}).
-		SetDoneFunc(func(int, string) {
-			pages.RemovePage("servers_page")
-		})
-	pages.AddPage("servers_page", modal, true, true)
-}
+// subscribeLogEntries streams log entries as they are logged through a log.subscribe on the shared session
+func subscribeLogEntries(ctx context.Context, config *rpc.RPCConfig) (<-chan *rpc.FileLogEntry, error) {
+	events, err := rpc.Sessions.Get(config).Subscribe(ctx, []string{"all"})
+	if err != nil {
+		return nil, err
+	}
 
-func remoteServerBansPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
-	// TODO: Implement server bans page
-	modal := tview.NewModal().
-		SetText("Server Bans view coming soon...").
-		AddButtons([]string{"OK"}).
-		SetDoneFunc(func(int, string) {
-			pages.RemovePage("bans_page")
-		})
-	pages.AddPage("bans_page", modal, true, true)
+	logChan := make(chan *rpc.FileLogEntry, 100)
+	go func() {
+		defer close(logChan)
+		for event := range events {
+			if event.Type == rpc.EventResync {
+				// Entries dropped while the page fell behind can not be fetched again
+				continue
+			}
+			select {
+			case logChan <- event.Entry:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return logChan, nil
 }
 
-func reconfigureRPC(app *tview.Application, pages *tview.Pages, buildDir string) {
-	// Remove current config and show setup
-	rpcConfig, _ := rpc.LoadRPCConfig()
-	if rpcConfig != nil {
-		// Remove config file
-		home, _ := os.UserHomeDir()
-		configPath := filepath.Join(home, ".unrealircd_rpc_config")
-		os.Remove(configPath)
+// reconfigureRPC edits the connection settings of rpcConfig, the profile in use
+func reconfigureRPC(app *tview.Application, pages *tview.Pages, rpcConfig *rpc.RPCConfig, buildDir string) {
+	if rpcConfig.Detected {
+		showMessageModal(pages, "rpc_local_modal", "Connected to this server over its RPC socket, there is nothing to configure.\n\nAdd a remote server under Profiles.")
+		return
 	}
-	pages.RemovePage("remote_control_menu")
-	RemoteControlMenuPage(app, pages, buildDir)
+	showRPCSetupModal(app, pages, buildDir, rpcConfig)
 }
 
 func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, buildDir string) {
 	var updateLogDisplay func() // Function to update the log display
 
-	debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	fmt.Fprintf(debugFile, "[DEBUG] remoteLogStreamingPage called\n")
-	debugFile.Close()
-
-	client, err := rpc.NewRPCClient(config)
+	client, err := rpc.Sessions.Get(config).Client()
 	if err != nil {
-		debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Failed to create RPC client: %v\n", err)
-		debugFile.Close()
-
 		errorModal := tview.NewModal().
-			SetText(fmt.Sprintf("Failed to create RPC client: %v", err)).
+			SetText(fmt.Sprintf("Failed to connect to RPC server: %v", err)).
 			AddButtons([]string{"OK"}).
 			SetDoneFunc(func(int, string) {
 				pages.RemovePage("rpc_client_error_modal")
@@ -864,10 +1501,6 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 		return
 	}
 
-	debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-	fmt.Fprintf(debugFile, "[DEBUG] RPC client created successfully\n")
-	debugFile.Close()
-
 	// Create the log streaming page
 	flex := tview.NewFlex().SetDirection(tview.FlexRow)
 
@@ -896,7 +1529,12 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 	// Initial loading message
 	logList.AddItem("Loading logs...", "", 0, nil)
 
+	// Where the entries come from, and why they stopped coming if they did
+	logStatus := tview.NewTextView()
+	logStatus.SetDynamicColors(true)
+
 	logFlex.AddItem(logList, 0, 1, false)
+	logFlex.AddItem(logStatus, 1, 0, false)
 
 	contentFlex.AddItem(logFlex, 0, 1, false)
 
@@ -917,11 +1555,6 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 		// Create a local copy of level to avoid closure capture issues
 		levelCopy := level
 		levelForm.AddCheckbox(level, true, func(checked bool) {
-			// Debug: log the checkbox change
-			debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			fmt.Fprintf(debugFile, "[DEBUG] Checkbox %s changed to %v\n", levelCopy, checked)
-			debugFile.Close()
-
 			// This will trigger filtering update
 			selectedLevelsMutex.Lock()
 			if checked {
@@ -935,18 +1568,12 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 				}
 				if !found {
 					selectedLevels = append(selectedLevels, levelCopy)
-					debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-					fmt.Fprintf(debugFile, "[DEBUG] Added %s to selectedLevels: %v\n", levelCopy, selectedLevels)
-					debugFile.Close()
 				}
 			} else {
 				// Remove from selected levels
 				for i, l := range selectedLevels {
 					if l == levelCopy {
 						selectedLevels = append(selectedLevels[:i], selectedLevels[i+1:]...)
-						debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-						fmt.Fprintf(debugFile, "[DEBUG] Removed %s from selectedLevels: %v\n", levelCopy, selectedLevels)
-						debugFile.Close()
 						break
 					}
 				}
@@ -997,12 +1624,85 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 			}
 		})
 
-	searchFlex.AddItem(searchInput, 3, 0, false)
+	searchHelp := tview.NewTextView()
+	searchHelp.SetDynamicColors(true)
+	searchHelp.SetWordWrap(true)
+	searchHelp.SetText("[gray]AND, OR, NOT/-, (), \"phrase\", /regex/i, field:value (level, subsystem, event, nick, ip, msg)[-]")
+
+	searchFlex.AddItem(searchInput, 2, 0, false)
+	searchFlex.AddItem(searchHelp, 0, 1, false)
 
 	controlsFlex.AddItem(searchFlex, 0, 1, false)
 
+	// Subsystem, event, source, client and time filters, set through the Filters modal
+	var extraFilter rpc.LogFilter
+	var extraFilterMutex sync.Mutex
+
+	filterSummary := tview.NewTextView()
+	filterSummary.SetDynamicColors(true)
+	filterSummary.SetWordWrap(true)
+	filterSummary.SetBorder(true).SetTitle("Active Filters")
+	filterSummary.SetText("None")
+
+	controlsFlex.AddItem(filterSummary, 0, 1, false)
+
+	// buildLogFilter combines the checkboxes, the search input and the extra filters
+	buildLogFilter := func() rpc.LogFilter {
+		extraFilterMutex.Lock()
+		filter := extraFilter
+		extraFilterMutex.Unlock()
+
+		selectedLevelsMutex.Lock()
+		filter.Levels = append([]string(nil), selectedLevels...)
+		selectedLevelsMutex.Unlock()
+
+		filter.Search = strings.TrimSpace(searchInput.GetText())
+		return filter
+	}
+
+	// applyLogFilter replaces the whole filter, from a preset or the Filters modal
+	applyLogFilter := func(filter rpc.LogFilter, setLevels bool) {
+		extraFilterMutex.Lock()
+		extraFilter = filter
+		extraFilter.Levels = nil
+		extraFilter.Search = ""
+		extraFilterMutex.Unlock()
+
+		if setLevels {
+			wanted := filter.Levels
+			if len(wanted) == 0 {
+				wanted = levels
+			}
+			selectedLevelsMutex.Lock()
+			selectedLevels = selectedLevels[:0]
+			for _, level := range levels {
+				checked := false
+				for _, w := range wanted {
+					if strings.EqualFold(w, level) {
+						checked = true
+						break
+					}
+				}
+				if checked {
+					selectedLevels = append(selectedLevels, level)
+				}
+				// Set directly, SetChecked does not call the changed func
+				levelForm.GetFormItemByLabel(level).(*tview.Checkbox).SetChecked(checked)
+			}
+			selectedLevelsMutex.Unlock()
+		}
+
+		// Setting the text starts the debounced update
+		if searchInput.GetText() != filter.Search {
+			searchInput.SetText(filter.Search)
+		} else {
+			go updateLogDisplay()
+		}
+	}
+
 	// Channel for log events
 	stopChan := make(chan bool, 1)
+	streamCtx, cancelStream := context.WithCancel(context.Background()) // Ends an RPC log subscription
 	var streamingGoroutineRunning bool
 	var allLogEntries []*rpc.FileLogEntry      // Store all log entries
 	var filteredLogEntries []*rpc.FileLogEntry // Store filtered entries for display
@@ -1014,94 +1714,46 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 		logEntriesMutex.Lock()
 		defer logEntriesMutex.Unlock()
 
-		// Get current search text
-		searchText := strings.ToLower(searchInput.GetText())
+		filter := buildLogFilter()
 
-		// Debug: log total entries
-		debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] updateLogDisplay: allLogEntries has %d entries\n", len(allLogEntries))
-		debugFile.Close()
+		summary := formatLogFilter(filter)
+		compiled, err := filter.Compile(time.Now())
+		if err != nil {
+			// Keep showing the previous results until the filter is valid again
+			app.QueueUpdateDraw(func() {
+				filterSummary.SetText(fmt.Sprintf("[red]%s[-]\n%s", tview.Escape(err.Error()), summary))
+			})
+			return
+		}
 
-		// Filter entries based on selected levels and search text
+		// Filter entries based on selected levels, search expression and extra filters
 		filteredLogEntries = []*rpc.FileLogEntry{}
 
-		// Debug: log selected levels
-		selectedLevelsMutex.Lock()
-		debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Selected levels: %v\n", selectedLevels)
-		debugFile.Close()
-		selectedLevelsMutex.Unlock()
-
-		for _, entry := range allLogEntries {
-			// Check level filter
-			selectedLevelsMutex.Lock()
-			levelSelected := false
-			entryLevelLower := strings.ToLower(entry.Level)
-			for _, selectedLevel := range selectedLevels {
-				if strings.ToLower(selectedLevel) == entryLevelLower {
-					levelSelected = true
-					break
-				}
-			}
-			selectedLevelsMutex.Unlock()
-
-			// Debug: log level check
-			debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			fmt.Fprintf(debugFile, "[DEBUG] Entry level '%s' (%s), selected: %v\n", entry.Level, entryLevelLower, levelSelected)
-			debugFile.Close()
-
-			if !levelSelected {
-				continue
-			}
-
-			// Check search filter
-			if searchText != "" {
-				searchableText := strings.ToLower(fmt.Sprintf("%s %s %s %s", entry.Level, entry.Subsystem, entry.EventID, entry.Msg))
-				if !strings.Contains(searchableText, searchText) {
-					continue
+		// An empty level list would match every level, but no checked box means nothing is shown
+		if len(filter.Levels) > 0 {
+			for _, entry := range allLogEntries {
+				if compiled.Match(entry) {
+					filteredLogEntries = append(filteredLogEntries, entry)
 				}
 			}
-
-			filteredLogEntries = append(filteredLogEntries, entry)
 		}
 
-		// Debug: log filtered count
-		debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Filtered to %d entries\n", len(filteredLogEntries))
-		debugFile.Close()
-
 		// Update UI
 		app.QueueUpdateDraw(func() {
+			filterSummary.SetText(summary)
 			logList.Clear()
 			addedCount := 0
 			for _, logEntry := range filteredLogEntries {
 				timestamp := parseLogTimestamp(logEntry.Timestamp)
 				logTime := timestamp.Format("15:04:05")
 
-				var levelColor string
-				switch strings.ToLower(logEntry.Level) {
-				case "fatal", "error":
-					levelColor = "[red]"
-				case "warning", "warn":
-					levelColor = "[yellow]"
-				case "info":
-					levelColor = "[green]"
-				case "debug":
-					levelColor = "[blue]"
-				default:
-					levelColor = "[white]"
-				}
+				levelColor := logLevelColor(logEntry.Level)
 
 				mainText := fmt.Sprintf("[%s] %s%s[-]: %s: %s", logTime, levelColor, logEntry.Level, logEntry.Subsystem, logEntry.Msg)
 				logList.AddItem(mainText, "", 0, nil)
 				addedCount++
 			}
 
-			// Debug: log how many items were added
-			debugFile, _ = os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			fmt.Fprintf(debugFile, "[DEBUG] Added %d items to logList (filteredLogEntries has %d)\n", addedCount, len(filteredLogEntries))
-			debugFile.Close()
-
 			if len(filteredLogEntries) > 0 {
 				logList.SetCurrentItem(len(filteredLogEntries) - 1) // Show latest log at bottom
 			}
@@ -1117,10 +1769,6 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 	// Old buttons removed - now auto-starting
 
 	backBtn := tview.NewButton("Back").SetSelectedFunc(func() {
-		debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Back button pressed, streamingGoroutineRunning: %v\n", streamingGoroutineRunning)
-		debugFile.Close()
-
 		// Cancel any pending search timer
 		if searchTimer != nil {
 			searchTimer.Stop()
@@ -1128,6 +1776,7 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 		}
 
 		// Stop streaming if active
+		cancelStream()
 		if streamingGoroutineRunning {
 			select {
 			case stopChan <- true:
@@ -1164,64 +1813,65 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 		entry := filteredLogEntries[selectedIndex]
 		logEntriesMutex.Unlock()
 
-		// Parse the raw JSON to get all fields including nested objects
-		var entryData map[string]interface{}
-		if err := json.Unmarshal([]byte(entry.RawJSON), &entryData); err != nil {
-			errorModal := tview.NewModal().
-				SetText("Error parsing log entry JSON: " + err.Error()).
-				AddButtons([]string{"OK"}).
-				SetDoneFunc(func(buttonIndex int, buttonLabel string) {
-					pages.RemovePage("json_error_modal")
-				})
-			pages.AddPage("json_error_modal", errorModal, true, true)
-			return
-		}
+		showLogEntryInspector(pages, entry, fmt.Sprintf("Log Entry %d", selectedIndex+1))
+	})
 
-		// Format as tree structure
-		formattedText := formatJSONTree(entryData, "")
-
-		// Create a text view to display the formatted JSON
-		jsonView := tview.NewTextView()
-		jsonView.SetBorder(true).SetTitle(fmt.Sprintf("Log Entry %d", selectedIndex+1))
-		jsonView.SetDynamicColors(true) // Enable color tags for syntax highlighting
-		jsonView.SetWordWrap(true)
-		jsonView.SetScrollable(true)
-		jsonView.SetText(formattedText)
-
-		// Create modal with the JSON view
-		jsonFlex := tview.NewFlex().SetDirection(tview.FlexRow)
-		jsonFlex.AddItem(jsonView, 0, 1, true)
-		closeBtn := tview.NewButton("Close").SetSelectedFunc(func() {
-			pages.RemovePage("json_inspect_modal")
-		})
-		jsonFlex.AddItem(closeBtn, 3, 0, false)
+	// exportLogView writes what the log list shows to a file
+	exportLogView := func() {
+		logEntriesMutex.Lock()
+		entries := append([]*rpc.FileLogEntry(nil), filteredLogEntries...)
+		logEntriesMutex.Unlock()
+
+		showLogExportModal(app, pages, entries)
+	}
 
-		pages.AddPage("json_inspect_modal", jsonFlex, true, true)
+	logList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'e' {
+			exportLogView()
+			return nil
+		}
+		return event
 	})
 
 	// Button flex
 	actionsFlex := tview.NewFlex().SetDirection(tview.FlexRow)
 	actionsFlex.SetBorder(true).SetTitle("Actions")
 	actionsFlex.AddItem(inspectBtn, 3, 0, false)
+	actionsFlex.AddItem(tview.NewButton("Filters").SetSelectedFunc(func() {
+		showLogFilterModal(app, pages, buildLogFilter(), func(filter rpc.LogFilter) {
+			applyLogFilter(filter, false)
+		})
+	}), 3, 0, false)
+	actionsFlex.AddItem(tview.NewButton("Presets").SetSelectedFunc(func() {
+		showLogPresetsModal(app, pages, buildLogFilter(), func(filter rpc.LogFilter) {
+			applyLogFilter(filter, true)
+		})
+	}), 3, 0, false)
+	actionsFlex.AddItem(tview.NewButton("Export (e)").SetSelectedFunc(exportLogView), 3, 0, false)
+	actionsFlex.AddItem(tview.NewButton("Search History").SetSelectedFunc(func() {
+		remoteLogHistoryPage(app, pages, buildDir)
+	}), 3, 0, false)
 	actionsFlex.AddItem(backBtn, 3, 0, false)
 
-	controlsFlex.AddItem(actionsFlex, 0, 1, false)
+	controlsFlex.AddItem(actionsFlex, 20, 0, false)
 
-	contentFlex.AddItem(controlsFlex, 30, 0, false)
+	contentFlex.AddItem(controlsFlex, 36, 0, false)
 
 	flex.AddItem(contentFlex, 0, 1, false)
 
 	// Auto-start streaming
 	go func() {
-		debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		fmt.Fprintf(debugFile, "[DEBUG] Auto-starting file-based log streaming\n")
-		debugFile.Close()
-
 		streamingGoroutineRunning = true
 		allLogEntries = []*rpc.FileLogEntry{} // Start with empty list
 
-		// Start tailing the log file with all sources
-		logChan, err := client.TailLogFile(buildDir, []string{"*"})
+		// Start tailing the log file with all sources, or stream over RPC if the
+		// server does not run here and there is no log file to read
+		source := "Following logs/ircd.json.log"
+		logChan, logErrs, err := client.TailLogFile(streamCtx, buildDir, []string{"*"}, rpc.TailOptions{History: maxLogLines})
+		if err != nil {
+			source = "Streaming over RPC, no log file here"
+			logChan, err = subscribeLogEntries(streamCtx, config)
+		}
 		if err != nil {
 			app.QueueUpdateDraw(func() {
 				errorModal := tview.NewModal().
@@ -1235,6 +1885,20 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 			return
 		}
 
+		app.QueueUpdateDraw(func() {
+			logStatus.SetText(fmt.Sprintf("[gray]%s[-]", source))
+		})
+
+		// A failure is kept in the status until the page is opened again
+		var failures []string
+		showLogFailure := func(err error) {
+			failures = append(failures, err.Error())
+			text := fmt.Sprintf("[red]%s[-]", tview.Escape(strings.Join(failures, "; ")))
+			app.QueueUpdateDraw(func() {
+				logStatus.SetText(text)
+			})
+		}
+
 		var historicTimer *time.Timer
 
 		go func() {
@@ -1243,9 +1907,6 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 					historicTimer.Stop()
 				}
 				streamingGoroutineRunning = false
-				debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-				fmt.Fprintf(debugFile, "[DEBUG] File-based log streaming goroutine exited\n")
-				debugFile.Close()
 			}()
 
 			var pendingEntries []*rpc.FileLogEntry
@@ -1254,18 +1915,16 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 			historicLogsDone := false
 			historicLoadTimeout := 500 * time.Millisecond // Wait 500ms after last historic log
 
-			debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-			fmt.Fprintf(debugFile, "[DEBUG] File-based streaming loop started\n")
-			debugFile.Close()
-
 			for {
 				select {
 				case entry, ok := <-logChan:
 					if !ok {
-						// Channel closed, exit
-						debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-						fmt.Fprintf(debugFile, "[DEBUG] Log channel closed, exiting\n")
-						debugFile.Close()
+						// Channel closed, exit with why it was when it was not stopped
+						if logErrs != nil {
+							for err := range logErrs {
+								showLogFailure(err)
+							}
+						}
 						return
 					}
 
@@ -1296,9 +1955,6 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 							// Update display with filtered logs
 							updateLogDisplay()
 
-							debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-							fmt.Fprintf(debugFile, "[DEBUG] Rendered %d historic logs at once\n", len(allLogEntries))
-							debugFile.Close()
 						}
 					})
 
@@ -1322,10 +1978,14 @@ func remoteLogStreamingPage(app *tview.Application, pages *tview.Pages, config *
 						lastUpdate = time.Now()
 					}
 
+				case err, ok := <-logErrs:
+					if !ok {
+						logErrs = nil // Only the entries are left to wait for
+						continue
+					}
+					showLogFailure(err)
+
 				case <-stopChan:
-					debugFile, _ := os.OpenFile("/tmp/debug.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-					fmt.Fprintf(debugFile, "[DEBUG] Received stop signal, exiting\n")
-					debugFile.Close()
 					return
 				}
 			}
@@ -0,0 +1,268 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// reputationSnapshotInterval is how often the reputation page takes a snapshot
+// while it is open, UnrealIRCd updates the scores every five minutes
+const reputationSnapshotInterval = 5 * time.Minute
+
+// reputationTrendLength is how many of the latest snapshots the trend shows
+const reputationTrendLength = 60
+
+// stopReputationSnapshots stops the snapshots of the reputation page shown last
+var stopReputationSnapshots func()
+
+// remoteReputationPage shows how the reputation of the connected users is
+// distributed, the low reputation users per server and the trend over the
+// snapshots taken, which are kept between runs
+func remoteReputationPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	if stopReputationSnapshots != nil {
+		stopReputationSnapshots()
+	}
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	histogramView := tview.NewTextView()
+	histogramView.SetBorder(true)
+	histogramView.SetTitle("Reputation Histogram")
+	histogramView.SetDynamicColors(true)
+	histogramView.SetText("Loading users...")
+
+	trendView := tview.NewTextView()
+	trendView.SetBorder(true)
+	trendView.SetTitle("Trend")
+	trendView.SetDynamicColors(true)
+	trendView.SetWordWrap(true)
+
+	rootNode := tview.NewTreeNode(fmt.Sprintf("Below %d", rpc.LowReputation)).SetColor(tcell.ColorYellow)
+	lowTree := tview.NewTreeView()
+	lowTree.SetRoot(rootNode)
+	lowTree.SetCurrentNode(rootNode)
+	lowTree.SetBorder(true)
+	lowTree.SetTitle("Low Reputation Users by Server")
+	lowTree.SetBorderColor(tcell.ColorBlue)
+
+	// Enter folds a server in or out
+	lowTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		if len(node.GetChildren()) > 0 {
+			node.SetExpanded(!node.IsExpanded())
+		}
+	})
+
+	// Fetch the users, record a snapshot and show everything again
+	refresh := func() {
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					histogramView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			users, err := client.GetUsers()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					histogramView.SetText(fmt.Sprintf("Error fetching users: %v", err))
+				})
+				return
+			}
+
+			now := time.Now()
+			snapshotErr := rpc.SaveReputationSnapshot(config.Target(), rpc.TakeReputationSnapshot(users, now))
+			snapshots, err := rpc.LoadReputationSnapshots(config.Target())
+			if snapshotErr == nil {
+				snapshotErr = err
+			}
+
+			app.QueueUpdateDraw(func() {
+				histogramView.SetText(formatReputationHistogram(rpc.ReputationHistogram(users), len(users)))
+				histogramView.SetTitle(fmt.Sprintf("Reputation Histogram - %s", now.Format("15:04:05")))
+				buildLowReputationTree(rootNode, rpc.LowReputationUsers(users))
+				if snapshotErr != nil {
+					trendView.SetText(fmt.Sprintf("[red]Error keeping snapshots: %v[-]", snapshotErr))
+				} else {
+					trendView.SetText(formatReputationTrend(snapshots))
+				}
+			})
+		}()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopReputationSnapshots = cancel
+	go func() {
+		ticker := time.NewTicker(reputationSnapshotInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				refresh()
+			}
+		}
+	}()
+
+	back := func() {
+		stopReputationSnapshots()
+		pages.RemovePage("remote_reputation")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	lowTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'r' {
+			refresh()
+			return nil
+		}
+		return event
+	})
+
+	leftFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	leftFlex.AddItem(histogramView, len(rpc.ReputationHistogram(nil))+4, 0, false)
+	leftFlex.AddItem(trendView, 0, 1, false)
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(leftFlex, 0, 1, false)
+	contentFlex.AddItem(lowTree, 0, 1, true)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(refresh), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter(fmt.Sprintf("Enter: Expand/Collapse | r: Refresh and Snapshot (every %s while open) | ESC: Main Menu", reputationSnapshotInterval)), 3, 0, false)
+
+	pages.AddPage("remote_reputation", flex, true, true)
+	app.SetFocus(lowTree)
+
+	refresh()
+}
+
+// buildLowReputationTree puts a node per server under root, holding its low reputation users
+func buildLowReputationTree(root *tview.TreeNode, servers []rpc.ServerUsers) {
+	root.ClearChildren()
+	total := 0
+	for i, server := range servers {
+		total += len(server.Users)
+		name := server.Server
+		if name == "" {
+			name = "(unknown server)"
+		}
+		serverNode := tview.NewTreeNode(fmt.Sprintf("%s (%d)", name, len(server.Users))).
+			SetColor(tcell.ColorGreen).
+			SetSelectable(true).
+			SetExpanded(i == 0) // Only the server with most of them is open at first
+		for _, user := range server.Users {
+			account := user.Account
+			if account == "" || account == "none" {
+				account = "-"
+			}
+			serverNode.AddChild(tview.NewTreeNode(fmt.Sprintf("%-16s rep %-3d %s  account %s", user.Nick, user.Reputation, user.IP, account)))
+		}
+		root.AddChild(serverNode)
+	}
+	root.SetText(fmt.Sprintf("Below %d: %d users on %d servers", rpc.LowReputation, total, len(servers)))
+}
+
+// formatReputationHistogram draws a bar per bucket, red for scores below rpc.LowReputation
+func formatReputationHistogram(buckets []rpc.ReputationBucket, users int) string {
+	const barWidth = 30
+
+	largest := 0
+	for _, bucket := range buckets {
+		largest = max(largest, bucket.Count)
+	}
+
+	var result strings.Builder
+	for _, bucket := range buckets {
+		width := 0
+		if largest > 0 {
+			width = (bucket.Count*barWidth + largest - 1) / largest
+		}
+		color := "green"
+		if bucket.Max >= 0 && bucket.Max < rpc.LowReputation {
+			color = "red"
+		}
+		share := 0.0
+		if users > 0 {
+			share = float64(bucket.Count) * 100 / float64(users)
+		}
+		result.WriteString(fmt.Sprintf("%9s [%s]%-*s[-] %d (%.1f%%)\n", bucket.Label, color, barWidth, strings.Repeat("█", width), bucket.Count, share))
+	}
+	return result.String()
+}
+
+// formatReputationTrend shows the latest snapshots as sparklines and the last few as numbers
+func formatReputationTrend(snapshots []rpc.ReputationSnapshot) string {
+	if len(snapshots) < 2 {
+		return fmt.Sprintf("A snapshot is taken every %s while this page is open, the trend shows once there are two.", reputationSnapshotInterval)
+	}
+	if len(snapshots) > reputationTrendLength {
+		snapshots = snapshots[len(snapshots)-reputationTrendLength:]
+	}
+
+	var users, low, median []float64
+	for _, snapshot := range snapshots {
+		users = append(users, float64(snapshot.Users))
+		low = append(low, lowShare(snapshot))
+		median = append(median, float64(snapshot.Median))
+	}
+
+	first, last := snapshots[0], snapshots[len(snapshots)-1]
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("[green]%d snapshots, %s to %s[-]\n\n",
+		len(snapshots), first.Time.Format("Jan 2 15:04"), last.Time.Format("Jan 2 15:04")))
+	result.WriteString(fmt.Sprintf("Users    %s %d -> %d\n", sparkline(users), first.Users, last.Users))
+	result.WriteString(fmt.Sprintf("Below %-2d %s %.1f%% -> %.1f%%\n", rpc.LowReputation, sparkline(low), lowShare(first), lowShare(last)))
+	result.WriteString(fmt.Sprintf("Median   %s %d -> %d\n\n", sparkline(median), first.Median, last.Median))
+
+	result.WriteString("[yellow]Time          Users  Below  Median[-]\n")
+	recent := snapshots[max(len(snapshots)-8, 0):]
+	for i := len(recent) - 1; i >= 0; i-- {
+		s := recent[i]
+		result.WriteString(fmt.Sprintf("%-12s %6d %5.1f%% %7d\n", s.Time.Format("Jan 2 15:04"), s.Users, lowShare(s), s.Median))
+	}
+	return result.String()
+}
+
+// lowShare returns the percentage of users below rpc.LowReputation in a snapshot
+func lowShare(snapshot rpc.ReputationSnapshot) float64 {
+	if snapshot.Users == 0 {
+		return 0
+	}
+	return float64(snapshot.Low) * 100 / float64(snapshot.Users)
+}
+
+// sparkline draws values as a row of block characters, scaled between their minimum and maximum
+func sparkline(values []float64) string {
+	blocks := []rune("▁▂▃▄▅▆▇█")
+	if len(values) == 0 {
+		return ""
+	}
+	low, high := values[0], values[0]
+	for _, v := range values {
+		low = min(low, v)
+		high = max(high, v)
+	}
+	var line strings.Builder
+	for _, v := range values {
+		level := 0
+		if high > low {
+			level = int((v - low) / (high - low) * float64(len(blocks)-1))
+		}
+		line.WriteRune(blocks[level])
+	}
+	return line.String()
+}
@@ -0,0 +1,304 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Sort orders available on the server bans page, cycled with 's'
+var serverBanSortModes = []string{"type", "setter", "expiry"}
+
+func remoteServerBansPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	var bans []rpc.ServerBanInfo
+	sortMode := 0
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	bansList := tview.NewList()
+	bansList.SetBorder(true)
+	bansList.SetTitle("Server Bans")
+	bansList.SetBorderColor(tcell.ColorBlue)
+
+	banDetailsView := tview.NewTextView()
+	banDetailsView.SetBorder(true)
+	banDetailsView.SetTitle("Ban Details")
+	banDetailsView.SetDynamicColors(true)
+	banDetailsView.SetWordWrap(true)
+	banDetailsView.SetText("Loading server bans...")
+
+	showBanDetails := func(index int) {
+		if index < 0 || index >= len(bans) {
+			banDetailsView.SetText("No server bans.")
+			return
+		}
+		banDetailsView.SetText(formatServerBanDetails(bans[index]))
+	}
+
+	// Rebuild the list from bans in the current sort order
+	renderBans := func() {
+		sortServerBans(bans, serverBanSortModes[sortMode])
+		bansList.SetTitle(fmt.Sprintf("Server Bans (%d) - sorted by %s", len(bans), serverBanSortModes[sortMode]))
+		bansList.Clear()
+		for _, ban := range bans {
+			mainText := fmt.Sprintf("%-7s %s", ban.Type, tview.Escape(ban.Name))
+			secondaryText := fmt.Sprintf("  by %s, expires %s - %s", tview.Escape(ban.Setby), formatBanExpiry(ban), tview.Escape(ban.Reason))
+			bansList.AddItem(mainText, secondaryText, 0, nil)
+		}
+		if len(bans) > 0 {
+			bansList.SetCurrentItem(0)
+		}
+		showBanDetails(bansList.GetCurrentItem())
+	}
+
+	var loadBans func()
+	loadBans = func() {
+		banDetailsView.SetText("Loading server bans...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					banDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			newBans, err := client.GetServerBans()
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					banDetailsView.SetText(fmt.Sprintf("Error fetching server bans: %v", err))
+					return
+				}
+				bans = newBans
+				renderBans()
+			})
+		}()
+	}
+
+	bansList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showBanDetails(index)
+	})
+
+	addBan := func() {
+		showAddServerBanModal(app, pages, config, "", loadBans)
+	}
+
+	deleteBan := func() {
+		index := bansList.GetCurrentItem()
+		if index < 0 || index >= len(bans) {
+			return
+		}
+		ban := bans[index]
+		if ban.SetInConfig {
+			showMessageModal(pages, "server_ban_config_modal", fmt.Sprintf("The %s on %s is set in the configuration file and cannot be removed over RPC.", ban.Type, ban.Name))
+			return
+		}
+		showConfirmModal(pages, "server_ban_delete_modal", fmt.Sprintf("Remove %s on %s?", ban.Type, ban.Name), func() {
+			go func() {
+				client, err := rpc.Sessions.Get(config).Client()
+				if err == nil {
+					err = client.DeleteServerBan(ban.Type, ban.Name)
+				}
+				app.QueueUpdateDraw(func() {
+					if err != nil {
+						showMessageModal(pages, "server_ban_error_modal", fmt.Sprintf("Error removing ban: %v", err))
+						return
+					}
+					loadBans()
+				})
+			}()
+		})
+	}
+
+	cycleSort := func() {
+		sortMode = (sortMode + 1) % len(serverBanSortModes)
+		renderBans()
+	}
+
+	back := func() {
+		pages.RemovePage("remote_server_bans")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	bansList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			addBan()
+			return nil
+		case 'd':
+			deleteBan()
+			return nil
+		case 's':
+			cycleSort()
+			return nil
+		case 'r':
+			loadBans()
+			return nil
+		}
+		if event.Key() == tcell.KeyDelete {
+			deleteBan()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(bansList, 0, 2, true)
+	contentFlex.AddItem(banDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Add Ban").SetSelectedFunc(addBan), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Delete Ban").SetSelectedFunc(deleteBan), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Sort").SetSelectedFunc(cycleSort), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadBans), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("a: Add | d: Delete | s: Sort (type/setter/expiry) | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_server_bans", flex, true, true)
+	app.SetFocus(bansList)
+
+	loadBans()
+}
+
+// showAddServerBanModal shows the form for adding a server ban, mask is prefilled if not empty
+func showAddServerBanModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, mask string, onAdded func()) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Add Server Ban")
+	form.SetBackgroundColor(tcell.ColorDefault)
+
+	form.AddDropDown("Type:", rpc.ServerBanTypes, 0, nil)
+	form.AddInputField("Mask:", mask, 40, nil, nil)
+	form.AddInputField("Duration:", "1d", 20, nil, nil)
+	form.AddInputField("Reason:", "", 40, nil, nil)
+
+	form.AddButton("Add", func() {
+		_, banType := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		name := strings.TrimSpace(form.GetFormItem(1).(*tview.InputField).GetText())
+		duration := strings.TrimSpace(form.GetFormItem(2).(*tview.InputField).GetText())
+		reason := strings.TrimSpace(form.GetFormItem(3).(*tview.InputField).GetText())
+
+		if name == "" || reason == "" {
+			showMessageModal(pages, "server_ban_validation_modal", "Mask and reason are required.")
+			return
+		}
+
+		// Show who the ban hits before it is set
+		question := fmt.Sprintf("Add %s on %s?", banType, name)
+		showBanMatchConfirm(app, pages, config, banType, name, question, func() {
+			go func() {
+				client, err := rpc.Sessions.Get(config).Client()
+				if err == nil {
+					err = client.AddServerBan(banType, name, duration, reason)
+				}
+				app.QueueUpdateDraw(func() {
+					if err != nil {
+						showMessageModal(pages, "server_ban_error_modal", fmt.Sprintf("Error adding ban: %v", err))
+						return
+					}
+					pages.RemovePage("server_ban_add_modal")
+					if onAdded != nil {
+						onAdded()
+					}
+				})
+			}()
+		})
+	})
+
+	// Build the mask with a live preview of the users it matches
+	form.AddButton("Build Mask", func() {
+		_, banType := form.GetFormItem(0).(*tview.DropDown).GetCurrentOption()
+		maskField := form.GetFormItem(1).(*tview.InputField)
+		showBanMaskBuilder(app, pages, config, "Server Ban Mask", banType, maskField.GetText(), nil, func(mask, _ string) {
+			maskField.SetText(mask)
+		})
+	})
+
+	form.AddButton("Cancel", func() {
+		pages.RemovePage("server_ban_add_modal")
+	})
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("server_ban_add_modal", centeredModal(form, 60, 13), true, true)
+}
+
+func formatServerBanDetails(ban rpc.ServerBanInfo) string {
+	typeDisplay := ban.Type
+	if ban.TypeString != "" {
+		typeDisplay = fmt.Sprintf("%s (%s)", ban.TypeString, ban.Type)
+	}
+
+	setAt := "Unknown"
+	if ban.CreatedAt > 0 {
+		setAt = time.Unix(ban.CreatedAt, 0).Format("2006-01-02 15:04:05")
+	}
+
+	source := "RPC / IRC"
+	if ban.SetInConfig {
+		source = "Configuration file"
+	}
+
+	return fmt.Sprintf(
+		"[green]Mask:[white]\n  %s\n"+
+			"[green]Type:[white]\n  %s\n"+
+			"[green]Reason:[white]\n  %s\n"+
+			"[green]Set By:[white]\n  %s\n"+
+			"[green]Set At:[white]\n  %s\n"+
+			"[green]Expires:[white]\n  %s\n"+
+			"[green]Duration:[white]\n  %s\n"+
+			"[green]Source:[white]\n  %s",
+		tview.Escape(ban.Name), typeDisplay, tview.Escape(ban.Reason), tview.Escape(ban.Setby), setAt, formatBanExpiry(ban), ban.DurationString, source)
+}
+
+func formatBanExpiry(ban rpc.ServerBanInfo) string {
+	return formatExpiry(ban.ExpireAt)
+}
+
+// formatExpiry formats the expiry time of a ban or exception, 0 meaning it never expires
+func formatExpiry(expireAt int64) string {
+	if expireAt == 0 {
+		return "never"
+	}
+	return time.Unix(expireAt, 0).Format("2006-01-02 15:04:05")
+}
+
+// sortServerBans sorts bans by the given mode, falling back to the mask so the order is stable
+func sortServerBans(bans []rpc.ServerBanInfo, mode string) {
+	sort.SliceStable(bans, func(i, j int) bool {
+		a, b := bans[i], bans[j]
+		switch mode {
+		case "type":
+			if a.Type != b.Type {
+				return a.Type < b.Type
+			}
+		case "setter":
+			if !strings.EqualFold(a.Setby, b.Setby) {
+				return strings.ToLower(a.Setby) < strings.ToLower(b.Setby)
+			}
+		case "expiry":
+			// Permanent bans (ExpireAt == 0) go last
+			if a.ExpireAt != b.ExpireAt {
+				if a.ExpireAt == 0 {
+					return false
+				}
+				if b.ExpireAt == 0 {
+					return true
+				}
+				return a.ExpireAt < b.ExpireAt
+			}
+		}
+		return a.Name < b.Name
+	})
+}
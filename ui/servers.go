@@ -0,0 +1,263 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func remoteServersPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	rootNode := tview.NewTreeNode("Network").SetColor(tcell.ColorYellow)
+	serverTree := tview.NewTreeView()
+	serverTree.SetRoot(rootNode)
+	serverTree.SetCurrentNode(rootNode)
+	serverTree.SetBorder(true)
+	serverTree.SetTitle("Server Links")
+	serverTree.SetBorderColor(tcell.ColorBlue)
+
+	serverDetailsView := tview.NewTextView()
+	serverDetailsView.SetBorder(true)
+	serverDetailsView.SetTitle("Server Details")
+	serverDetailsView.SetDynamicColors(true)
+	serverDetailsView.SetWordWrap(true)
+	serverDetailsView.SetText("Loading servers...")
+
+	serverTree.SetChangedFunc(func(node *tview.TreeNode) {
+		if server, ok := node.GetReference().(rpc.ServerInfo); ok {
+			serverDetailsView.SetText(formatServerDetails(server))
+		} else {
+			serverDetailsView.SetText("Select a server to view details.")
+		}
+	})
+
+	// Enter fetches fresh details for the selected server with server.get
+	serverTree.SetSelectedFunc(func(node *tview.TreeNode) {
+		server, ok := node.GetReference().(rpc.ServerInfo)
+		if !ok {
+			return
+		}
+		serverDetailsView.SetText("Loading server details...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					serverDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			detailedServer, err := client.GetServer(server.Name)
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					serverDetailsView.SetText(fmt.Sprintf("Error getting server details: %v", err))
+					return
+				}
+				// Keep the tree topology we already know if server.get did not include it
+				if detailedServer.Uplink == "" {
+					detailedServer.Uplink = server.Uplink
+				}
+				node.SetReference(*detailedServer)
+				serverDetailsView.SetText(formatServerDetails(*detailedServer))
+			})
+		}()
+	})
+
+	loadServers := func() {
+		serverDetailsView.SetText("Loading servers...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					serverDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			servers, err := client.GetServers()
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					serverDetailsView.SetText(fmt.Sprintf("Error fetching servers: %v", err))
+					return
+				}
+				buildServerTree(rootNode, servers)
+				rootNode.SetText(fmt.Sprintf("Network (%d servers)", len(servers)))
+				children := rootNode.GetChildren()
+				if len(children) > 0 {
+					serverTree.SetCurrentNode(children[0])
+					if server, ok := children[0].GetReference().(rpc.ServerInfo); ok {
+						serverDetailsView.SetText(formatServerDetails(server))
+					}
+				} else {
+					serverTree.SetCurrentNode(rootNode)
+					serverDetailsView.SetText("No servers found.")
+				}
+			})
+		}()
+	}
+
+	back := func() {
+		pages.RemovePage("remote_servers")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	serverTree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Rune() == 'r' {
+			loadServers()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(serverTree, 0, 1, true)
+	contentFlex.AddItem(serverDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadServers), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("Enter: Refresh Details | r: Reload | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_servers", flex, true, true)
+	app.SetFocus(serverTree)
+
+	loadServers()
+}
+
+// buildServerTree rebuilds the link topology under root, each server hanging off its uplink
+func buildServerTree(root *tview.TreeNode, servers []rpc.ServerInfo) {
+	root.ClearChildren()
+
+	known := make(map[string]bool)
+	for _, server := range servers {
+		known[server.Name] = true
+	}
+
+	children := make(map[string][]rpc.ServerInfo)
+	var roots []rpc.ServerInfo
+	for _, server := range servers {
+		// The server we are connected to has no uplink (or itself), treat unknown uplinks as roots too
+		if server.Uplink == "" || server.Uplink == server.Name || !known[server.Uplink] {
+			roots = append(roots, server)
+		} else {
+			children[server.Uplink] = append(children[server.Uplink], server)
+		}
+	}
+
+	byName := func(list []rpc.ServerInfo) {
+		sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	}
+	byName(roots)
+
+	visited := make(map[string]bool)
+	var addNode func(parent *tview.TreeNode, server rpc.ServerInfo)
+	addNode = func(parent *tview.TreeNode, server rpc.ServerInfo) {
+		if visited[server.Name] {
+			return
+		}
+		visited[server.Name] = true
+
+		node := tview.NewTreeNode(formatServerNodeText(server)).
+			SetReference(server).
+			SetSelectable(true)
+		switch {
+		case server.Ulined:
+			node.SetColor(tcell.ColorPurple)
+		case !server.Synced:
+			node.SetColor(tcell.ColorYellow)
+		default:
+			node.SetColor(tcell.ColorGreen)
+		}
+		parent.AddChild(node)
+
+		leaves := children[server.Name]
+		byName(leaves)
+		for _, leaf := range leaves {
+			addNode(node, leaf)
+		}
+	}
+
+	for _, server := range roots {
+		addNode(root, server)
+	}
+}
+
+func formatServerNodeText(server rpc.ServerInfo) string {
+	text := fmt.Sprintf("%s (%d users)", server.Name, server.Users)
+	if server.Ulined {
+		text += " (ulined)"
+	}
+	if !server.Synced {
+		text += " (syncing)"
+	}
+	return text
+}
+
+func formatServerDetails(server rpc.ServerInfo) string {
+	uplink := server.Uplink
+	if uplink == "" || uplink == server.Name {
+		uplink = "None (this is the server we are connected to)"
+	}
+
+	bootTime := "Unknown"
+	if server.BootTime > 0 {
+		bootTime = time.Unix(server.BootTime, 0).Format("2006-01-02 15:04:05")
+	}
+
+	tlsInfo := "Not using TLS"
+	if server.TLSCipher != "" {
+		tlsInfo = server.TLSCipher
+		if server.TLSCertFP != "" {
+			tlsInfo += "\n  Cert FP: " + server.TLSCertFP
+		}
+	}
+
+	return fmt.Sprintf(
+		"[green]Name:[white]\n  %s\n"+
+			"[green]Description:[white]\n  %s\n"+
+			"[green]Server ID:[white]\n  %s\n"+
+			"[green]Uplink:[white]\n  %s\n"+
+			"[green]Software:[white]\n  %s (protocol %d)\n"+
+			"[green]Users:[white]\n  %d\n"+
+			"[green]Uptime:[white]\n  %s (since %s)\n"+
+			"[green]U-Lined:[white]\n  %s\n"+
+			"[green]Synced:[white]\n  %s\n"+
+			"[green]TLS:[white]\n  %s",
+		server.Name, tview.Escape(server.Info), server.ID, uplink, server.Software, server.Protocol,
+		server.Users, formatUptime(server.Uptime), bootTime, yesNo(server.Ulined), yesNo(server.Synced), tlsInfo)
+}
+
+// formatUptime formats a number of seconds as e.g. "3d 4h 12m"
+func formatUptime(seconds int64) string {
+	if seconds <= 0 {
+		return "Unknown"
+	}
+	d := time.Duration(seconds) * time.Second
+	days := int64(d.Hours()) / 24
+	hours := int64(d.Hours()) % 24
+	minutes := int64(d.Minutes()) % 60
+	if days > 0 {
+		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
+	}
+	if hours > 0 {
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	}
+	return fmt.Sprintf("%dm", minutes)
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "Yes"
+	}
+	return "No"
+}
@@ -0,0 +1,238 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Matches listed per spamfilter in the results, the count is always complete
+const spamfilterBenchMaxListed = 25
+
+// spamfilterTestBenchPage tries spamfilters against sample text offline, before they are added to the network
+func spamfilterTestBenchPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	patternsArea := tview.NewTextArea()
+	patternsArea.SetBorder(true)
+	patternsArea.SetTitle("Spamfilters: <simple|regex> <targets> <pattern>")
+	patternsArea.SetPlaceholder("regex cpNn ^buy cheap .* now$\nsimple q *free money*\nsimple u *!*@*:spambot*")
+
+	samplesArea := tview.NewTextArea()
+	samplesArea.SetBorder(true)
+	samplesArea.SetTitle("Samples: one per line, [target:] text")
+	samplesArea.SetPlaceholder("hello everyone\nbuy cheap watches now\nquit: Get free money at example.com\nuser: spambot!bot@192.0.2.1:spambot 3000")
+
+	resultsView := tview.NewTextView()
+	resultsView.SetBorder(true)
+	resultsView.SetTitle("Results")
+	resultsView.SetDynamicColors(true)
+	resultsView.SetWordWrap(true)
+	resultsView.SetScrollable(true)
+	resultsView.SetText("Enter spamfilters and samples, then press Run (Ctrl-R).\n\n" +
+		"Targets: " + describeSpamfilterTargetLetters() + "\n\n" +
+		"Samples without a target are channel messages. Target names like quit:, part:, away: and user: " +
+		"can be used too, user samples are matched as nick!user@host:realname.\n\n" +
+		"Regexes run on a PCRE-like engine, so results are close to the server's but not guaranteed. " +
+		"Differences: " + tview.Escape(strings.Join(rpc.SpamfilterRegexDifferences, "; ")) + ".\n\n" +
+		"Regexes are also checked for patterns that can make PCRE backtrack for a very long time. " +
+		"That check is a heuristic: a warning is a reason to look again, not proof. " +
+		"A sample a regex takes too long on is reported, the test itself can not hang.")
+
+	run := func() {
+		filters, err := rpc.ParseSpamfilterPatterns(patternsArea.GetText())
+		if err != nil {
+			resultsView.SetText(fmt.Sprintf("[red]Spamfilters: %s[-]", tview.Escape(err.Error())))
+			return
+		}
+		samples := rpc.ParseSpamfilterCorpus(samplesArea.GetText())
+		if len(filters) == 0 || len(samples) == 0 {
+			resultsView.SetText("[yellow]Enter at least one spamfilter and one sample.[-]")
+			return
+		}
+		resultsView.SetText(runSpamfilterBench(filters, samples))
+		resultsView.ScrollToBeginning()
+	}
+
+	loadSamples := func() {
+		showLoadSamplesModal(app, pages, func(text string) {
+			samplesArea.SetText(text, false)
+			app.SetFocus(samplesArea)
+		})
+	}
+
+	importFilters := func() {
+		resultsView.SetText("Loading spamfilters from the server...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			var filters []rpc.SpamfilterInfo
+			if err == nil {
+				filters, err = client.GetSpamfilters()
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					resultsView.SetText(fmt.Sprintf("[red]Error fetching spamfilters: %s[-]", tview.Escape(err.Error())))
+					return
+				}
+				var lines []string
+				for _, filter := range filters {
+					lines = append(lines, rpc.FormatSpamfilterPattern(filter))
+				}
+				patternsArea.SetText(strings.Join(lines, "\n"), false)
+				resultsView.SetText(fmt.Sprintf("Imported %d spamfilters from the server.", len(filters)))
+			})
+		}()
+	}
+
+	back := func() {
+		pages.RemovePage("spamfilter_test_bench")
+		pages.SwitchToPage("remote_spamfilters")
+	}
+
+	inputFlex := tview.NewFlex().SetDirection(tview.FlexRow)
+	inputFlex.AddItem(patternsArea, 0, 1, true)
+	inputFlex.AddItem(samplesArea, 0, 2, false)
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(inputFlex, 0, 1, true)
+	contentFlex.AddItem(resultsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Run").SetSelectedFunc(run), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Load Samples").SetSelectedFunc(loadSamples), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Import Server Spamfilters").SetSelectedFunc(importFilters), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("Ctrl-R: Run | Ctrl-L: Load Samples | ESC: Main Menu"), 3, 0, false)
+
+	flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyCtrlR:
+			run()
+			return nil
+		case tcell.KeyCtrlL:
+			loadSamples()
+			return nil
+		}
+		return event
+	})
+
+	pages.AddPage("spamfilter_test_bench", flex, true, true)
+	app.SetFocus(patternsArea)
+}
+
+// runSpamfilterBench matches every sample against every spamfilter and describes the outcome
+func runSpamfilterBench(filters []rpc.SpamfilterInfo, samples []rpc.SpamfilterSample) string {
+	var b strings.Builder
+	matched := make([]bool, len(samples))
+
+	for _, filter := range filters {
+		fmt.Fprintf(&b, "[yellow]%s %s[-] %s\n", filter.MatchType, filter.Targets, tview.Escape(filter.Name))
+
+		compiled, err := rpc.CompileSpamfilter(filter)
+		if err != nil {
+			fmt.Fprintf(&b, "  [red]%s[-]\n\n", tview.Escape(err.Error()))
+			continue
+		}
+		for _, warning := range compiled.Warnings {
+			fmt.Fprintf(&b, "  [red]Possible backtracking risk (heuristic): %s[-]\n", tview.Escape(warning))
+		}
+
+		var lines []string
+		count := 0
+		for i, sample := range samples {
+			match, err := compiled.Match(sample.Target, sample.Text)
+			if err != nil {
+				// The rest of the samples would likely take as long
+				fmt.Fprintf(&b, "  [red]Sample %d: %s, the remaining samples were skipped[-]\n", sample.Line, tview.Escape(err.Error()))
+				break
+			}
+			if !match {
+				continue
+			}
+			matched[i] = true
+			count++
+			if len(lines) < spamfilterBenchMaxListed {
+				lines = append(lines, fmt.Sprintf("    %d (%s): %s", sample.Line, sample.Target, tview.Escape(sample.Text)))
+			}
+		}
+
+		if count == 0 {
+			b.WriteString("  [green]No matches[-]\n\n")
+			continue
+		}
+		fmt.Fprintf(&b, "  [red]%d of %d samples match[-]\n", count, len(samples))
+		b.WriteString(strings.Join(lines, "\n") + "\n")
+		if count > len(lines) {
+			fmt.Fprintf(&b, "    ... and %d more\n", count-len(lines))
+		}
+		b.WriteString("\n")
+	}
+
+	var unmatched []string
+	for i, sample := range samples {
+		if !matched[i] {
+			unmatched = append(unmatched, fmt.Sprintf("    %d (%s): %s", sample.Line, sample.Target, tview.Escape(sample.Text)))
+		}
+	}
+	fmt.Fprintf(&b, "[green]%d of %d samples are not matched by any spamfilter[-]\n", len(unmatched), len(samples))
+	if len(unmatched) > spamfilterBenchMaxListed {
+		unmatched = append(unmatched[:spamfilterBenchMaxListed], fmt.Sprintf("    ... and %d more", len(unmatched)-spamfilterBenchMaxListed))
+	}
+	b.WriteString(strings.Join(unmatched, "\n"))
+	return b.String()
+}
+
+// showLoadSamplesModal asks for a file and passes its contents to onLoad
+func showLoadSamplesModal(app *tview.Application, pages *tview.Pages, onLoad func(text string)) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Load Samples")
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.AddInputField("File:", "", 50, nil, nil)
+
+	closeModal := func() {
+		pages.RemovePage("spamfilter_samples_modal")
+	}
+
+	form.AddButton("Load", func() {
+		path := strings.TrimSpace(form.GetFormItemByLabel("File:").(*tview.InputField).GetText())
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			showMessageModal(pages, "spamfilter_samples_error_modal", fmt.Sprintf("Error reading samples: %v", err))
+			return
+		}
+		closeModal()
+		onLoad(string(data))
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("spamfilter_samples_modal", centeredModal(form, 66, 7), true, true)
+	app.SetFocus(form)
+}
+
+// describeSpamfilterTargetLetters lists the target letters with their meaning
+func describeSpamfilterTargetLetters() string {
+	var parts []string
+	for _, target := range rpc.SpamfilterTargets {
+		parts = append(parts, fmt.Sprintf("%s = %s", target.Letter, strings.ToLower(target.Description)))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,262 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+func remoteSpamfiltersPage(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig) {
+	var filters []rpc.SpamfilterInfo
+
+	flex := tview.NewFlex().SetDirection(tview.FlexRow)
+	flex.AddItem(createHeader(), 3, 0, false)
+
+	filtersList := tview.NewList()
+	filtersList.SetBorder(true)
+	filtersList.SetTitle("Spamfilters")
+	filtersList.SetBorderColor(tcell.ColorBlue)
+
+	filterDetailsView := tview.NewTextView()
+	filterDetailsView.SetBorder(true)
+	filterDetailsView.SetTitle("Spamfilter Details")
+	filterDetailsView.SetDynamicColors(true)
+	filterDetailsView.SetWordWrap(true)
+	filterDetailsView.SetText("Loading spamfilters...")
+
+	showFilterDetails := func(index int) {
+		if index < 0 || index >= len(filters) {
+			filterDetailsView.SetText("No spamfilters.")
+			return
+		}
+		filterDetailsView.SetText(formatSpamfilterDetails(filters[index]))
+	}
+
+	renderFilters := func() {
+		filtersList.SetTitle(fmt.Sprintf("Spamfilters (%d)", len(filters)))
+		filtersList.Clear()
+		for _, filter := range filters {
+			mainText := fmt.Sprintf("%-6s %-9s %s", filter.MatchType, filter.Action, tview.Escape(filter.Name))
+			secondaryText := fmt.Sprintf("  targets %s, %d hits - %s", filter.Targets, filter.Hits, tview.Escape(filter.Reason))
+			filtersList.AddItem(mainText, secondaryText, 0, nil)
+		}
+		if len(filters) > 0 {
+			filtersList.SetCurrentItem(0)
+		}
+		showFilterDetails(filtersList.GetCurrentItem())
+	}
+
+	loadFilters := func() {
+		filterDetailsView.SetText("Loading spamfilters...")
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err != nil {
+				app.QueueUpdateDraw(func() {
+					filterDetailsView.SetText(fmt.Sprintf("Error connecting to RPC server: %v", err))
+				})
+				return
+			}
+
+			newFilters, err := client.GetSpamfilters()
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					filterDetailsView.SetText(fmt.Sprintf("Error fetching spamfilters: %v", err))
+					return
+				}
+				filters = newFilters
+				renderFilters()
+			})
+		}()
+	}
+
+	filtersList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showFilterDetails(index)
+	})
+
+	addFilter := func() {
+		showAddSpamfilterModal(app, pages, config, loadFilters)
+	}
+
+	deleteFilter := func() {
+		index := filtersList.GetCurrentItem()
+		if index < 0 || index >= len(filters) {
+			return
+		}
+		filter := filters[index]
+		if filter.SetInConfig {
+			showMessageModal(pages, "spamfilter_config_modal", fmt.Sprintf("The spamfilter %s is set in the configuration file and cannot be removed over RPC.", filter.Name))
+			return
+		}
+		showConfirmModal(pages, "spamfilter_delete_modal", fmt.Sprintf("Remove the spamfilter %s?", filter.Name), func() {
+			go func() {
+				client, err := rpc.Sessions.Get(config).Client()
+				if err == nil {
+					err = client.DeleteSpamfilter(filter)
+				}
+				app.QueueUpdateDraw(func() {
+					if err != nil {
+						showMessageModal(pages, "spamfilter_error_modal", fmt.Sprintf("Error removing spamfilter: %v", err))
+						return
+					}
+					loadFilters()
+				})
+			}()
+		})
+	}
+
+	back := func() {
+		pages.RemovePage("remote_spamfilters")
+		pages.SwitchToPage("remote_control_menu")
+	}
+
+	filtersList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Rune() {
+		case 'a':
+			addFilter()
+			return nil
+		case 'd':
+			deleteFilter()
+			return nil
+		case 'r':
+			loadFilters()
+			return nil
+		case 't':
+			spamfilterTestBenchPage(app, pages, config)
+			return nil
+		}
+		if event.Key() == tcell.KeyDelete {
+			deleteFilter()
+			return nil
+		}
+		return event
+	})
+
+	contentFlex := tview.NewFlex()
+	contentFlex.AddItem(filtersList, 0, 2, true)
+	contentFlex.AddItem(filterDetailsView, 0, 1, false)
+
+	buttonBar := tview.NewFlex()
+	buttonBar.AddItem(tview.NewButton("Add Spamfilter").SetSelectedFunc(addFilter), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Delete Spamfilter").SetSelectedFunc(deleteFilter), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Test Bench").SetSelectedFunc(func() {
+		spamfilterTestBenchPage(app, pages, config)
+	}), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Refresh").SetSelectedFunc(loadFilters), 0, 1, false)
+	buttonBar.AddItem(tview.NewTextView().SetText(" "), 2, 0, false)
+	buttonBar.AddItem(tview.NewButton("Back").SetSelectedFunc(back), 0, 1, false)
+
+	flex.AddItem(contentFlex, 0, 1, true)
+	flex.AddItem(buttonBar, 3, 0, false)
+	flex.AddItem(CreateFooter("a: Add | d: Delete | t: Test Bench | r: Refresh | ESC: Main Menu"), 3, 0, false)
+
+	pages.AddPage("remote_spamfilters", flex, true, true)
+	app.SetFocus(filtersList)
+
+	loadFilters()
+}
+
+// showAddSpamfilterModal shows a guided form for adding a spamfilter
+func showAddSpamfilterModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, onAdded func()) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Add Spamfilter")
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.SetItemPadding(0)
+
+	form.AddInputField("Pattern:", "", 40, nil, nil)
+	form.AddDropDown("Match type:", rpc.SpamfilterMatchTypes, 0, nil)
+	form.AddDropDown("Action:", rpc.SpamfilterActions, 0, nil)
+	form.AddInputField("Ban duration:", "1d", 20, nil, nil)
+	form.AddInputField("Reason:", "", 40, nil, nil)
+	for _, target := range rpc.SpamfilterTargets {
+		form.AddCheckbox(fmt.Sprintf("%s (%s)", target.Description, target.Letter), false, nil)
+	}
+
+	form.AddButton("Add", func() {
+		name := form.GetFormItemByLabel("Pattern:").(*tview.InputField).GetText()
+		_, matchType := form.GetFormItemByLabel("Match type:").(*tview.DropDown).GetCurrentOption()
+		_, action := form.GetFormItemByLabel("Action:").(*tview.DropDown).GetCurrentOption()
+		duration := strings.TrimSpace(form.GetFormItemByLabel("Ban duration:").(*tview.InputField).GetText())
+		reason := strings.TrimSpace(form.GetFormItemByLabel("Reason:").(*tview.InputField).GetText())
+
+		var targets strings.Builder
+		for _, target := range rpc.SpamfilterTargets {
+			label := fmt.Sprintf("%s (%s)", target.Description, target.Letter)
+			if form.GetFormItemByLabel(label).(*tview.Checkbox).IsChecked() {
+				targets.WriteString(target.Letter)
+			}
+		}
+
+		if strings.TrimSpace(name) == "" || reason == "" {
+			showMessageModal(pages, "spamfilter_validation_modal", "Pattern and reason are required.")
+			return
+		}
+		if targets.Len() == 0 {
+			showMessageModal(pages, "spamfilter_validation_modal", "Choose at least one target to filter.")
+			return
+		}
+
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err == nil {
+				err = client.AddSpamfilter(name, matchType, targets.String(), action, duration, reason)
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "spamfilter_error_modal", fmt.Sprintf("Error adding spamfilter: %v", err))
+					return
+				}
+				pages.RemovePage("spamfilter_add_modal")
+				if onAdded != nil {
+					onAdded()
+				}
+			})
+		}()
+	})
+
+	form.AddButton("Cancel", func() {
+		pages.RemovePage("spamfilter_add_modal")
+	})
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("spamfilter_add_modal", centeredModal(form, 64, 22), true, true)
+}
+
+func formatSpamfilterDetails(filter rpc.SpamfilterInfo) string {
+	setAt := "Unknown"
+	if filter.CreatedAt > 0 {
+		setAt = time.Unix(filter.CreatedAt, 0).Format("2006-01-02 15:04:05")
+	}
+
+	banDuration := filter.BanDurationString
+	if banDuration == "" {
+		banDuration = "-"
+	}
+
+	source := "RPC / IRC"
+	if filter.SetInConfig {
+		source = "Configuration file"
+	}
+
+	return fmt.Sprintf(
+		"[green]Pattern:[white]\n  %s\n"+
+			"[green]Match Type:[white]\n  %s\n"+
+			"[green]Targets:[white]\n  %s (%s)\n"+
+			"[green]Action:[white]\n  %s\n"+
+			"[green]Ban Duration:[white]\n  %s\n"+
+			"[green]Reason:[white]\n  %s\n"+
+			"[green]Hits:[white]\n  %d (%d on exempted users)\n"+
+			"[green]Set By:[white]\n  %s\n"+
+			"[green]Set At:[white]\n  %s\n"+
+			"[green]Expires:[white]\n  %s\n"+
+			"[green]Source:[white]\n  %s",
+		tview.Escape(filter.Name), filter.MatchType, rpc.DescribeSpamfilterTargets(filter.Targets), filter.Targets,
+		filter.Action, banDuration, tview.Escape(filter.Reason), filter.Hits, filter.HitsExcept,
+		tview.Escape(filter.Setby), setAt, formatExpiry(filter.ExpireAt), source)
+}
@@ -0,0 +1,376 @@
+package ui
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"utui/rpc"
+)
+
+// tableItems are the items a listTable shows, like the users or channels. The
+// tableModel holding them changes them with set and cut only.
+type tableItems interface {
+	Len() int
+	Field(index int, column string) string
+	set(index int, item interface{}) tableItems // Replaces the item at index, or appends it at Len
+	cut(index int) tableItems                   // Removes the item at index, moving the last one there
+}
+
+// userItems are the items of the users table
+type userItems []rpc.UserInfo
+
+func (u userItems) Len() int                              { return len(u) }
+func (u userItems) Field(index int, column string) string { return rpc.UserField(u[index], column) }
+
+func (u userItems) set(index int, item interface{}) tableItems {
+	if index == len(u) {
+		return append(u, item.(rpc.UserInfo))
+	}
+	u[index] = item.(rpc.UserInfo)
+	return u
+}
+
+func (u userItems) cut(index int) tableItems {
+	u[index] = u[len(u)-1]
+	return u[:len(u)-1]
+}
+
+// channelItems are the items of the channels table
+type channelItems []rpc.ChannelInfo
+
+func (c channelItems) Len() int { return len(c) }
+func (c channelItems) Field(index int, column string) string {
+	return rpc.ChannelField(c[index], column)
+}
+
+func (c channelItems) set(index int, item interface{}) tableItems {
+	if index == len(c) {
+		return append(c, item.(rpc.ChannelInfo))
+	}
+	c[index] = item.(rpc.ChannelInfo)
+	return c
+}
+
+func (c channelItems) cut(index int) tableItems {
+	c[index] = c[len(c)-1]
+	return c[:len(c)-1]
+}
+
+// tableModel holds the items of a listTable and the rows shown of them, filtered
+// and sorted. It is changed off the UI goroutine, so that loading and updating
+// a large network does not hold up drawing. The first column is the key of an
+// item. Items are found by key through a map, and a changed item only moves its
+// own row, found by binary search: all rows are only filtered and sorted again
+// when the items are replaced or the filter or sort order changes.
+type tableModel struct {
+	mu       sync.RWMutex
+	columns  []rpc.TableColumn
+	items    tableItems
+	keys     map[string]int // Lower case keys to item indexes
+	values   []sortValue    // Per item, what its row is filtered and sorted by
+	rows     []int          // Item indexes of the shown rows, filtered and sorted
+	query    *rpc.TableQuery
+	sortBy   string
+	sortDesc bool
+	numeric  bool              // Whether the sort column is numeric
+	order    int64             // Number of the filter and sort order applied
+	renames  map[string]string // Lower case old keys to new ones, until the table reselects
+
+	// Changes made by the fn passed to update
+	changed bool
+	added   map[int]bool // Items set since, their rows are inserted after fn
+}
+
+// sortValue is the value of an item in the sort column, parsed once for sorting
+type sortValue struct {
+	key    string // Lower case, to sort items with the same value
+	text   string
+	number float64
+	shown  bool // Whether the item passes the filter and has a row
+}
+
+func newTableModel(columns []rpc.TableColumn, items tableItems) *tableModel {
+	m := &tableModel{
+		columns: columns,
+		items:   items,
+		sortBy:  columns[0].Name,
+	}
+	m.reorder()
+	return m
+}
+
+// update calls fn with the model locked, to change the items with replace, put
+// and remove. It reports whether fn changed anything.
+func (m *tableModel) update(fn func()) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.changed = false
+	fn()
+	m.insertAdded()
+	return m.changed
+}
+
+// replace replaces all items, which are filtered and sorted again. It is only
+// called from the fn passed to update.
+func (m *tableModel) replace(items tableItems) {
+	m.items = items
+	m.added = nil
+	m.reorder()
+	m.changed = true
+}
+
+// find returns the index of the item with key, or -1
+func (m *tableModel) find(key string) int {
+	if index, ok := m.keys[strings.ToLower(key)]; ok {
+		return index
+	}
+	return -1
+}
+
+// put replaces the item with key by item, or adds it. key must be the key of
+// item. It is only called from the fn passed to update.
+func (m *tableModel) put(key string, item interface{}) {
+	index := m.find(key)
+	if index >= 0 {
+		m.hide(index)
+	} else {
+		index = m.items.Len()
+		m.values = append(m.values, sortValue{key: strings.ToLower(key)})
+		m.keys[strings.ToLower(key)] = index
+	}
+	m.items = m.items.set(index, item)
+	if m.added == nil {
+		m.added = make(map[int]bool)
+	}
+	m.added[index] = true
+	m.changed = true
+}
+
+// remove removes the item with key, if there is one. It is only called from
+// the fn passed to update.
+func (m *tableModel) remove(key string) {
+	index := m.find(key)
+	if index < 0 {
+		return
+	}
+	m.hide(index)
+	delete(m.keys, m.values[index].key)
+	delete(m.added, index)
+
+	// The last item takes the place of the removed one
+	last := m.items.Len() - 1
+	if index != last {
+		if m.added[last] {
+			delete(m.added, last)
+			m.added[index] = true
+		} else if m.values[last].shown {
+			m.rows[m.search(last)] = index
+		}
+		m.values[index] = m.values[last]
+		m.keys[m.values[index].key] = index
+	}
+	m.values = m.values[:last]
+	m.items = m.items.cut(index)
+	m.changed = true
+}
+
+// renamed records that the key of an item changed. It is only called from the fn
+// passed to update, so the table can keep the item selected.
+func (m *tableModel) renamed(old, new string) {
+	if m.renames == nil {
+		m.renames = make(map[string]string)
+	}
+	m.renames[strings.ToLower(old)] = new
+}
+
+// rename returns the key the item with key has after the renames since the last
+// call, which are forgotten
+func (m *tableModel) rename(key string) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Follow nick changes in a row, at most once per rename in case they go round
+	for range m.renames {
+		new, ok := m.renames[strings.ToLower(key)]
+		if !ok {
+			break
+		}
+		key = new
+	}
+	m.renames = nil
+	return key
+}
+
+// read calls fn with the model locked for reading. fn may use find and the
+// items, but must not change or keep them.
+func (m *tableModel) read(fn func()) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	fn()
+}
+
+// setOrder changes the filter and sort order of the rows. Orders are numbered,
+// one older than the order applied is ignored.
+func (m *tableModel) setOrder(order int64, query *rpc.TableQuery, sortBy string, sortDesc bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if order < m.order {
+		return
+	}
+	m.order = order
+	m.query = query
+	m.sortBy = sortBy
+	m.sortDesc = sortDesc
+	m.reorder()
+}
+
+// reorder indexes, filters and sorts all items again, with mu locked
+func (m *tableModel) reorder() {
+	m.numeric = false
+	for _, column := range m.columns {
+		if column.Name == m.sortBy {
+			m.numeric = column.Numeric
+		}
+	}
+
+	n := m.items.Len()
+	m.keys = make(map[string]int, n)
+	m.values = make([]sortValue, n)
+	m.rows = m.rows[:0]
+	for i := 0; i < n; i++ {
+		m.values[i] = m.value(i)
+		m.keys[m.values[i].key] = i
+		if m.values[i].shown {
+			m.rows = append(m.rows, i)
+		}
+	}
+	sort.Slice(m.rows, func(i, j int) bool { return m.less(m.rows[i], m.rows[j]) })
+}
+
+// value returns what the item at index is filtered and sorted by
+func (m *tableModel) value(index int) sortValue {
+	value := sortValue{
+		key:   strings.ToLower(m.items.Field(index, m.columns[0].Name)),
+		text:  strings.ToLower(m.items.Field(index, m.sortBy)),
+		shown: m.query.Match(func(column string) string { return m.items.Field(index, column) }),
+	}
+	if m.numeric {
+		value.number, _ = strconv.ParseFloat(value.text, 64)
+	}
+	return value
+}
+
+// less reports whether the row of item a goes before the row of item b
+func (m *tableModel) less(a, b int) bool {
+	x, y := &m.values[a], &m.values[b]
+	if m.sortDesc {
+		x, y = y, x
+	}
+	if m.numeric && x.number != y.number {
+		return x.number < y.number
+	}
+	if x.text != y.text {
+		return x.text < y.text
+	}
+	return x.key < y.key
+}
+
+// search returns the row of the item at index, or where it would go
+func (m *tableModel) search(index int) int {
+	return sort.Search(len(m.rows), func(row int) bool { return !m.less(m.rows[row], index) })
+}
+
+// hide removes the row of the item at index, if it has one
+func (m *tableModel) hide(index int) {
+	if !m.values[index].shown {
+		return
+	}
+	row := m.search(index)
+	m.rows = append(m.rows[:row], m.rows[row+1:]...)
+	m.values[index].shown = false
+}
+
+// insertAdded gives the items set in update their rows. A few rows are inserted
+// where they go, many, like a batch of a load, are sorted and merged in at once.
+func (m *tableModel) insertAdded() {
+	var added []int
+	for index := range m.added {
+		m.values[index] = m.value(index)
+		if m.values[index].shown {
+			added = append(added, index)
+		}
+	}
+	m.added = nil
+
+	if len(added) < 16 {
+		for _, index := range added {
+			row := m.search(index)
+			m.rows = append(m.rows, 0)
+			copy(m.rows[row+1:], m.rows[row:])
+			m.rows[row] = index
+		}
+		return
+	}
+
+	sort.Slice(added, func(i, j int) bool { return m.less(added[i], added[j]) })
+	rows := make([]int, 0, len(m.rows)+len(added))
+	i := 0
+	for _, index := range m.rows {
+		for i < len(added) && m.less(added[i], index) {
+			rows = append(rows, added[i])
+			i++
+		}
+		rows = append(rows, index)
+	}
+	m.rows = append(rows, added[i:]...)
+}
+
+// counts returns the number of rows shown and of items
+func (m *tableModel) counts() (int, int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.rows), m.items.Len()
+}
+
+// keyAt returns the key of the item in row, or "" when there is no such row
+func (m *tableModel) keyAt(row int) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if row < 0 || row >= len(m.rows) {
+		return ""
+	}
+	return m.items.Field(m.rows[row], m.columns[0].Name)
+}
+
+// rowOf returns the row of the item with key, or -1
+func (m *tableModel) rowOf(key string) int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	index := m.find(key)
+	if index < 0 || !m.values[index].shown {
+		return -1
+	}
+	return m.search(index)
+}
+
+// window returns the values of columns for rows start up to end
+func (m *tableModel) window(start, end int, columns []rpc.TableColumn) [][]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if end > len(m.rows) {
+		end = len(m.rows)
+	}
+	var values [][]string
+	for row := start; row < end; row++ {
+		fields := make([]string, len(columns))
+		for c, column := range columns {
+			fields[c] = m.items.Field(m.rows[row], column.Name)
+		}
+		values = append(values, fields)
+	}
+	return values
+}
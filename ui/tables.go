@@ -0,0 +1,348 @@
+package ui
+
+import (
+	"fmt"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tableKeys describes the keys listTable.handleKey understands
+const tableKeys = "/: Filter | 1-9: Sort | c: Columns"
+
+// tableRowBuffer is how many rows before and after the drawn ones are formatted
+// ahead, so scrolling does not go back to the model for every row
+const tableRowBuffer = 100
+
+// listTable shows items in a table with sortable, configurable columns and an
+// inline filter using rpc table queries. The items are kept in a tableModel,
+// which is changed off the UI goroutine, and only the rows around the ones on
+// screen are formatted, so large networks draw as fast as small ones. Items are
+// identified by their first column, the key.
+type listTable struct {
+	*tview.Flex
+	app     *tview.Application
+	pages   *tview.Pages
+	table   *tview.Table
+	filter  *tview.InputField
+	model   *tableModel
+	content *tableContent
+
+	name     string            // Table name the shown columns are saved under
+	title    string            // Title, followed by the row counts
+	columns  []rpc.TableColumn // Every column the table can show
+	shown    []rpc.TableColumn
+	sortBy   string
+	sortDesc bool
+	query    *rpc.TableQuery
+	queryErr error
+	orders   int64 // Number of filter and sort changes, only the latest is applied
+
+	onSelect func(key string) // Called when another item is selected, with "" for none
+	onChange func()           // Called after the items changed
+	selected string           // Key of the item onSelect was last called for
+}
+
+// newListTable creates a table called name (for the saved columns), showing items
+func newListTable(app *tview.Application, pages *tview.Pages, name, title string, columns []rpc.TableColumn, items tableItems) *listTable {
+	t := &listTable{
+		Flex:    tview.NewFlex().SetDirection(tview.FlexRow),
+		app:     app,
+		pages:   pages,
+		table:   tview.NewTable(),
+		filter:  tview.NewInputField(),
+		model:   newTableModel(columns, items),
+		name:    name,
+		title:   title,
+		columns: columns,
+		sortBy:  columns[0].Name,
+	}
+	t.content = &tableContent{t: t}
+
+	t.shown = columns
+	if saved, err := rpc.LoadTableColumns(name); err == nil && len(saved) > 0 {
+		t.shown = nil
+		for _, columnName := range saved {
+			for _, column := range columns {
+				if column.Name == columnName {
+					t.shown = append(t.shown, column)
+				}
+			}
+		}
+		if len(t.shown) == 0 {
+			t.shown = columns
+		}
+	}
+
+	t.table.SetContent(t.content)
+	t.table.SetBorder(true)
+	t.table.SetBorderColor(tcell.ColorBlue)
+	t.table.SetSelectable(true, false)
+	t.table.SetFixed(1, 0)
+	t.table.SetSelectionChangedFunc(func(row, column int) {
+		// Changes reselect the same item, only a different one is reported
+		key := t.model.keyAt(row - 1)
+		if key == t.selected {
+			return
+		}
+		t.selected = key
+		if t.onSelect != nil {
+			t.onSelect(key)
+		}
+	})
+
+	t.filter.SetLabel("Filter: ")
+	t.filter.SetPlaceholder("server:hub1 reputation:<10")
+	t.filter.SetChangedFunc(func(text string) {
+		t.query, t.queryErr = rpc.ParseTableQuery(text, t.columns)
+		if t.queryErr != nil {
+			t.query = nil
+		}
+		t.reorder()
+	})
+	t.filter.SetDoneFunc(func(key tcell.Key) {
+		app.SetFocus(t.table)
+	})
+
+	t.AddItem(t.filter, 1, 0, false)
+	t.AddItem(t.table, 0, 1, true)
+	t.renderTitle()
+	return t
+}
+
+// SetItems replaces the items on the UI goroutine. It is meant for starting
+// over with no or few items, use Update to change many.
+func (t *listTable) SetItems(items tableItems) {
+	t.model.update(func() { t.model.replace(items) })
+	t.changed()
+}
+
+// Update changes the items with fn off the UI goroutine, then redraws the table.
+// fn runs with the model locked and changes items with t.model.put and remove,
+// which only move the rows of those items, or replaces all of them. It must
+// not wait for the UI goroutine. When it changes the key of an item, it calls
+// t.model.renamed so that the item stays selected.
+func (t *listTable) Update(fn func(m *tableModel)) {
+	if t.model.update(func() { fn(t.model) }) {
+		t.app.QueueUpdateDraw(t.changed)
+	}
+}
+
+// Read calls fn with the model locked for reading, see tableModel.read
+func (t *listTable) Read(fn func(m *tableModel)) {
+	t.model.read(func() { fn(t.model) })
+}
+
+// reorder filters and sorts the rows again in the background after the filter or
+// sort order changed
+func (t *listTable) reorder() {
+	t.renderTitle()
+	t.orders++
+	order := t.orders
+	query, sortBy, sortDesc := t.query, t.sortBy, t.sortDesc
+	go func() {
+		t.model.setOrder(order, query, sortBy, sortDesc)
+		t.app.QueueUpdateDraw(func() {
+			if order == t.orders {
+				t.changed()
+			}
+		})
+	}()
+}
+
+// changed redraws the table after the model changed, keeping the selected item selected
+func (t *listTable) changed() {
+	t.selected = t.model.rename(t.selected)
+	t.content.clear()
+	t.renderTitle()
+	t.SelectKey(t.selected)
+	if t.onChange != nil {
+		t.onChange()
+	}
+}
+
+func (t *listTable) renderTitle() {
+	rows, total := t.model.counts()
+	title := fmt.Sprintf("%s (%d)", t.title, rows)
+	if rows != total {
+		title = fmt.Sprintf("%s (%d of %d)", t.title, rows, total)
+	}
+	if t.queryErr != nil {
+		title += fmt.Sprintf(" - filter: %s", tview.Escape(t.queryErr.Error()))
+	}
+	t.table.SetTitle(title)
+}
+
+// SetTitle sets the title shown before the row counts
+func (t *listTable) SetTitle(title string) {
+	t.title = title
+	t.renderTitle()
+}
+
+// Selected returns the key of the selected item, or "" when none is
+func (t *listTable) Selected() string {
+	return t.selected
+}
+
+// SelectKey selects the item with key, or the first row when it is not shown
+func (t *listTable) SelectKey(key string) {
+	row := 0
+	if key != "" {
+		row = max(t.model.rowOf(key), 0)
+	}
+	t.table.Select(row+1, 0)
+}
+
+// headerCell returns the header of a shown column, with its number for sorting
+func (t *listTable) headerCell(c int) *tview.TableCell {
+	column := t.shown[c]
+	title := column.Title
+	if column.Name == t.sortBy {
+		if t.sortDesc {
+			title += " ▼"
+		} else {
+			title += " ▲"
+		}
+	}
+	return tview.NewTableCell(fmt.Sprintf("%d %s", c+1, title)).
+		SetTextColor(tcell.ColorYellow).
+		SetSelectable(false)
+}
+
+// tableContent feeds the rows of a listTable to its tview.Table as they are
+// drawn. It keeps the cells of the rows around the drawn ones, until the
+// items change or the drawn rows move out of them.
+type tableContent struct {
+	tview.TableContentReadOnly
+	t     *listTable
+	start int // Row of the first cached cells, below the header
+	cells [][]*tview.TableCell
+}
+
+func (c *tableContent) GetRowCount() int {
+	rows, _ := c.t.model.counts()
+	return rows + 1
+}
+
+func (c *tableContent) GetColumnCount() int {
+	return len(c.t.shown)
+}
+
+func (c *tableContent) GetCell(row, column int) *tview.TableCell {
+	if row < 0 || column < 0 || column >= len(c.t.shown) {
+		return nil
+	}
+	if row == 0 {
+		return c.t.headerCell(column)
+	}
+	row--
+	if row < c.start || row >= c.start+len(c.cells) {
+		c.fill(row)
+	}
+	if row < c.start || row >= c.start+len(c.cells) {
+		return nil
+	}
+	return c.cells[row-c.start][column]
+}
+
+// fill formats the rows around row
+func (c *tableContent) fill(row int) {
+	c.start = max(row-tableRowBuffer, 0)
+	c.cells = nil
+	for _, fields := range c.t.model.window(c.start, row+2*tableRowBuffer, c.t.shown) {
+		cells := make([]*tview.TableCell, len(fields))
+		for i, field := range fields {
+			column := c.t.shown[i]
+			cell := tview.NewTableCell(tview.Escape(field))
+			if column.Numeric {
+				cell.SetAlign(tview.AlignRight)
+			}
+			if column.Name == "topic" {
+				cell.SetMaxWidth(50).SetExpansion(1)
+			}
+			cells[i] = cell
+		}
+		c.cells = append(c.cells, cells)
+	}
+}
+
+// clear drops the cached cells after the items or the shown columns changed
+func (c *tableContent) clear() {
+	c.cells = nil
+}
+
+// handleKey handles the filter, sort and column keys, reporting whether key was one of them
+func (t *listTable) handleKey(key rune) bool {
+	switch {
+	case key == '/':
+		t.app.SetFocus(t.filter)
+	case key >= '1' && key <= '9':
+		c := int(key - '1')
+		if c >= len(t.shown) {
+			return true
+		}
+		if t.sortBy == t.shown[c].Name {
+			t.sortDesc = !t.sortDesc
+		} else {
+			t.sortBy = t.shown[c].Name
+			t.sortDesc = t.shown[c].Numeric
+		}
+		t.reorder()
+	case key == 'c':
+		t.showColumnsModal()
+	default:
+		return false
+	}
+	return true
+}
+
+// showColumnsModal lets the user choose the columns shown, which are saved for next time
+func (t *listTable) showColumnsModal() {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle("Columns")
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.SetItemPadding(0)
+
+	for _, column := range t.columns {
+		checked := false
+		for _, shown := range t.shown {
+			if shown.Name == column.Name {
+				checked = true
+			}
+		}
+		form.AddCheckbox(column.Title, checked, nil)
+	}
+
+	closeModal := func() {
+		t.pages.RemovePage("table_columns_modal")
+		t.app.SetFocus(t.table)
+	}
+
+	form.AddButton("Save", func() {
+		var shown []rpc.TableColumn
+		var names []string
+		for i, column := range t.columns {
+			if form.GetFormItem(i).(*tview.Checkbox).IsChecked() {
+				shown = append(shown, column)
+				names = append(names, column.Name)
+			}
+		}
+		if len(shown) == 0 {
+			showMessageModal(t.pages, "table_columns_error_modal", "Choose at least one column.")
+			return
+		}
+		t.shown = shown
+		closeModal()
+		t.changed()
+		if err := rpc.SaveTableColumns(t.name, names); err != nil {
+			showMessageModal(t.pages, "table_columns_error_modal", fmt.Sprintf("Error saving columns: %v", err))
+		}
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	t.pages.AddPage("table_columns_modal", centeredModal(form, 40, len(t.columns)+6), true, true)
+	t.app.SetFocus(form)
+}
@@ -0,0 +1,141 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"utui/rpc"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// userActionKeys describes the keys handleUserActionKey understands
+const userActionKeys = "k: Kill | n: Nick | v: Vhost | m: Modes | s: Snomask | j: Join | p: Part | b: Ban"
+
+// handleUserActionKey starts the moderation action bound to key on user. onDone
+// is called after the action succeeded. It returns false for keys without an action.
+func handleUserActionKey(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, user rpc.UserInfo, key rune, onDone func()) bool {
+	nick := user.Nick
+	switch key {
+	case 'k':
+		showInputModal(app, pages, "Kill "+nick, "Reason:", "", "", func(reason string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Kill %s (%s)?", nick, reason), func(client *rpc.RPCClient) error {
+				return client.KillUser(nick, reason)
+			}, onDone)
+		})
+	case 'n':
+		showInputModal(app, pages, "Change Nick of "+nick, "New nick:", nick, "", func(newNick string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Change the nick of %s to %s?", nick, newNick), func(client *rpc.RPCClient) error {
+				return client.SetUserNick(nick, newNick)
+			}, onDone)
+		})
+	case 'v':
+		showInputModal(app, pages, "Set Vhost of "+nick, "Vhost:", user.Vhost, "", func(vhost string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Set the vhost of %s to %s?", nick, vhost), func(client *rpc.RPCClient) error {
+				return client.SetUserVhost(nick, vhost)
+			}, onDone)
+		})
+	case 'm':
+		showInputModal(app, pages, fmt.Sprintf("Modes of %s (now %s)", nick, user.Modes), "Modes (+x-i):", "", "", func(modes string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Set modes %s on %s?", modes, nick), func(client *rpc.RPCClient) error {
+				return client.SetUserModes(nick, modes)
+			}, onDone)
+		})
+	case 's':
+		showInputModal(app, pages, "Snomask of "+nick, "Snomask (+bc-k):", "", "", func(snomask string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Set snomask %s on %s?", snomask, nick), func(client *rpc.RPCClient) error {
+				return client.SetUserSnomask(nick, snomask)
+			}, onDone)
+		})
+	case 'j':
+		showInputModal(app, pages, "Force Join "+nick, "Channel:", "#", "Override bans, keys and limits", func(channel string, force bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Make %s join %s?", nick, channel), func(client *rpc.RPCClient) error {
+				return client.JoinUser(nick, channel, force)
+			}, onDone)
+		})
+	case 'p':
+		channel := ""
+		if len(user.Channels) > 0 {
+			channel = user.Channels[0]
+		}
+		showInputModal(app, pages, "Force Part "+nick, "Channel:", channel, "", func(channel string, _ bool) {
+			runRPCAction(app, pages, config, fmt.Sprintf("Make %s leave %s?", nick, channel), func(client *rpc.RPCClient) error {
+				return client.PartUser(nick, channel)
+			}, onDone)
+		})
+	case 'b':
+		showBanUserModal(app, pages, config, user, onDone)
+	default:
+		return false
+	}
+	return true
+}
+
+// runRPCAction asks for confirmation, then runs action in the background
+func runRPCAction(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, question string, action func(client *rpc.RPCClient) error, onDone func()) {
+	showConfirmModal(pages, "rpc_action_confirm_modal", question, func() {
+		go func() {
+			client, err := rpc.Sessions.Get(config).Client()
+			if err == nil {
+				err = action(client)
+			}
+			app.QueueUpdateDraw(func() {
+				if err != nil {
+					showMessageModal(pages, "rpc_action_error_modal", fmt.Sprintf("Error: %v", err))
+					return
+				}
+				if onDone != nil {
+					onDone()
+				}
+			})
+		}()
+	})
+}
+
+// showInputModal asks for a single value, with an optional checkbox when checkbox is not empty
+func showInputModal(app *tview.Application, pages *tview.Pages, title, label, value, checkbox string, onSubmit func(value string, checked bool)) {
+	form := tview.NewForm()
+	form.SetBorder(true).SetTitle(title)
+	form.SetBackgroundColor(tcell.ColorDefault)
+	form.AddInputField(label, value, 40, nil, nil)
+	height := 7
+	if checkbox != "" {
+		form.AddCheckbox(checkbox, false, nil)
+		height += 2
+	}
+
+	closeModal := func() {
+		pages.RemovePage("input_modal")
+	}
+
+	form.AddButton("OK", func() {
+		text := strings.TrimSpace(form.GetFormItem(0).(*tview.InputField).GetText())
+		if text == "" {
+			return
+		}
+		checked := checkbox != "" && form.GetFormItem(1).(*tview.Checkbox).IsChecked()
+		closeModal()
+		onSubmit(text, checked)
+	})
+	form.AddButton("Cancel", closeModal)
+	form.SetCancelFunc(closeModal)
+	form.SetButtonsAlign(tview.AlignCenter)
+
+	pages.AddPage("input_modal", centeredModal(form, 64, height), true, true)
+	app.SetFocus(form)
+}
+
+// showBanUserModal opens the mask builder with masks built from user, then the
+// server ban form with the mask chosen
+func showBanUserModal(app *tview.Application, pages *tview.Pages, config *rpc.RPCConfig, user rpc.UserInfo, onDone func()) {
+	masks := rpc.UserBanMasks(user, false)
+	if len(masks) == 0 {
+		showMessageModal(pages, "user_action_error_modal", fmt.Sprintf("No IP, host or account is known for %s.", user.Nick))
+		return
+	}
+
+	// Matched as the type the server ban form starts with, it checks again for the type chosen there
+	showBanMaskBuilder(app, pages, config, "Ban "+user.Nick, rpc.ServerBanTypes[0], masks[0], masks, func(mask, _ string) {
+		showAddServerBanModal(app, pages, config, mask, onDone)
+	})
+}